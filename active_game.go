@@ -0,0 +1,57 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidActiveGame = errors.New("invalid saved game")
+
+// ActiveGameStore is a PlayerStore that also keeps the game being played, so the game carries on with its blind clock
+// after a restart or a crash
+type ActiveGameStore interface {
+	// SaveActiveGame replaces the game kept, nil clears it once the game is over
+	SaveActiveGame(ctx context.Context, game *ActiveGame) error
+	// GetActiveGame is nil when there's no game being played
+	GetActiveGame(ctx context.Context) (*ActiveGame, error)
+}
+
+// ActiveGame is what it takes to carry on with a game, the result so far and where the blind clock is at
+type ActiveGame struct {
+	// the players eliminated so far are the finishers
+	Result GameResult
+	Clock  ClockState
+	// the roster the game started with, games saved before there were rosters don't have one
+	Players        []Entrant  `json:",omitempty"`
+	AdaptiveLevels bool       `json:",omitempty"`
+	Entries        EntryRules `json:",omitempty"`
+}
+
+func (g ActiveGame) Validate() error {
+	if g.Result.ID == "" {
+		return errors.New("the game has no id")
+	}
+	if g.Result.NumPlayers < 2 {
+		return fmt.Errorf("a game needs at least 2 players, got %d", g.Result.NumPlayers)
+	}
+	if len(g.Clock.Levels) == 0 {
+		return errors.New("the blind clock has no levels")
+	}
+	if len(g.Players) > 0 && len(g.Players) != g.Result.NumPlayers {
+		return fmt.Errorf("the roster has %d players for a game of %d", len(g.Players), g.Result.NumPlayers)
+	}
+	return nil
+}
+
+// copy doesn't share the finishers or levels with the game, so stores that keep it in memory aren't changed by callers
+func (g *ActiveGame) copy() *ActiveGame {
+	if g == nil {
+		return nil
+	}
+	game := *g
+	game.Result.Finishers = append([]Finisher(nil), g.Result.Finishers...)
+	game.Clock.Levels = append([]ScheduledLevel(nil), g.Clock.Levels...)
+	game.Players = append([]Entrant(nil), g.Players...)
+	return &game
+}
@@ -0,0 +1,307 @@
+package poker
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Role is what a user is allowed to do, each role can do everything the roles below it can
+type Role string
+
+const (
+	// players can only read the leagues, results and ratings
+	RolePlayer Role = "player"
+	// organisers can also start games and record results
+	RoleOrganiser Role = "organiser"
+	// admins can also create and archive leagues
+	RoleAdmin Role = "admin"
+)
+
+const (
+	DefaultSessionLength = 12 * time.Hour
+	// the cookie the /game page logs in with, api clients send the token as a bearer token instead
+	SessionCookieName = "poker_session"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid name or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrInvalidUser        = errors.New("invalid user")
+)
+
+var roleRanks = map[Role]int{RolePlayer: 1, RoleOrganiser: 2, RoleAdmin: 3}
+
+// dummyPasswordHash is compared against when there's no user or password to check, so a login takes as long whether
+// the name exists or not. It has the cost of HashPassword.
+const dummyPasswordHash = "$2a$10$4LoHb0.Cse/OowjtozEla.vbW9VhdunnZii.SF/Vfg8y/qPY3T7hG"
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows is true when the role can do what the required role can
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// User logs in with a password or sends one of their api tokens. Only hashes are kept so the users file gives nothing
+// away, see HashPassword and HashToken.
+type User struct {
+	Name         string
+	Role         Role
+	PasswordHash string
+	TokenHashes  []string
+}
+
+// HashPassword hashes a password with bcrypt for the users file
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("could not hash password, %v", err)
+	}
+	return string(hash), nil
+}
+
+// HashToken hashes an api token for the users file. Tokens are long and random so a plain sha256 is enough and
+// they can be looked up by their hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewToken is a random token to hand out as an api token or a session
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate token, %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Session is what a login hands back, the token is sent as a bearer token or in the session cookie
+type Session struct {
+	Token   string
+	Name    string
+	Role    Role
+	Expires time.Time
+}
+
+// Authenticator knows the users and the sessions they logged in with. Sessions are kept in memory so everyone logs in
+// again after a restart, api tokens keep working.
+type Authenticator struct {
+	users         map[string]User
+	tokens        map[string]string // token hash to user name
+	sessionLength time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]Session // by token hash
+}
+
+func NewAuthenticator(users []User, sessionLength time.Duration) (*Authenticator, error) {
+	if sessionLength <= 0 {
+		return nil, fmt.Errorf("session length must be positive, got %v", sessionLength)
+	}
+
+	a := &Authenticator{
+		users:         map[string]User{},
+		tokens:        map[string]string{},
+		sessionLength: sessionLength,
+		sessions:      map[string]Session{},
+	}
+	for _, user := range users {
+		if err := ValidatePlayerName(user.Name); err != nil {
+			return nil, fmt.Errorf("%w, %v", ErrInvalidUser, err)
+		}
+		if !user.Role.Valid() {
+			return nil, fmt.Errorf("%w, %s has an unknown role %q", ErrInvalidUser, user.Name, user.Role)
+		}
+		if user.PasswordHash == "" && len(user.TokenHashes) == 0 {
+			return nil, fmt.Errorf("%w, %s has no password or tokens", ErrInvalidUser, user.Name)
+		}
+		if _, ok := a.users[user.Name]; ok {
+			return nil, fmt.Errorf("%w, %s is there twice", ErrInvalidUser, user.Name)
+		}
+		for _, hash := range user.TokenHashes {
+			if _, ok := a.tokens[hash]; ok {
+				return nil, fmt.Errorf("%w, a token of %s is used twice", ErrInvalidUser, user.Name)
+			}
+			a.tokens[hash] = user.Name
+		}
+		a.users[user.Name] = user
+	}
+	return a, nil
+}
+
+// Login starts a session for the user when the password matches
+func (a *Authenticator) Login(name, password string) (Session, error) {
+	user, ok := a.users[name]
+	if !ok || user.PasswordHash == "" {
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return Session{}, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return Session{}, ErrInvalidCredentials
+	}
+
+	token, err := NewToken()
+	if err != nil {
+		return Session{}, err
+	}
+	session := Session{Token: token, Name: user.Name, Role: user.Role, Expires: time.Now().Add(a.sessionLength)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sessions[HashToken(token)] = session
+	return session, nil
+}
+
+// Logout ends the session of the token, api tokens can't be logged out
+func (a *Authenticator) Logout(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.sessions, HashToken(token))
+}
+
+// Authenticate finds the user of a session or api token
+func (a *Authenticator) Authenticate(token string) (User, error) {
+	hash := HashToken(token)
+	if name, ok := a.tokens[hash]; ok {
+		return a.users[name], nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	session, ok := a.sessions[hash]
+	if !ok {
+		return User{}, ErrInvalidToken
+	}
+	if time.Now().After(session.Expires) {
+		delete(a.sessions, hash)
+		return User{}, ErrInvalidToken
+	}
+	return a.users[session.Name], nil
+}
+
+type userKey struct{}
+
+// requestUser is the user the request was authenticated as, there's none for anonymous requests
+func requestUser(r *http.Request) (User, bool) {
+	user, ok := r.Context().Value(userKey{}).(User)
+	return user, ok
+}
+
+// Middleware authenticates the bearer token or session cookie of the request before passing it on, requests without
+// either go on anonymously. A bad bearer token is refused straight away while a bad cookie is dropped, so a browser
+// with an expired session can still get to the login page.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := bearerToken(r); ok {
+			user, err := a.Authenticate(token)
+			if err != nil {
+				unauthorized(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+			return
+		}
+
+		if cookie, err := r.Cookie(SessionCookieName); err == nil {
+			user, err := a.Authenticate(cookie.Value)
+			if err == nil {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+				return
+			}
+			clearSessionCookie(w)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("www-authenticate", `Bearer realm="poker"`)
+	writeError(w, http.StatusUnauthorized, err)
+}
+
+func setSessionCookie(w http.ResponseWriter, session Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  session.Expires,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+type usersFile struct {
+	Users []userFile `json:"users" yaml:"users"`
+}
+
+type userFile struct {
+	Name         string   `json:"name" yaml:"name"`
+	Role         string   `json:"role" yaml:"role"`
+	PasswordHash string   `json:"passwordHash" yaml:"passwordHash"`
+	TokenHashes  []string `json:"tokenHashes" yaml:"tokenHashes"`
+}
+
+// loads the users from a .json or .yaml file, passwords and tokens are hashed with HashPassword and HashToken
+func LoadUsers(path string) ([]User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read users file %s, %v", path, err)
+	}
+
+	var file usersFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("unsupported users file extension %q, use .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse users file %s, %v", path, err)
+	}
+
+	users := make([]User, len(file.Users))
+	for i, user := range file.Users {
+		users[i] = User{Name: user.Name, Role: Role(user.Role), PasswordHash: user.PasswordHash, TokenHashes: user.TokenHashes}
+	}
+	return users, nil
+}
@@ -0,0 +1,199 @@
+package poker_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestRoles(t *testing.T) {
+	cases := []struct {
+		role, required poker.Role
+		want           bool
+	}{
+		{poker.RolePlayer, poker.RolePlayer, true},
+		{poker.RolePlayer, poker.RoleOrganiser, false},
+		{poker.RoleOrganiser, poker.RolePlayer, true},
+		{poker.RoleOrganiser, poker.RoleOrganiser, true},
+		{poker.RoleOrganiser, poker.RoleAdmin, false},
+		{poker.RoleAdmin, poker.RoleOrganiser, true},
+		{poker.Role("dealer"), poker.RolePlayer, false},
+	}
+
+	for _, c := range cases {
+		if got := c.role.Allows(c.required); got != c.want {
+			t.Errorf("%s allows %s got %v want %v", c.role, c.required, got, c.want)
+		}
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	hash := mustHashPassword(t, "s3cret")
+	users := []poker.User{
+		{Name: "Andre", Role: poker.RoleOrganiser, PasswordHash: hash},
+		{Name: "scoreboard", Role: poker.RolePlayer, TokenHashes: []string{poker.HashToken("api-token")}},
+	}
+
+	t.Run("logs in with the right password", func(t *testing.T) {
+		auth := mustMakeAuthenticator(t, users, time.Hour)
+
+		session, err := auth.Login("Andre", "s3cret")
+		assertNoError(t, err)
+		if session.Name != "Andre" || session.Role != poker.RoleOrganiser || session.Token == "" {
+			t.Errorf("got session %+v", session)
+		}
+
+		user, err := auth.Authenticate(session.Token)
+		assertNoError(t, err)
+		if user.Name != "Andre" {
+			t.Errorf("got user %q want Andre", user.Name)
+		}
+	})
+
+	t.Run("refuses a wrong password or an unknown user", func(t *testing.T) {
+		auth := mustMakeAuthenticator(t, users, time.Hour)
+
+		for _, login := range [][2]string{{"Andre", "guess"}, {"Chris", "s3cret"}, {"scoreboard", ""}} {
+			if _, err := auth.Login(login[0], login[1]); !errors.Is(err, poker.ErrInvalidCredentials) {
+				t.Errorf("login as %s got %v want %v", login[0], err, poker.ErrInvalidCredentials)
+			}
+		}
+	})
+
+	t.Run("unknown users take as long to refuse as a wrong password", func(t *testing.T) {
+		auth := mustMakeAuthenticator(t, users, time.Hour)
+		timeLogin := func(name string) time.Duration {
+			start := time.Now()
+			auth.Login(name, "guess")
+			return time.Since(start)
+		}
+
+		// a bcrypt compare is milliseconds where a map lookup is microseconds, a quarter leaves room for noise
+		wrongPassword, unknownUser, noPassword := timeLogin("Andre"), timeLogin("Chris"), timeLogin("scoreboard")
+		if unknownUser < wrongPassword/4 || noPassword < wrongPassword/4 {
+			t.Errorf("got %v for an unknown user and %v for a user without a password, want about the %v of a wrong password",
+				unknownUser, noPassword, wrongPassword)
+		}
+	})
+
+	t.Run("api tokens authenticate their user", func(t *testing.T) {
+		auth := mustMakeAuthenticator(t, users, time.Hour)
+
+		user, err := auth.Authenticate("api-token")
+		assertNoError(t, err)
+		if user.Name != "scoreboard" || user.Role != poker.RolePlayer {
+			t.Errorf("got user %+v", user)
+		}
+
+		if _, err := auth.Authenticate("made-up"); !errors.Is(err, poker.ErrInvalidToken) {
+			t.Errorf("got %v want %v", err, poker.ErrInvalidToken)
+		}
+	})
+
+	t.Run("sessions end on logout", func(t *testing.T) {
+		auth := mustMakeAuthenticator(t, users, time.Hour)
+
+		session, err := auth.Login("Andre", "s3cret")
+		assertNoError(t, err)
+		auth.Logout(session.Token)
+
+		if _, err := auth.Authenticate(session.Token); !errors.Is(err, poker.ErrInvalidToken) {
+			t.Errorf("got %v want %v", err, poker.ErrInvalidToken)
+		}
+	})
+
+	t.Run("sessions expire", func(t *testing.T) {
+		auth := mustMakeAuthenticator(t, users, time.Millisecond)
+
+		session, err := auth.Login("Andre", "s3cret")
+		assertNoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		if _, err := auth.Authenticate(session.Token); !errors.Is(err, poker.ErrInvalidToken) {
+			t.Errorf("got %v want %v", err, poker.ErrInvalidToken)
+		}
+	})
+
+	t.Run("invalid users", func(t *testing.T) {
+		cases := map[string][]poker.User{
+			"unknown role":         {{Name: "Andre", Role: "dealer", PasswordHash: hash}},
+			"no password or token": {{Name: "Andre", Role: poker.RolePlayer}},
+			"there twice":          {users[0], users[0]},
+			"used twice":           {users[1], {Name: "Chris", Role: poker.RoleAdmin, TokenHashes: users[1].TokenHashes}},
+			"invalid player name":  {{Name: "", Role: poker.RolePlayer, PasswordHash: hash}},
+		}
+
+		for want, users := range cases {
+			_, err := poker.NewAuthenticator(users, time.Hour)
+			if !errors.Is(err, poker.ErrInvalidUser) || !strings.Contains(err.Error(), want) {
+				t.Errorf("got %v want an invalid user error with %q", err, want)
+			}
+		}
+	})
+}
+
+func TestLoadUsers(t *testing.T) {
+	want := []poker.User{
+		{Name: "Andre", Role: poker.RoleAdmin, PasswordHash: "$2a$10$hash"},
+		{Name: "scoreboard", Role: poker.RolePlayer, TokenHashes: []string{"abc123"}},
+	}
+
+	t.Run("from yaml", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "users.yaml", strings.Join([]string{
+			"users:",
+			"  - {name: Andre, role: admin, passwordHash: $2a$10$hash}",
+			"  - {name: scoreboard, role: player, tokenHashes: [abc123]}",
+		}, "\n"))
+
+		got, err := poker.LoadUsers(path)
+		assertNoError(t, err)
+		assertUsers(t, got, want)
+	})
+
+	t.Run("from json", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "users.json", `{"users": [
+			{"name": "Andre", "role": "admin", "passwordHash": "$2a$10$hash"},
+			{"name": "scoreboard", "role": "player", "tokenHashes": ["abc123"]}
+		]}`)
+
+		got, err := poker.LoadUsers(path)
+		assertNoError(t, err)
+		assertUsers(t, got, want)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "users.txt", "")
+
+		_, err := poker.LoadUsers(path)
+		assertErrorContains(t, err, "unsupported users file extension")
+	})
+}
+
+func mustHashPassword(t testing.TB, password string) string {
+	t.Helper()
+	hash, err := poker.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func mustMakeAuthenticator(t testing.TB, users []poker.User, sessionLength time.Duration) *poker.Authenticator {
+	t.Helper()
+	auth, err := poker.NewAuthenticator(users, sessionLength)
+	if err != nil {
+		t.Fatal("could not make authenticator", err)
+	}
+	return auth
+}
+
+func assertUsers(t testing.TB, got, want []poker.User) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got users %+v want %+v", got, want)
+	}
+}
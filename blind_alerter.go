@@ -6,18 +6,35 @@ import (
 	"time"
 )
 
+// AlertTimer is a scheduled alert that can be stopped before it fires, *time.Timer satisfies it
+type AlertTimer interface {
+	Stop() bool
+}
+
 type BlindAlerter interface {
-	ScheduleAlertAt(duration time.Duration, amount int, outputTo io.Writer)
+	ScheduleAlertAt(duration time.Duration, level BlindLevel, outputTo io.Writer) AlertTimer
 }
 
-type BlindAlerterFunc func(duration time.Duration, amount int, outputTo io.Writer)
+type BlindAlerterFunc func(duration time.Duration, level BlindLevel, outputTo io.Writer) AlertTimer
 
-func (a BlindAlerterFunc) ScheduleAlertAt(duration time.Duration, amount int, outputTo io.Writer) {
-	a(duration, amount, outputTo)
+func (a BlindAlerterFunc) ScheduleAlertAt(duration time.Duration, level BlindLevel, outputTo io.Writer) AlertTimer {
+	return a(duration, level, outputTo)
 }
 
-func Alerter(duration time.Duration, amount int, outputTo io.Writer) {
-	time.AfterFunc(duration, func() {
-		fmt.Fprintf(outputTo, "Blind is now %d\n", amount)
+func Alerter(duration time.Duration, level BlindLevel, outputTo io.Writer) AlertTimer {
+	return time.AfterFunc(duration, func() {
+		announceLevel(outputTo, level)
 	})
 }
+
+// levelMessage announces the level starting
+func levelMessage(level BlindLevel) string {
+	message := fmt.Sprintf("Blind is now %v\n", level)
+	if level.Break {
+		message = fmt.Sprintf("Break for %v\n", level.Duration)
+	}
+	if level.Notice != "" {
+		message += level.Notice + "\n"
+	}
+	return message
+}
@@ -0,0 +1,305 @@
+package poker
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	ErrClockPaused    = errors.New("blind clock is paused")
+	ErrClockNotPaused = errors.New("blind clock is not paused")
+	ErrClockStopped   = errors.New("blind clock has been cancelled")
+	ErrNoNextLevel    = errors.New("already at the last blind level")
+)
+
+type scheduledLevel struct {
+	at    time.Duration
+	level BlindLevel
+}
+
+// BlindClock runs the blind levels of a game and can be paused, resumed, skipped and cancelled.
+// Level offsets are relative to the game start and only count the time the clock was running.
+type BlindClock struct {
+	lock     sync.Mutex
+	alerter  BlindAlerter
+	out      io.Writer
+	schedule []scheduledLevel
+	timers   []AlertTimer
+
+	// index of the level being played, -1 before the first alert is scheduled
+	current   int
+	elapsed   time.Duration
+	resumedAt time.Time
+	// resumedAt on the wall clock, the monotonic reading of resumedAt means nothing after a restart
+	runningSince time.Time
+	paused       bool
+	cancelled    bool
+}
+
+// StartBlindClock schedules every level of the structure, level lengths scale with numPlayers
+func StartBlindClock(alerter BlindAlerter, structure BlindStructure, numPlayers int, out io.Writer) *BlindClock {
+	c := &BlindClock{
+		alerter:      alerter,
+		out:          out,
+		schedule:     make([]scheduledLevel, len(structure.Levels)),
+		current:      -1,
+		resumedAt:    time.Now(),
+		runningSince: time.Now().UTC(),
+	}
+
+	at := 0 * time.Second
+	for i, level := range structure.Levels {
+		c.schedule[i] = scheduledLevel{at, level}
+		at += structure.LevelDuration(level, numPlayers)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.scheduleFrom(0, 0)
+
+	return c
+}
+
+// Pause stops every pending alert and remembers how far into the current level the game is
+func (c *BlindClock) Pause() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cancelled {
+		return ErrClockStopped
+	}
+	if c.paused {
+		return ErrClockPaused
+	}
+
+	c.stopTimers()
+	c.elapsed += time.Since(c.resumedAt)
+	c.current = c.levelAt(c.elapsed)
+	c.paused = true
+
+	announceClock(c.out, true)
+	return nil
+}
+
+// Resume schedules the remaining levels with the time that was left when the clock was paused
+func (c *BlindClock) Resume() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cancelled {
+		return ErrClockStopped
+	}
+	if !c.paused {
+		return ErrClockNotPaused
+	}
+
+	c.paused = false
+	c.resumedAt = time.Now()
+	c.runningSince = time.Now().UTC()
+
+	announceClock(c.out, false)
+	c.scheduleFrom(c.current+1, c.elapsed)
+	return nil
+}
+
+// SkipLevel moves to the next level straight away, the following levels keep their full length.
+// A paused clock announces the new level but stays paused.
+func (c *BlindClock) SkipLevel() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cancelled {
+		return ErrClockStopped
+	}
+
+	elapsed := c.elapsed
+	if !c.paused {
+		elapsed += time.Since(c.resumedAt)
+	}
+
+	next := c.levelAt(elapsed) + 1
+	if next >= len(c.schedule) {
+		return ErrNoNextLevel
+	}
+
+	c.stopTimers()
+
+	// bring the next level forward to now and every level after it by the same amount
+	shift := c.schedule[next].at - elapsed
+	for i := next; i < len(c.schedule); i++ {
+		c.schedule[i].at -= shift
+	}
+
+	if c.paused {
+		c.timers = append(c.timers, c.alerter.ScheduleAlertAt(0, c.schedule[next].level, c.out))
+		c.current = next
+		return nil
+	}
+
+	c.scheduleFrom(next, elapsed)
+	return nil
+}
+
+// ClockStatus is the level being played and how long it has left
+type ClockStatus struct {
+	Level BlindLevel
+	// of Level in the blind structure
+	Index int
+	// 0 at the last level
+	NextLevelIn time.Duration
+	Paused      bool
+}
+
+func (c *BlindClock) Status() ClockStatus {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elapsed := c.elapsed
+	if !c.paused {
+		elapsed += time.Since(c.resumedAt)
+	}
+
+	current := c.levelAt(elapsed)
+	if current < 0 {
+		current = 0
+	}
+	status := ClockStatus{Level: c.schedule[current].level, Index: current, Paused: c.paused}
+	if next := current + 1; next < len(c.schedule) {
+		status.NextLevelIn = c.schedule[next].at - elapsed
+	}
+	return status
+}
+
+// Retime gives the levels after the current one the length worked out by length, the current level keeps its start
+// but ends after its new length, straight away if that already went by
+func (c *BlindClock) Retime(length func(level BlindLevel) time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cancelled {
+		return ErrClockStopped
+	}
+
+	elapsed := c.elapsed
+	if !c.paused {
+		elapsed += time.Since(c.resumedAt)
+	}
+	current := c.levelAt(elapsed)
+	if current < 0 {
+		current = 0
+	}
+
+	for i := current + 1; i < len(c.schedule); i++ {
+		previous := c.schedule[i-1]
+		c.schedule[i].at = max(previous.at+length(previous.level), elapsed)
+	}
+
+	if !c.paused {
+		c.stopTimers()
+		c.scheduleFrom(current+1, elapsed)
+	}
+	return nil
+}
+
+// ClockState is what a clock needs to carry on where it was, see State and RestoreBlindClock
+type ClockState struct {
+	// skipping levels moves them so they are kept rather than worked out again from the blind structure
+	Levels []ScheduledLevel
+	// running time played before RunningSince, or before the pause
+	Elapsed time.Duration
+	// when the clock last started running, the time since counts as played even while the game wasn't saved
+	RunningSince time.Time
+	Paused       bool
+}
+
+// ScheduledLevel is a level and when it starts in the running time of the game
+type ScheduledLevel struct {
+	At    time.Duration
+	Level BlindLevel
+}
+
+func (c *BlindClock) State() ClockState {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	state := ClockState{
+		Levels:       make([]ScheduledLevel, len(c.schedule)),
+		Elapsed:      c.elapsed,
+		RunningSince: c.runningSince,
+		Paused:       c.paused,
+	}
+	for i, s := range c.schedule {
+		state.Levels[i] = ScheduledLevel{At: s.at, Level: s.level}
+	}
+	return state
+}
+
+// RestoreBlindClock carries on with a clock from its state. A running clock counts the time since it was saved as
+// played, announces the level that is being played now and schedules the ones after it.
+func RestoreBlindClock(alerter BlindAlerter, state ClockState, out io.Writer) *BlindClock {
+	c := &BlindClock{
+		alerter:      alerter,
+		out:          out,
+		schedule:     make([]scheduledLevel, len(state.Levels)),
+		current:      -1,
+		elapsed:      state.Elapsed,
+		resumedAt:    time.Now(),
+		runningSince: time.Now().UTC(),
+		paused:       state.Paused,
+	}
+	for i, s := range state.Levels {
+		c.schedule[i] = scheduledLevel{s.At, s.Level}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.paused {
+		if played := time.Since(state.RunningSince); played > 0 {
+			c.elapsed += played
+		}
+	}
+	c.current = c.levelAt(c.elapsed)
+	if c.paused || c.current < 0 {
+		// paused clocks schedule nothing until they resume, a clock with no levels has nothing to schedule
+		return c
+	}
+
+	c.timers = append(c.timers, c.alerter.ScheduleAlertAt(0, c.schedule[c.current].level, c.out))
+	c.scheduleFrom(c.current+1, c.elapsed)
+	return c
+}
+
+// Cancel stops every pending alert, the clock can't be used afterwards
+func (c *BlindClock) Cancel() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopTimers()
+	c.cancelled = true
+}
+
+// schedules the alerts for the levels from index onwards relative to the running time already played
+func (c *BlindClock) scheduleFrom(index int, elapsed time.Duration) {
+	for _, s := range c.schedule[index:] {
+		c.timers = append(c.timers, c.alerter.ScheduleAlertAt(s.at-elapsed, s.level, c.out))
+	}
+}
+
+func (c *BlindClock) stopTimers() {
+	for _, timer := range c.timers {
+		timer.Stop()
+	}
+	c.timers = nil
+}
+
+// index of the level being played after elapsed running time
+func (c *BlindClock) levelAt(elapsed time.Duration) int {
+	level := c.current
+	for i := c.current + 1; i < len(c.schedule) && c.schedule[i].at <= elapsed; i++ {
+		level = i
+	}
+	return level
+}
@@ -0,0 +1,296 @@
+package poker_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestBlindClock(t *testing.T) {
+	structure := poker.BlindStructure{
+		Name: "test",
+		Levels: []poker.BlindLevel{
+			{SmallBlind: 10, BigBlind: 20, Duration: 10 * time.Minute},
+			{SmallBlind: 20, BigBlind: 40, Duration: 10 * time.Minute},
+			{SmallBlind: 40, BigBlind: 80, Duration: 10 * time.Minute},
+		},
+	}
+
+	t.Run("schedules every level on start", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		poker.StartBlindClock(alerter, structure, 4, &bytes.Buffer{})
+
+		assertSchedulingTests(t, []ScheduledAlert{
+			{At: 0, Amount: 10},
+			{At: 10 * time.Minute, Amount: 20},
+			{At: 20 * time.Minute, Amount: 40},
+		}, alerter)
+	})
+
+	t.Run("pause stops pending alerts and resume reschedules the remaining levels", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		out := &bytes.Buffer{}
+		clock := poker.StartBlindClock(alerter, structure, 4, out)
+
+		assertNoError(t, clock.Pause())
+		if alerter.Pending() != 0 {
+			t.Fatalf("got %d alerts pending while paused, want none", alerter.Pending())
+		}
+
+		alerter.Alerts = nil
+		assertNoError(t, clock.Resume())
+
+		assertSchedulingAround(t, []ScheduledAlert{
+			{At: 10 * time.Minute, Amount: 20},
+			{At: 20 * time.Minute, Amount: 40},
+		}, alerter)
+		assertResponseBody(t, out.String(), "Clock paused\nClock resumed\n")
+	})
+
+	t.Run("pause and resume are announced as messages to announcers", func(t *testing.T) {
+		out := &SpyAnnouncer{}
+		clock := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, out)
+
+		assertNoError(t, clock.Pause())
+		assertNoError(t, clock.Resume())
+
+		assertAnnounced(t, out, poker.PausedMessage, poker.ResumedMessage)
+		assertResponseBody(t, out.String(), "")
+	})
+
+	t.Run("can't pause twice or resume a running clock", func(t *testing.T) {
+		clock := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{})
+
+		if err := clock.Resume(); err != poker.ErrClockNotPaused {
+			t.Errorf("got error %v, want %v", err, poker.ErrClockNotPaused)
+		}
+
+		assertNoError(t, clock.Pause())
+		if err := clock.Pause(); err != poker.ErrClockPaused {
+			t.Errorf("got error %v, want %v", err, poker.ErrClockPaused)
+		}
+	})
+
+	t.Run("skip brings the next level forward", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock := poker.StartBlindClock(alerter, structure, 4, &bytes.Buffer{})
+
+		alerter.Alerts = nil
+		assertNoError(t, clock.SkipLevel())
+
+		assertSchedulingAround(t, []ScheduledAlert{
+			{At: 0, Amount: 20},
+			{At: 10 * time.Minute, Amount: 40},
+		}, alerter)
+	})
+
+	t.Run("skip while paused announces the level and stays paused", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock := poker.StartBlindClock(alerter, structure, 4, &bytes.Buffer{})
+		assertNoError(t, clock.Pause())
+
+		alerter.Alerts = nil
+		assertNoError(t, clock.SkipLevel())
+		assertSchedulingTests(t, []ScheduledAlert{{At: 0, Amount: 20}}, alerter)
+
+		alerter.Alerts = nil
+		assertNoError(t, clock.Resume())
+		assertSchedulingAround(t, []ScheduledAlert{{At: 10 * time.Minute, Amount: 40}}, alerter)
+	})
+
+	t.Run("can't skip past the last level", func(t *testing.T) {
+		clock := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{})
+
+		assertNoError(t, clock.SkipLevel())
+		assertNoError(t, clock.SkipLevel())
+		if err := clock.SkipLevel(); err != poker.ErrNoNextLevel {
+			t.Errorf("got error %v, want %v", err, poker.ErrNoNextLevel)
+		}
+	})
+
+	t.Run("status has the level being played and the time left in it", func(t *testing.T) {
+		clock := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{})
+
+		status := clock.Status()
+		if status.Level != structure.Levels[0] || status.Index != 0 || status.Paused {
+			t.Errorf("got status %+v want the first level running", status)
+		}
+		if status.NextLevelIn > 10*time.Minute || status.NextLevelIn < 10*time.Minute-time.Second {
+			t.Errorf("got next level in %v want about 10m", status.NextLevelIn)
+		}
+
+		assertNoError(t, clock.SkipLevel())
+		assertNoError(t, clock.SkipLevel())
+		assertNoError(t, clock.Pause())
+
+		status = clock.Status()
+		if status.Level != structure.Levels[2] || status.Index != 2 || !status.Paused || status.NextLevelIn != 0 {
+			t.Errorf("got status %+v want the last level paused", status)
+		}
+	})
+
+	t.Run("cancel stops every alert and the clock", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock := poker.StartBlindClock(alerter, structure, 4, &bytes.Buffer{})
+
+		clock.Cancel()
+
+		if alerter.Pending() != 0 {
+			t.Errorf("got %d alerts pending after cancel, want none", alerter.Pending())
+		}
+		if err := clock.Pause(); err != poker.ErrClockStopped {
+			t.Errorf("got error %v, want %v", err, poker.ErrClockStopped)
+		}
+	})
+
+	t.Run("retime gives the levels to come their new length", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock := poker.StartBlindClock(alerter, structure, 4, &bytes.Buffer{})
+
+		alerter.Alerts = nil
+		assertNoError(t, clock.Retime(func(poker.BlindLevel) time.Duration { return 5 * time.Minute }))
+
+		assertSchedulingAround(t, []ScheduledAlert{
+			{At: 5 * time.Minute, Amount: 20},
+			{At: 10 * time.Minute, Amount: 40},
+		}, alerter)
+		if alerter.Pending() != 2 {
+			t.Errorf("got %d alerts pending, want only the 2 retimed levels", alerter.Pending())
+		}
+	})
+
+	t.Run("retime moves on straight away when the level being played already lasted longer", func(t *testing.T) {
+		state := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{}).State()
+		state.RunningSince = state.RunningSince.Add(-15 * time.Minute)
+		alerter := &SpyBlindAlerter{}
+		clock := poker.RestoreBlindClock(alerter, state, &bytes.Buffer{})
+
+		alerter.Alerts = nil
+		assertNoError(t, clock.Retime(func(poker.BlindLevel) time.Duration { return 3 * time.Minute }))
+
+		assertSchedulingAround(t, []ScheduledAlert{{At: 0, Amount: 40}}, alerter)
+	})
+
+	t.Run("retime while paused takes effect on resume", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock := poker.StartBlindClock(alerter, structure, 4, &bytes.Buffer{})
+		assertNoError(t, clock.Pause())
+
+		alerter.Alerts = nil
+		assertNoError(t, clock.Retime(func(poker.BlindLevel) time.Duration { return 5 * time.Minute }))
+		if alerter.Pending() != 0 {
+			t.Fatalf("got %d alerts pending while paused, want none", alerter.Pending())
+		}
+
+		assertNoError(t, clock.Resume())
+		assertSchedulingAround(t, []ScheduledAlert{
+			{At: 5 * time.Minute, Amount: 20},
+			{At: 10 * time.Minute, Amount: 40},
+		}, alerter)
+	})
+
+	t.Run("can't retime a cancelled clock", func(t *testing.T) {
+		clock := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{})
+		clock.Cancel()
+
+		if err := clock.Retime(func(level poker.BlindLevel) time.Duration { return level.Duration }); err != poker.ErrClockStopped {
+			t.Errorf("got error %v, want %v", err, poker.ErrClockStopped)
+		}
+	})
+
+	t.Run("state has the levels and the time played", func(t *testing.T) {
+		clock := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{})
+		assertNoError(t, clock.SkipLevel())
+		assertNoError(t, clock.Pause())
+
+		state := clock.State()
+		if !state.Paused || state.Elapsed > time.Second {
+			t.Errorf("got state %+v want paused at the start", state)
+		}
+		if len(state.Levels) != 3 || state.Levels[1].Level != structure.Levels[1] || state.Levels[1].At > time.Second {
+			t.Errorf("got levels %+v want the second level brought forward", state.Levels)
+		}
+	})
+
+	t.Run("a restored clock counts the time since it was saved and schedules the levels left", func(t *testing.T) {
+		state := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{}).State()
+		state.RunningSince = state.RunningSince.Add(-15 * time.Minute)
+
+		alerter := &SpyBlindAlerter{}
+		clock := poker.RestoreBlindClock(alerter, state, &bytes.Buffer{})
+
+		assertSchedulingAround(t, []ScheduledAlert{
+			{At: 0, Amount: 20},
+			{At: 5 * time.Minute, Amount: 40},
+		}, alerter)
+		if status := clock.Status(); status.Level != structure.Levels[1] {
+			t.Errorf("got level %+v want %+v", status.Level, structure.Levels[1])
+		}
+	})
+
+	t.Run("a restored paused clock waits to be resumed", func(t *testing.T) {
+		paused := poker.StartBlindClock(&SpyBlindAlerter{}, structure, 4, &bytes.Buffer{})
+		assertNoError(t, paused.Pause())
+		state := paused.State()
+		state.RunningSince = state.RunningSince.Add(-15 * time.Minute)
+
+		alerter := &SpyBlindAlerter{}
+		clock := poker.RestoreBlindClock(alerter, state, &bytes.Buffer{})
+
+		if alerter.Pending() != 0 {
+			t.Errorf("got %d alerts pending while paused, want none", alerter.Pending())
+		}
+		if status := clock.Status(); status.Level != structure.Levels[0] || !status.Paused {
+			t.Errorf("got status %+v want the first level paused", status)
+		}
+
+		assertNoError(t, clock.Resume())
+		assertSchedulingAround(t, []ScheduledAlert{
+			{At: 10 * time.Minute, Amount: 20},
+			{At: 20 * time.Minute, Amount: 40},
+		}, alerter)
+	})
+}
+
+// like assertSchedulingTests but allows for the real time that passed while the test ran
+func assertSchedulingAround(t testing.TB, tests []ScheduledAlert, blindAlerter *SpyBlindAlerter) {
+	t.Helper()
+
+	if len(blindAlerter.Alerts) != len(tests) {
+		t.Fatalf("got %d alerts scheduled, want %d, %v", len(blindAlerter.Alerts), len(tests), blindAlerter.Alerts)
+	}
+
+	for i, want := range tests {
+		got := blindAlerter.Alerts[i]
+		if got.Amount != want.Amount {
+			t.Errorf("got blind amount of %d, wanted %d", got.Amount, want.Amount)
+		}
+		if got.At > want.At || got.At < want.At-time.Second {
+			t.Errorf("got scheduled time of %v, wanted about %v", got.At, want.At)
+		}
+	}
+}
+
+// SpyAnnouncer keeps the messages announced to it, anything written as text ends up in the buffer
+type SpyAnnouncer struct {
+	bytes.Buffer
+	Messages []poker.Message
+}
+
+func (s *SpyAnnouncer) Announce(message poker.Message) {
+	s.Messages = append(s.Messages, message)
+}
+
+func assertAnnounced(t testing.TB, announcer *SpyAnnouncer, want ...poker.MessageType) {
+	t.Helper()
+	var got []poker.MessageType
+	for _, message := range announcer.Messages {
+		got = append(got, message.Type)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got messages %v announced want %v", got, want)
+	}
+}
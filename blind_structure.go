@@ -0,0 +1,244 @@
+package poker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultBlindStructure = "standard"
+
+type BlindLevel struct {
+	SmallBlind int
+	BigBlind   int
+	Ante       int
+	Duration   time.Duration
+	Break      bool
+	// said when the level starts, like entries that open or close with it
+	Notice string `json:",omitempty"`
+}
+
+func (l BlindLevel) String() string {
+	if l.Break {
+		return fmt.Sprintf("break for %v", l.Duration)
+	}
+	if l.Ante > 0 {
+		return fmt.Sprintf("%d/%d ante %d", l.SmallBlind, l.BigBlind, l.Ante)
+	}
+	return fmt.Sprintf("%d/%d", l.SmallBlind, l.BigBlind)
+}
+
+type BlindStructure struct {
+	Name   string
+	Levels []BlindLevel
+	// added to the duration of every blind level for each player in the game
+	DurationPerPlayer time.Duration
+}
+
+// how long a level lasts in a game with numPlayers, breaks are never scaled
+func (b BlindStructure) LevelDuration(level BlindLevel, numPlayers int) time.Duration {
+	if level.Break {
+		return level.Duration
+	}
+	return level.Duration + time.Duration(numPlayers)*b.DurationPerPlayer
+}
+
+func (b BlindStructure) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("blind structure has no name")
+	}
+	if len(b.Levels) == 0 {
+		return fmt.Errorf("blind structure %q has no levels", b.Name)
+	}
+	if b.DurationPerPlayer < 0 {
+		return fmt.Errorf("blind structure %q has a negative duration per player of %v", b.Name, b.DurationPerPlayer)
+	}
+
+	var previous *BlindLevel
+	for i, level := range b.Levels {
+		if level.Duration <= 0 {
+			return fmt.Errorf("blind structure %q: level %d must have a positive duration, got %v", b.Name, i+1, level.Duration)
+		}
+		if level.Break {
+			continue
+		}
+		if level.SmallBlind <= 0 || level.BigBlind <= 0 {
+			return fmt.Errorf("blind structure %q: level %d must have positive blinds, got %v", b.Name, i+1, level)
+		}
+		if level.SmallBlind > level.BigBlind {
+			return fmt.Errorf("blind structure %q: level %d has a small blind of %d bigger than the big blind of %d", b.Name, i+1, level.SmallBlind, level.BigBlind)
+		}
+		if level.Ante < 0 {
+			return fmt.Errorf("blind structure %q: level %d has a negative ante of %d", b.Name, i+1, level.Ante)
+		}
+		if previous != nil && (level.SmallBlind < previous.SmallBlind || level.BigBlind <= previous.BigBlind || level.Ante < previous.Ante) {
+			return fmt.Errorf("blind structure %q: level %d (%v) must increase from the previous level (%v)", b.Name, i+1, level, *previous)
+		}
+		previous = &b.Levels[i]
+	}
+
+	return nil
+}
+
+// blind structures available to a game, indexed by name
+type BlindStructures map[string]BlindStructure
+
+var ErrBlindStructureExists = errors.New("blind structure already exists")
+
+// Add makes the structure available, a structure with the name of one already added or of a preset is an error
+func (b BlindStructures) Add(structure BlindStructure) error {
+	if err := structure.Validate(); err != nil {
+		return fmt.Errorf("invalid blind structure, %v", err)
+	}
+	if _, ok := b[structure.Name]; ok {
+		return fmt.Errorf("%w %q, give it another name", ErrBlindStructureExists, structure.Name)
+	}
+	b[structure.Name] = structure
+	return nil
+}
+
+// empty name returns the default structure
+var ErrUnknownBlindStructure = errors.New("unknown blind structure")
+
+func (b BlindStructures) Get(name string) (BlindStructure, error) {
+	if name == "" {
+		name = DefaultBlindStructure
+	}
+	structure, ok := b[name]
+	if !ok {
+		return BlindStructure{}, fmt.Errorf("%w %q, choose one of %s", ErrUnknownBlindStructure, name, strings.Join(b.Names(), ", "))
+	}
+	return structure, nil
+}
+
+func (b BlindStructures) Names() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// presets shipped with the app, standard keeps the original 5 + 1 minute per player levels
+func BlindPresets() BlindStructures {
+	return BlindStructures{
+		"standard": {
+			Name:              "standard",
+			Levels:            levels(5*time.Minute, false, 100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000),
+			DurationPerPlayer: time.Minute,
+		},
+		"turbo": {
+			Name:   "turbo",
+			Levels: levels(6*time.Minute, false, 25, 50, 75, 100, 150, 200, 300, 400, 600, 800, 1000, 1500, 2000),
+		},
+		"deepstack": {
+			Name:   "deepstack",
+			Levels: deepStackLevels(),
+		},
+	}
+}
+
+func deepStackLevels() []BlindLevel {
+	levelDuration := 20 * time.Minute
+	breakLevel := BlindLevel{Duration: 10 * time.Minute, Break: true}
+
+	l := levels(levelDuration, false, 25, 50, 75, 100)
+	l = append(l, breakLevel)
+	l = append(l, levels(levelDuration, false, 150, 200, 250, 300)...)
+	l = append(l, breakLevel)
+	return append(l, levels(levelDuration, true, 400, 500, 600, 800, 1000, 1500, 2000, 3000)...)
+}
+
+// levels with a big blind of twice the small blind and, optionally, an ante of a quarter of the small blind
+func levels(duration time.Duration, withAnte bool, smallBlinds ...int) []BlindLevel {
+	l := make([]BlindLevel, len(smallBlinds))
+	for i, small := range smallBlinds {
+		l[i] = BlindLevel{SmallBlind: small, BigBlind: 2 * small, Duration: duration}
+		if withAnte {
+			l[i].Ante = small / 4
+		}
+	}
+	return l
+}
+
+type blindStructureFile struct {
+	Name              string           `json:"name" yaml:"name"`
+	DurationPerPlayer string           `json:"durationPerPlayer" yaml:"durationPerPlayer"`
+	Levels            []blindLevelFile `json:"levels" yaml:"levels"`
+}
+
+type blindLevelFile struct {
+	SmallBlind int    `json:"smallBlind" yaml:"smallBlind"`
+	BigBlind   int    `json:"bigBlind" yaml:"bigBlind"`
+	Ante       int    `json:"ante" yaml:"ante"`
+	Duration   string `json:"duration" yaml:"duration"`
+	Break      bool   `json:"break" yaml:"break"`
+}
+
+// loads a blind structure from a .json, .yaml or .yml file. Durations use time.ParseDuration format e.g. "15m"
+func LoadBlindStructure(path string) (BlindStructure, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("could not read blind structure file %s, %v", path, err)
+	}
+
+	var file blindStructureFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return BlindStructure{}, fmt.Errorf("unsupported blind structure file extension %q, use .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("could not parse blind structure file %s, %v", path, err)
+	}
+
+	structure, err := file.toBlindStructure()
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("invalid blind structure file %s, %v", path, err)
+	}
+
+	if err := structure.Validate(); err != nil {
+		return BlindStructure{}, fmt.Errorf("invalid blind structure file %s, %v", path, err)
+	}
+
+	return structure, nil
+}
+
+func (f blindStructureFile) toBlindStructure() (BlindStructure, error) {
+	structure := BlindStructure{Name: f.Name, Levels: make([]BlindLevel, len(f.Levels))}
+
+	if f.DurationPerPlayer != "" {
+		perPlayer, err := time.ParseDuration(f.DurationPerPlayer)
+		if err != nil {
+			return BlindStructure{}, fmt.Errorf("bad durationPerPlayer %q, %v", f.DurationPerPlayer, err)
+		}
+		structure.DurationPerPlayer = perPlayer
+	}
+
+	for i, level := range f.Levels {
+		duration, err := time.ParseDuration(level.Duration)
+		if err != nil {
+			return BlindStructure{}, fmt.Errorf("level %d has a bad duration %q, %v", i+1, level.Duration, err)
+		}
+		structure.Levels[i] = BlindLevel{
+			SmallBlind: level.SmallBlind,
+			BigBlind:   level.BigBlind,
+			Ante:       level.Ante,
+			Duration:   duration,
+			Break:      level.Break,
+		}
+	}
+
+	return structure, nil
+}
@@ -0,0 +1,228 @@
+package poker_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestBlindStructureValidate(t *testing.T) {
+	level := func(small, big, ante int) poker.BlindLevel {
+		return poker.BlindLevel{SmallBlind: small, BigBlind: big, Ante: ante, Duration: 10 * time.Minute}
+	}
+
+	tests := []struct {
+		name      string
+		structure poker.BlindStructure
+		wantErr   string
+	}{
+		{
+			name:      "valid structure",
+			structure: poker.BlindStructure{Name: "ok", Levels: []poker.BlindLevel{level(10, 20, 0), {Duration: time.Minute, Break: true}, level(20, 40, 5)}},
+		},
+		{
+			name:      "missing name",
+			structure: poker.BlindStructure{Levels: []poker.BlindLevel{level(10, 20, 0)}},
+			wantErr:   "no name",
+		},
+		{
+			name:      "no levels",
+			structure: poker.BlindStructure{Name: "empty"},
+			wantErr:   "no levels",
+		},
+		{
+			name:      "zero duration",
+			structure: poker.BlindStructure{Name: "zero", Levels: []poker.BlindLevel{{SmallBlind: 10, BigBlind: 20}}},
+			wantErr:   "level 1 must have a positive duration",
+		},
+		{
+			name:      "zero duration break",
+			structure: poker.BlindStructure{Name: "zero", Levels: []poker.BlindLevel{level(10, 20, 0), {Break: true}}},
+			wantErr:   "level 2 must have a positive duration",
+		},
+		{
+			name:      "small blind bigger than big blind",
+			structure: poker.BlindStructure{Name: "upside down", Levels: []poker.BlindLevel{level(40, 20, 0)}},
+			wantErr:   "small blind of 40 bigger than the big blind of 20",
+		},
+		{
+			name:      "decreasing blinds",
+			structure: poker.BlindStructure{Name: "down", Levels: []poker.BlindLevel{level(20, 40, 0), level(10, 20, 0)}},
+			wantErr:   "level 2 (10/20) must increase from the previous level (20/40)",
+		},
+		{
+			name:      "decreasing blinds across a break",
+			structure: poker.BlindStructure{Name: "down", Levels: []poker.BlindLevel{level(20, 40, 0), {Duration: time.Minute, Break: true}, level(20, 40, 0)}},
+			wantErr:   "level 3 (20/40) must increase",
+		},
+		{
+			name:      "decreasing ante",
+			structure: poker.BlindStructure{Name: "ante", Levels: []poker.BlindLevel{level(20, 40, 10), level(30, 60, 5)}},
+			wantErr:   "level 2 (30/60 ante 5) must increase",
+		},
+		{
+			name:      "negative per player duration",
+			structure: poker.BlindStructure{Name: "negative", Levels: []poker.BlindLevel{level(10, 20, 0)}, DurationPerPlayer: -time.Minute},
+			wantErr:   "negative duration per player",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.structure.Validate()
+			assertErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBlindPresets(t *testing.T) {
+	presets := poker.BlindPresets()
+
+	want := []string{"deepstack", "standard", "turbo"}
+	if got := presets.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got presets %v, want %v", got, want)
+	}
+
+	for name, structure := range presets {
+		t.Run(name, func(t *testing.T) {
+			assertNoError(t, structure.Validate())
+		})
+	}
+
+	t.Run("empty name gets the default structure", func(t *testing.T) {
+		got, err := presets.Get("")
+		assertNoError(t, err)
+		if got.Name != poker.DefaultBlindStructure {
+			t.Errorf("got %q, want %q", got.Name, poker.DefaultBlindStructure)
+		}
+	})
+
+	t.Run("unknown name lists the available structures", func(t *testing.T) {
+		_, err := presets.Get("hyper")
+		assertErrorContains(t, err, `unknown blind structure "hyper", choose one of deepstack, standard, turbo`)
+	})
+
+	t.Run("invalid structures are not added", func(t *testing.T) {
+		err := presets.Add(poker.BlindStructure{Name: "empty"})
+		assertErrorContains(t, err, "no levels")
+
+		if _, ok := presets["empty"]; ok {
+			t.Error("invalid structure was added")
+		}
+	})
+
+	t.Run("structures don't replace one with the same name", func(t *testing.T) {
+		standard := presets[poker.DefaultBlindStructure]
+		err := presets.Add(poker.BlindStructure{
+			Name:   poker.DefaultBlindStructure,
+			Levels: []poker.BlindLevel{{SmallBlind: 1, BigBlind: 2, Duration: time.Minute}},
+		})
+
+		if !errors.Is(err, poker.ErrBlindStructureExists) {
+			t.Errorf("got error %v, want %v", err, poker.ErrBlindStructureExists)
+		}
+		if got := presets[poker.DefaultBlindStructure]; !reflect.DeepEqual(got, standard) {
+			t.Errorf("got %+v after adding a structure with its name, want it unchanged", got)
+		}
+	})
+}
+
+func TestLoadBlindStructure(t *testing.T) {
+	want := poker.BlindStructure{
+		Name: "home game",
+		Levels: []poker.BlindLevel{
+			{SmallBlind: 25, BigBlind: 50, Duration: 15 * time.Minute},
+			{Duration: 10 * time.Minute, Break: true},
+			{SmallBlind: 50, BigBlind: 100, Ante: 10, Duration: 15 * time.Minute},
+		},
+		DurationPerPlayer: 30 * time.Second,
+	}
+
+	t.Run("from json", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "blinds.json", `{
+			"name": "home game",
+			"durationPerPlayer": "30s",
+			"levels": [
+				{"smallBlind": 25, "bigBlind": 50, "duration": "15m"},
+				{"break": true, "duration": "10m"},
+				{"smallBlind": 50, "bigBlind": 100, "ante": 10, "duration": "15m"}
+			]}`)
+
+		got, err := poker.LoadBlindStructure(path)
+		assertNoError(t, err)
+		assertBlindStructure(t, got, want)
+	})
+
+	t.Run("from yaml", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "blinds.yaml", strings.Join([]string{
+			"name: home game",
+			"durationPerPlayer: 30s",
+			"levels:",
+			"  - {smallBlind: 25, bigBlind: 50, duration: 15m}",
+			"  - {break: true, duration: 10m}",
+			"  - {smallBlind: 50, bigBlind: 100, ante: 10, duration: 15m}",
+		}, "\n"))
+
+		got, err := poker.LoadBlindStructure(path)
+		assertNoError(t, err)
+		assertBlindStructure(t, got, want)
+	})
+
+	t.Run("bad duration", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "blinds.json", `{"name": "bad", "levels": [{"smallBlind": 25, "bigBlind": 50, "duration": "soon"}]}`)
+
+		_, err := poker.LoadBlindStructure(path)
+		assertErrorContains(t, err, `level 1 has a bad duration "soon"`)
+	})
+
+	t.Run("invalid structure", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "blinds.yml", "name: bad\nlevels:\n  - {smallBlind: 50, bigBlind: 100, duration: 5m}\n  - {smallBlind: 25, bigBlind: 50, duration: 5m}\n")
+
+		_, err := poker.LoadBlindStructure(path)
+		assertErrorContains(t, err, "level 2 (25/50) must increase")
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		path := writeBlindStructureFile(t, "blinds.txt", "")
+
+		_, err := poker.LoadBlindStructure(path)
+		assertErrorContains(t, err, "unsupported blind structure file extension")
+	})
+}
+
+func writeBlindStructureFile(t testing.TB, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+		t.Fatalf("could not write blind structure file %v", err)
+	}
+	return path
+}
+
+func assertBlindStructure(t testing.TB, got, want poker.BlindStructure) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func assertErrorContains(t testing.TB, err error, want string) {
+	t.Helper()
+	if want == "" {
+		assertNoError(t, err)
+		return
+	}
+	if err == nil {
+		t.Fatalf("expected an error containing %q but didn't get one", want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %q, want it to contain %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,112 @@
+package cards
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Suit uint8
+
+const (
+	Clubs Suit = iota
+	Diamonds
+	Hearts
+	Spades
+)
+
+var Suits = []Suit{Clubs, Diamonds, Hearts, Spades}
+
+const suitSymbols = "cdhs"
+
+func (s Suit) String() string {
+	if int(s) >= len(suitSymbols) {
+		return "?"
+	}
+	return string(suitSymbols[s])
+}
+
+// ranks match their face value, aces are high and also play low in the wheel straight
+type Rank uint8
+
+const (
+	Two Rank = iota + 2
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Ace
+)
+
+var Ranks = []Rank{Two, Three, Four, Five, Six, Seven, Eight, Nine, Ten, Jack, Queen, King, Ace}
+
+const rankSymbols = "23456789TJQKA"
+
+func (r Rank) String() string {
+	if r < Two || r > Ace {
+		return "?"
+	}
+	return string(rankSymbols[r-Two])
+}
+
+type Card struct {
+	Rank Rank
+	Suit Suit
+}
+
+// String returns the short form of the card e.g. "As" or "Td"
+func (c Card) String() string {
+	return c.Rank.String() + c.Suit.String()
+}
+
+// ParseCard reads the short form of a card, "10" is accepted for tens
+func ParseCard(s string) (Card, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 3 && strings.HasPrefix(s, "10") {
+		s = "T" + s[2:]
+	}
+	if len(s) != 2 {
+		return Card{}, fmt.Errorf("invalid card %q, want a rank and a suit e.g. As", s)
+	}
+
+	rank := strings.IndexByte(rankSymbols, strings.ToUpper(s[:1])[0])
+	if rank < 0 {
+		return Card{}, fmt.Errorf("invalid rank in card %q", s)
+	}
+
+	suit := strings.IndexByte(suitSymbols, strings.ToLower(s[1:])[0])
+	if suit < 0 {
+		return Card{}, fmt.Errorf("invalid suit in card %q", s)
+	}
+
+	return Card{Rank: Rank(rank) + Two, Suit: Suit(suit)}, nil
+}
+
+// ParseCards reads space separated cards e.g. "As Kd 7h"
+func ParseCards(s string) ([]Card, error) {
+	fields := strings.Fields(s)
+	cards := make([]Card, len(fields))
+	for i, field := range fields {
+		card, err := ParseCard(field)
+		if err != nil {
+			return nil, err
+		}
+		cards[i] = card
+	}
+	return cards, nil
+}
+
+// MustParseCards is ParseCards for known good input, it panics on error
+func MustParseCards(s string) []Card {
+	cards, err := ParseCards(s)
+	if err != nil {
+		panic(err)
+	}
+	return cards
+}
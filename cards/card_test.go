@@ -0,0 +1,130 @@
+package cards_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/andremfp/poker-app/cards"
+)
+
+func TestParseCard(t *testing.T) {
+	tests := []struct {
+		input string
+		want  cards.Card
+	}{
+		{"As", cards.Card{Rank: cards.Ace, Suit: cards.Spades}},
+		{"td", cards.Card{Rank: cards.Ten, Suit: cards.Diamonds}},
+		{"10h", cards.Card{Rank: cards.Ten, Suit: cards.Hearts}},
+		{"2C", cards.Card{Rank: cards.Two, Suit: cards.Clubs}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := cards.ParseCard(tt.input)
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "A", "1s", "Ax", "Asd"} {
+		t.Run("invalid "+bad, func(t *testing.T) {
+			if _, err := cards.ParseCard(bad); err == nil {
+				t.Errorf("expected an error parsing %q", bad)
+			}
+		})
+	}
+
+	t.Run("string round trips", func(t *testing.T) {
+		for _, card := range cards.MustParseCards("As Td 2c 9h") {
+			parsed, _ := cards.ParseCard(card.String())
+			if parsed != card {
+				t.Errorf("got %v after round trip, want %v", parsed, card)
+			}
+		}
+	})
+}
+
+func TestDeck(t *testing.T) {
+	t.Run("new deck has 52 different cards", func(t *testing.T) {
+		deck := cards.NewDeck()
+		deck.Shuffle(rand.New(rand.NewSource(42)))
+
+		seen := map[cards.Card]bool{}
+		for deck.Len() > 0 {
+			card, err := deck.Deal()
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			if seen[card] {
+				t.Fatalf("dealt %v twice", card)
+			}
+			seen[card] = true
+		}
+
+		if len(seen) != 52 {
+			t.Errorf("got %d cards, want 52", len(seen))
+		}
+	})
+
+	t.Run("dealing from an empty deck errors", func(t *testing.T) {
+		deck := cards.NewDeck()
+		if _, err := deck.DealN(52); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		if _, err := deck.Deal(); err != cards.ErrEmptyDeck {
+			t.Errorf("got error %v, want %v", err, cards.ErrEmptyDeck)
+		}
+		if _, err := cards.NewDeck().DealN(53); err != cards.ErrEmptyDeck {
+			t.Errorf("got error %v, want %v", err, cards.ErrEmptyDeck)
+		}
+	})
+
+	t.Run("a deck of given cards deals them in order", func(t *testing.T) {
+		deck := cards.DeckOf(cards.MustParseCards("As Kd 7h")...)
+
+		dealt, err := deck.DealN(3)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if got := fmt.Sprint(dealt); got != "[As Kd 7h]" {
+			t.Errorf("got %s, want [As Kd 7h]", got)
+		}
+	})
+
+	t.Run("crypto seeded shuffles differ", func(t *testing.T) {
+		a, b := cards.NewDeck(), cards.NewDeck()
+		for _, deck := range []*cards.Deck{a, b} {
+			rng, err := cards.NewCryptoRand()
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			deck.Shuffle(rng)
+		}
+
+		handA, _ := a.DealN(52)
+		handB, _ := b.DealN(52)
+		if fmt.Sprint(handA) == fmt.Sprint(handB) {
+			t.Error("got the same shuffle twice")
+		}
+	})
+
+	t.Run("shuffle is deterministic for the same source", func(t *testing.T) {
+		a, b := cards.NewDeck(), cards.NewDeck()
+		a.Shuffle(rand.New(rand.NewSource(7)))
+		b.Shuffle(rand.New(rand.NewSource(7)))
+
+		handA, _ := a.DealN(5)
+		handB, _ := b.DealN(5)
+		for i := range handA {
+			if handA[i] != handB[i] {
+				t.Fatalf("got %v and %v, want the same order", handA, handB)
+			}
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package cards
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+var ErrEmptyDeck = errors.New("no cards left in the deck")
+
+type Deck struct {
+	cards []Card
+}
+
+// NewDeck returns the 52 cards in order, shuffle it before dealing
+func NewDeck() *Deck {
+	d := &Deck{cards: make([]Card, 0, len(Suits)*len(Ranks))}
+	for _, suit := range Suits {
+		for _, rank := range Ranks {
+			d.cards = append(d.cards, Card{Rank: rank, Suit: suit})
+		}
+	}
+	return d
+}
+
+// DeckOf deals the cards in the order given, to replay a hand or stack the deck in tests
+func DeckOf(cards ...Card) *Deck {
+	return &Deck{cards: append([]Card(nil), cards...)}
+}
+
+// NewCryptoRand is a rand seeded from crypto/rand, so the shuffles of a real game can't be guessed from the time
+// it started
+func NewCryptoRand() (*rand.Rand, error) {
+	var seed [8]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return nil, fmt.Errorf("could not seed the shuffle, %v", err)
+	}
+	return rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(seed[:])))), nil
+}
+
+func (d *Deck) Shuffle(rng *rand.Rand) {
+	rng.Shuffle(len(d.cards), func(i, j int) {
+		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+	})
+}
+
+// Deal takes the top card off the deck
+func (d *Deck) Deal() (Card, error) {
+	if len(d.cards) == 0 {
+		return Card{}, ErrEmptyDeck
+	}
+	card := d.cards[0]
+	d.cards = d.cards[1:]
+	return card, nil
+}
+
+// DealN takes n cards off the top of the deck, or none if there aren't enough
+func (d *Deck) DealN(n int) ([]Card, error) {
+	if n > len(d.cards) {
+		return nil, ErrEmptyDeck
+	}
+	dealt := make([]Card, n)
+	copy(dealt, d.cards[:n])
+	d.cards = d.cards[n:]
+	return dealt, nil
+}
+
+func (d *Deck) Len() int {
+	return len(d.cards)
+}
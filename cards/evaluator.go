@@ -0,0 +1,210 @@
+package cards
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+type Category uint8
+
+const (
+	HighCard Category = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+var categoryNames = []string{
+	"high card",
+	"one pair",
+	"two pair",
+	"three of a kind",
+	"straight",
+	"flush",
+	"full house",
+	"four of a kind",
+	"straight flush",
+}
+
+func (c Category) String() string {
+	if int(c) >= len(categoryNames) {
+		return "unknown"
+	}
+	return categoryNames[c]
+}
+
+// HandRank is the strength of the best five card hand, a higher HandRank beats a lower one
+// and equal ranks split the pot.
+// The category is kept in the top bits followed by up to five ranks (4 bits each) that
+// break ties inside the category, most significant first.
+type HandRank uint32
+
+const (
+	categoryShift = 20
+	rankBits      = 4
+)
+
+func (h HandRank) Category() Category {
+	return Category(h >> categoryShift)
+}
+
+// Kickers returns the ranks that decide between hands of the same category, in order of importance.
+// Straights only need their top card, the wheel (A-2-3-4-5) is five high.
+func (h HandRank) Kickers() []Rank {
+	var kickers []Rank
+	for shift := categoryShift - rankBits; shift >= 0; shift -= rankBits {
+		rank := Rank(h>>shift) & 0xF
+		if rank == 0 {
+			break
+		}
+		kickers = append(kickers, rank)
+	}
+	return kickers
+}
+
+func (h HandRank) String() string {
+	kickers := make([]string, 0, 5)
+	for _, rank := range h.Kickers() {
+		kickers = append(kickers, rank.String())
+	}
+	return fmt.Sprintf("%s (%s)", h.Category(), strings.Join(kickers, " "))
+}
+
+func newHandRank(category Category, ranks ...Rank) HandRank {
+	h := HandRank(category) << categoryShift
+	shift := categoryShift - rankBits
+	for _, rank := range ranks {
+		h |= HandRank(rank) << shift
+		shift -= rankBits
+	}
+	return h
+}
+
+// Evaluate returns the rank of the best five card hand that can be made from 5, 6 or 7 cards
+func Evaluate(hand ...Card) (HandRank, error) {
+	if len(hand) < 5 || len(hand) > 7 {
+		return 0, fmt.Errorf("can only evaluate 5 to 7 cards, got %d", len(hand))
+	}
+
+	// one bit per rank for each suit and every suit combined, plus how many of each rank
+	var suitMasks [4]uint16
+	var counts [Ace + 1]uint8
+	var seen uint64
+
+	for _, card := range hand {
+		if card.Rank < Two || card.Rank > Ace || card.Suit > Spades {
+			return 0, fmt.Errorf("invalid card %v", card)
+		}
+		bit := uint64(1) << (uint(card.Suit)*16 + uint(card.Rank))
+		if seen&bit != 0 {
+			return 0, fmt.Errorf("card %v appears more than once", card)
+		}
+		seen |= bit
+
+		suitMasks[card.Suit] |= 1 << card.Rank
+		counts[card.Rank]++
+	}
+
+	for _, mask := range suitMasks {
+		if bits.OnesCount16(mask) < 5 {
+			continue
+		}
+		// at most 7 cards so only one suit can have a flush
+		if high := straightHigh(mask); high != 0 {
+			return newHandRank(StraightFlush, high), nil
+		}
+		return newHandRank(Flush, topRanks(mask, 5)...), nil
+	}
+
+	var quads, trips, pairs, singles []Rank
+	for rank := Ace; rank >= Two; rank-- {
+		switch counts[rank] {
+		case 4:
+			quads = append(quads, rank)
+		case 3:
+			trips = append(trips, rank)
+		case 2:
+			pairs = append(pairs, rank)
+		case 1:
+			singles = append(singles, rank)
+		}
+	}
+
+	if len(quads) > 0 {
+		return newHandRank(FourOfAKind, quads[0], highestOf(trips, pairs, singles)), nil
+	}
+
+	if len(trips) > 0 && len(trips)+len(pairs) >= 2 {
+		// a second set of trips plays as the pair
+		pair := Rank(0)
+		if len(trips) > 1 {
+			pair = trips[1]
+		}
+		if len(pairs) > 0 && pairs[0] > pair {
+			pair = pairs[0]
+		}
+		return newHandRank(FullHouse, trips[0], pair), nil
+	}
+
+	all := suitMasks[Clubs] | suitMasks[Diamonds] | suitMasks[Hearts] | suitMasks[Spades]
+	if high := straightHigh(all); high != 0 {
+		return newHandRank(Straight, high), nil
+	}
+
+	if len(trips) > 0 {
+		return newHandRank(ThreeOfAKind, trips[0], singles[0], singles[1]), nil
+	}
+
+	if len(pairs) >= 2 {
+		// a third pair can still play as the kicker
+		return newHandRank(TwoPair, pairs[0], pairs[1], highestOf(pairs[2:], singles)), nil
+	}
+
+	if len(pairs) == 1 {
+		return newHandRank(OnePair, pairs[0], singles[0], singles[1], singles[2]), nil
+	}
+
+	return newHandRank(HighCard, singles[:5]...), nil
+}
+
+// highest card of a straight contained in the rank mask, 0 if there is none
+func straightHigh(mask uint16) Rank {
+	// the ace also plays low, below the two
+	if mask&(1<<Ace) != 0 {
+		mask |= 1 << 1
+	}
+	for high := Ace; high >= Five; high-- {
+		run := uint16(0x1F) << (high - 4)
+		if mask&run == run {
+			return high
+		}
+	}
+	return 0
+}
+
+func topRanks(mask uint16, n int) []Rank {
+	ranks := make([]Rank, 0, n)
+	for rank := Ace; rank >= Two && len(ranks) < n; rank-- {
+		if mask&(1<<rank) != 0 {
+			ranks = append(ranks, rank)
+		}
+	}
+	return ranks
+}
+
+// highest rank found in any of the groups, each group is sorted high to low
+func highestOf(groups ...[]Rank) Rank {
+	highest := Rank(0)
+	for _, group := range groups {
+		if len(group) > 0 && group[0] > highest {
+			highest = group[0]
+		}
+	}
+	return highest
+}
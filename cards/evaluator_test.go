@@ -0,0 +1,179 @@
+package cards_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/andremfp/poker-app/cards"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		hand     string
+		category cards.Category
+		kickers  string
+	}{
+		{"royal flush", "As Ks Qs Js Ts", cards.StraightFlush, "A"},
+		{"steel wheel", "5d 4d 3d 2d Ad", cards.StraightFlush, "5"},
+		{"straight flush beats the flush in seven cards", "9h 8h 7h 6h 5h Ah 2h", cards.StraightFlush, "9"},
+		{"four of a kind", "7c 7d 7h 7s Kd", cards.FourOfAKind, "7 K"},
+		{"four of a kind picks the best kicker over trips", "7c 7d 7h 7s 9d 9h 9s", cards.FourOfAKind, "7 9"},
+		{"full house", "Kc Kd Kh 2s 2d", cards.FullHouse, "K 2"},
+		{"full house from two trips", "Qc Qd Qh 4s 4d 4h As", cards.FullHouse, "Q 4"},
+		{"full house uses the best pair", "3c 3d 3h 9s 9d Js Jd", cards.FullHouse, "3 J"},
+		{"full house with the lower trips over a higher pair", "Ac Ad 8h 8s 8d", cards.FullHouse, "8 A"},
+		{"flush", "Ah Jh 8h 4h 2h", cards.Flush, "A J 8 4 2"},
+		{"flush uses the five best suited cards", "Ah Jh 8h 4h 2h 3h Kd", cards.Flush, "A J 8 4 3"},
+		{"straight", "9c 8d 7h 6s 5d", cards.Straight, "9"},
+		{"broadway", "Ac Kd Qh Js Td", cards.Straight, "A"},
+		{"wheel", "Ac 2d 3h 4s 5d", cards.Straight, "5"},
+		{"wheel with a six is a six high straight", "Ac 2d 3h 4s 5d 6c", cards.Straight, "6"},
+		{"straight with a pair in seven cards", "Tc 9d 8h 7s 6d 6c 2h", cards.Straight, "T"},
+		{"not a straight around the corner", "Qc Kd Ah 2s 3d", cards.HighCard, "A K Q 3 2"},
+		{"three of a kind", "8c 8d 8h Ks 3d", cards.ThreeOfAKind, "8 K 3"},
+		{"three of a kind in seven cards", "8c 8d 8h Ks 3d 2c Jh", cards.ThreeOfAKind, "8 K J"},
+		{"two pair", "Jc Jd 4h 4s Ad", cards.TwoPair, "J 4 A"},
+		{"two pair from three pairs uses the third pair as a kicker", "Jc Jd 4h 4s 9d 9c 2h", cards.TwoPair, "J 9 4"},
+		{"one pair", "Tc Td Ah 7s 3d", cards.OnePair, "T A 7 3"},
+		{"one pair in six cards", "Tc Td Ah 7s 3d Kc", cards.OnePair, "T A K 7"},
+		{"high card", "Ac Jd 9h 6s 3d", cards.HighCard, "A J 9 6 3"},
+		{"high card in seven cards", "Ac Jd 9h 6s 3d 2c 4h", cards.HighCard, "A J 9 6 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mustEvaluate(t, tt.hand)
+
+			if got.Category() != tt.category {
+				t.Errorf("got category %v, want %v", got.Category(), tt.category)
+			}
+
+			want := mustParseRanks(t, tt.kickers)
+			if !reflect.DeepEqual(got.Kickers(), want) {
+				t.Errorf("got kickers %v, want %v", got.Kickers(), want)
+			}
+		})
+	}
+}
+
+func TestEvaluateComparesHands(t *testing.T) {
+	tests := []struct {
+		name          string
+		better, worse string
+	}{
+		{"straight flush beats four of a kind", "9h 8h 7h 6h 5h", "Ac Ad Ah As Kd"},
+		{"four of a kind beats full house", "2c 2d 2h 2s 3d", "Ac Ad Ah Ks Kd"},
+		{"full house beats flush", "2c 2d 2h 3s 3d", "Ah Kh Qh Jh 9h"},
+		{"flush beats straight", "7h 5h 4h 3h 2h", "Ac Kd Qh Js Td"},
+		{"straight beats three of a kind", "Ac 2d 3h 4s 5d", "Ac Ad Ah Ks Qd"},
+		{"six high straight beats the wheel", "2c 3d 4h 5s 6d", "Ac 2d 3h 4s 5d"},
+		{"three of a kind beats two pair", "2c 2d 2h 4s 5d", "Ac Ad Kh Ks Qd"},
+		{"two pair beats one pair", "3c 3d 2h 2s 4d", "Ac Ad Kh Qs Jd"},
+		{"one pair beats high card", "2c 2d 3h 4s 5d", "Ac Kd Qh Js 9d"},
+		{"higher pair wins", "Kc Kd 3h 4s 5d", "Qc Qd Ah Ks Jd"},
+		{"same pair decided by kicker", "Kc Kd Ah 4s 3d", "Kh Ks Qh Js Td"},
+		{"same two pair decided by kicker", "Kc Kd 5h 5s 3d", "Kh Ks 5c 5d 2d"},
+		{"higher full house by trips", "3c 3d 3h 2s 2d", "2c 2h 2s Ac Ad"},
+		{"flush decided by the last card", "Ah Jh 8h 4h 3h", "Ad Jd 8d 4d 2d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			better := mustEvaluate(t, tt.better)
+			worse := mustEvaluate(t, tt.worse)
+
+			if better <= worse {
+				t.Errorf("expected %v to beat %v", better, worse)
+			}
+		})
+	}
+
+	t.Run("same hand in different suits ties", func(t *testing.T) {
+		a := mustEvaluate(t, "Ac Kd Qh 9s 3d 2c 4h")
+		b := mustEvaluate(t, "Ad Kc Qs 9h 4c 3h 2d")
+
+		if a != b {
+			t.Errorf("expected %v and %v to tie", a, b)
+		}
+	})
+
+	t.Run("board plays for both players", func(t *testing.T) {
+		board := "Ac Kd Qh Js Td"
+		a := mustEvaluate(t, board+" 2c 3c")
+		b := mustEvaluate(t, board+" 4h 5h")
+
+		if a != b {
+			t.Errorf("expected %v and %v to tie", a, b)
+		}
+	})
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		hand []cards.Card
+	}{
+		{"too few cards", cards.MustParseCards("As Ks Qs Js")},
+		{"too many cards", cards.MustParseCards("As Ks Qs Js Ts 9s 8s 7s")},
+		{"duplicate card", cards.MustParseCards("As As Qs Js Ts")},
+		{"invalid card", append(cards.MustParseCards("As Ks Qs Js"), cards.Card{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cards.Evaluate(tt.hand...); err == nil {
+				t.Error("expected an error but didn't get one")
+			}
+		})
+	}
+}
+
+func BenchmarkEvaluateSevenCards(b *testing.B) {
+	deck := cards.NewDeck()
+	deck.Shuffle(rand.New(rand.NewSource(1)))
+	hand, _ := deck.DealN(7)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cards.Evaluate(hand...)
+	}
+}
+
+func mustEvaluate(t testing.TB, hand string) cards.HandRank {
+	t.Helper()
+
+	parsed, err := cards.ParseCards(hand)
+	if err != nil {
+		t.Fatalf("could not parse hand %q, %v", hand, err)
+	}
+
+	rank, err := cards.Evaluate(parsed...)
+	if err != nil {
+		t.Fatalf("could not evaluate hand %q, %v", hand, err)
+	}
+	return rank
+}
+
+// ranks written as the ranks of cards in spades e.g. "A K 7"
+func mustParseRanks(t testing.TB, ranks string) []cards.Rank {
+	t.Helper()
+
+	var parsed []cards.Rank
+	for _, card := range cards.MustParseCards(suited(ranks)) {
+		parsed = append(parsed, card.Rank)
+	}
+	return parsed
+}
+
+func suited(ranks string) string {
+	out := []byte{}
+	for i := 0; i < len(ranks); i++ {
+		out = append(out, ranks[i])
+		if ranks[i] != ' ' {
+			out = append(out, 's')
+		}
+	}
+	return string(out)
+}
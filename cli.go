@@ -2,6 +2,8 @@ package poker
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -9,15 +11,43 @@ import (
 )
 
 const (
-	PlayerPrompt             = "Please enter the number of players: "
-	InvalidPlayerErrorPrompt = "Invalid input for the number of players... Try again."
+	PlayerPrompt             = "Please enter the players, separated by commas: "
+	StackPrompt              = "Please enter the starting stack (leave empty for 10000): "
+	BlindsPrompt             = "Please enter the blind structure (leave empty for " + DefaultBlindStructure + "): "
+	LeaguePrompt             = "Please enter the league (leave empty for " + DefaultLeagueID + "): "
+	InvalidPlayerErrorPrompt = "Invalid players... Try again."
+	InvalidStackErrorPrompt  = "Invalid starting stack... Try again."
+	InvalidBlindsErrorPrompt = "Invalid blind structure... Try again."
+	InvalidLeagueErrorPrompt = "Invalid league... Try again."
 	InvalidWinnerErrorPrompt = "Invalid input for the winner of the game... Try again."
+	RecordWinErrorPrompt     = "Could not record the win... Try again."
+	RestoredGamePrompt       = "Carrying on with the game that was being played.\n"
+	EliminatedPrompt         = "%s is out in position %d.\n"
+	WonPrompt                = "%s wins the game.\n"
+	EntryPrompt              = "%s is in, the prize pool is %d.\n"
+)
+
+// commands that control the blind clock while the game is running
+const (
+	PauseCommand     = "pause"
+	ResumeCommand    = "resume"
+	SkipLevelCommand = "skip"
+)
+
+// commands that follow the name of a player buying an entry while the game is running
+const (
+	RebuyCommand    = "rebuys"
+	AddOnCommand    = "addon"
+	RegisterCommand = "joins"
 )
 
 type CLI struct {
 	input  *bufio.Scanner
 	output io.Writer
 	game   Game
+	// the buy-in and entry rules of the games started
+	buyIn   int
+	entries EntryRules
 }
 
 func NewCLI(input io.Reader, output io.Writer, game Game) *CLI {
@@ -28,32 +58,187 @@ func NewCLI(input io.Reader, output io.Writer, game Game) *CLI {
 	}
 }
 
+// WithEntries has the games started cost buyIn for each entry and take entries after the start by the rules
+func (c *CLI) WithEntries(buyIn int, rules EntryRules) *CLI {
+	c.buyIn = buyIn
+	c.entries = rules
+	return c
+}
+
 func (c *CLI) PlayPoker() error {
-	fmt.Fprint(c.output, PlayerPrompt)
-	numPlayersInput, err := strconv.Atoi(c.readLine())
+	ctx := context.Background()
+
+	// a game that was running when the cli stopped carries on instead of starting a new one
+	restored, err := c.game.Restore(ctx, c.output)
 	if err != nil {
+		fmt.Fprintln(c.output, err)
+	}
+	if restored {
+		fmt.Fprint(c.output, RestoredGamePrompt)
+		return c.playGame(ctx)
+	}
+
+	fmt.Fprint(c.output, PlayerPrompt)
+	players, _ := c.readLine()
+	settings := GameSettings{Players: Roster(extractPlayers(players)...)}
+	if err := settings.Validate(); err != nil {
 		fmt.Fprint(c.output, InvalidPlayerErrorPrompt)
 		return err
 	}
 
-	c.game.Start(numPlayersInput, c.output)
+	fmt.Fprint(c.output, StackPrompt)
+	stackInput, _ := c.readLine()
+	if stackInput = strings.TrimSpace(stackInput); stackInput != "" {
+		stack, err := strconv.Atoi(stackInput)
+		if err != nil || stack <= 0 {
+			fmt.Fprint(c.output, InvalidStackErrorPrompt)
+			return fmt.Errorf("%w, invalid starting stack %q", ErrInvalidRoster, stackInput)
+		}
+		for i := range settings.Players {
+			settings.Players[i].Stack = stack
+		}
+	}
+
+	fmt.Fprint(c.output, BlindsPrompt)
+	blinds, _ := c.readLine()
+	settings.BlindStructure = strings.TrimSpace(blinds)
+
+	fmt.Fprint(c.output, LeaguePrompt)
+	league, _ := c.readLine()
+	settings.League = strings.TrimSpace(league)
+	settings.BuyIn, settings.Entries = c.buyIn, c.entries
+
+	if err := c.game.Start(ctx, settings, c.output); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidRoster):
+			fmt.Fprint(c.output, InvalidPlayerErrorPrompt)
+		case errors.Is(err, ErrUnknownBlindStructure):
+			fmt.Fprint(c.output, InvalidBlindsErrorPrompt)
+		case errors.Is(err, ErrLeagueNotFound), errors.Is(err, ErrLeagueArchived):
+			fmt.Fprint(c.output, InvalidLeagueErrorPrompt)
+		default:
+			fmt.Fprintln(c.output, err)
+		}
+		return err
+	}
+
+	return c.playGame(ctx)
+}
+
+// playGame follows the game until it is over or the input runs out, the game carries on with bad input
+func (c *CLI) playGame(ctx context.Context) error {
+	for {
+		input, ok := c.readLine()
+		if !ok {
+			return nil
+		}
+
+		if command, ok := c.clockCommand(input); ok {
+			if err := command(); err != nil {
+				fmt.Fprintln(c.output, err)
+			}
+			continue
+		}
+
+		playerName, command := extractCommand(input)
+		if playerName != "" && command == "out" {
+			if over := c.eliminate(ctx, playerName); over {
+				return nil
+			}
+			continue
+		}
+		if playerName != "" && c.entry(ctx, playerName, command) {
+			continue
+		}
+		if playerName == "" || command != "wins" {
+			fmt.Fprint(c.output, InvalidWinnerErrorPrompt)
+			continue
+		}
+		if err := c.game.Finish(ctx, playerName); err != nil {
+			fmt.Fprintln(c.output, err)
+			fmt.Fprintln(c.output, RecordWinErrorPrompt)
+			continue
+		}
 
-	input := c.readLine()
-	processedInput := strings.Split(input, " ")
-	if len(processedInput) != 2 || processedInput[1] != "wins" {
-		fmt.Fprint(c.output, InvalidWinnerErrorPrompt)
 		return nil
 	}
-	c.game.Finish(extractWinner(input))
+}
 
-	return nil
+// eliminate knocks the player out, over is true once that left a winner
+func (c *CLI) eliminate(ctx context.Context, playerName string) (over bool) {
+	finisher, winner, err := c.game.Eliminate(ctx, playerName)
+	if err != nil {
+		fmt.Fprintln(c.output, err)
+		return false
+	}
+	fmt.Fprintf(c.output, EliminatedPrompt, finisher.Name, finisher.Position)
+	if winner == "" {
+		return false
+	}
+	fmt.Fprintf(c.output, WonPrompt, winner)
+	return true
 }
 
-func extractWinner(input string) string {
-	return strings.Split(input, " ")[0]
+// entry buys the player the entry of the command, ok is false for input that isn't an entry command
+func (c *CLI) entry(ctx context.Context, playerName, command string) (ok bool) {
+	var err error
+	switch command {
+	case RebuyCommand:
+		err = c.game.Rebuy(ctx, playerName)
+	case AddOnCommand:
+		err = c.game.AddOn(ctx, playerName)
+	case RegisterCommand:
+		err = c.game.Register(ctx, Entrant{Name: playerName})
+	default:
+		return false
+	}
+	if err != nil {
+		fmt.Fprintln(c.output, err)
+		return true
+	}
+	if status, err := c.game.Status(); err == nil {
+		fmt.Fprintf(c.output, EntryPrompt, playerName, status.PrizePool)
+	}
+	return true
 }
 
-func (c *CLI) readLine() string {
-	c.input.Scan()
-	return c.input.Text()
+func (c *CLI) clockCommand(input string) (func() error, bool) {
+	switch strings.TrimSpace(input) {
+	case PauseCommand:
+		return c.game.Pause, true
+	case ResumeCommand:
+		return c.game.Resume, true
+	case SkipLevelCommand:
+		return c.game.SkipLevel, true
+	}
+	return nil, false
+}
+
+// the names of a comma separated list of players, blanks are left out
+func extractPlayers(input string) []string {
+	var players []string
+	for _, name := range strings.Split(input, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			players = append(players, name)
+		}
+	}
+	return players
+}
+
+// extractCommand splits "<player name> <command>" on the last space, so names can have spaces in them
+func extractCommand(input string) (playerName, command string) {
+	input = strings.TrimSpace(input)
+	i := strings.LastIndex(input, " ")
+	if i < 0 {
+		return "", input
+	}
+	return strings.TrimSpace(input[:i]), input[i+1:]
+}
+
+// readLine is false once the input is over
+func (c *CLI) readLine() (string, bool) {
+	if !c.input.Scan() {
+		return "", false
+	}
+	return c.input.Text(), true
 }
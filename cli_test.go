@@ -2,7 +2,12 @@ package poker_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -11,22 +16,157 @@ import (
 )
 
 type SpyGame struct {
-	StartCalled bool
-	StartedWith int
-	BlindAlert  []byte
+	StartCalled       bool
+	StartedWith       int
+	StartedWithRoster []poker.Entrant
+	StartedWithBlinds string
+	StartedInLeague   string
+	StartedAdaptive   bool
+	StartedWithBuyIn  int
+	StartedWithRules  poker.EntryRules
+	BlindAlert        []byte
+	StartError        error
+
+	ClockCommands []string
+	ClockError    error
 
 	FinishedCalled bool
 	FinishedWith   string
+	// returned by the next call to Finish only, so the winner can be sent again
+	FinishError error
+
+	EliminatedPlayers []string
+	EliminateError    error
+
+	// the players that bought each entry, a rebuy puts a player knocked out back in
+	Rebuys     []string
+	AddOns     []string
+	Registered []string
+	EntryError error
+
+	// returned by Status once the game started, with the players remaining worked out from the eliminations
+	GameStatus poker.GameStatus
+
+	// a game running before a restart, Restore carries on with it like Start was called
+	SavedGame    bool
+	RestoreError error
+	RestoredTo   io.Writer
 }
 
-func (g *SpyGame) Start(numPlayers int, alertsDestination io.Writer) {
+func (g *SpyGame) Start(ctx context.Context, settings poker.GameSettings, alertsDestination io.Writer) error {
+	if g.StartError != nil {
+		return g.StartError
+	}
 	g.StartCalled = true
-	g.StartedWith = numPlayers
+	g.StartedWith = len(settings.Players)
+	g.StartedWithRoster = settings.Players
+	g.StartedWithBlinds = settings.BlindStructure
+	g.StartedInLeague = settings.League
+	g.StartedAdaptive = settings.AdaptiveLevels
+	g.StartedWithBuyIn = settings.BuyIn
+	g.StartedWithRules = settings.Entries
 	alertsDestination.Write(g.BlindAlert)
+	return nil
+}
+
+func (g *SpyGame) Restore(ctx context.Context, alertsDestination io.Writer) (bool, error) {
+	if g.RestoreError != nil {
+		return false, g.RestoreError
+	}
+	if !g.SavedGame {
+		return false, nil
+	}
+	g.StartCalled = true
+	g.RestoredTo = alertsDestination
+	return true, nil
+}
+
+func (g *SpyGame) Pause() error {
+	g.ClockCommands = append(g.ClockCommands, "pause")
+	return g.ClockError
+}
+
+func (g *SpyGame) Resume() error {
+	g.ClockCommands = append(g.ClockCommands, "resume")
+	return g.ClockError
 }
 
-func (g *SpyGame) Finish(winner string) {
+func (g *SpyGame) SkipLevel() error {
+	g.ClockCommands = append(g.ClockCommands, "skip")
+	return g.ClockError
+}
+
+func (g *SpyGame) Finish(ctx context.Context, winner string) error {
+	if err := g.FinishError; err != nil {
+		g.FinishError = nil
+		return err
+	}
+	g.FinishedCalled = true
 	g.FinishedWith = winner
+	return nil
+}
+
+// Eliminate finishes the game with the last player of the roster left as the winner
+func (g *SpyGame) Eliminate(ctx context.Context, playerName string) (poker.Finisher, string, error) {
+	if g.EliminateError != nil {
+		return poker.Finisher{}, "", g.EliminateError
+	}
+	position := g.StartedWith - len(g.EliminatedPlayers)
+	g.EliminatedPlayers = append(g.EliminatedPlayers, playerName)
+	finisher := poker.Finisher{Name: playerName, Position: position}
+
+	var playing []string
+	for _, player := range g.StartedWithRoster {
+		if !slices.Contains(g.EliminatedPlayers, player.Name) {
+			playing = append(playing, player.Name)
+		}
+	}
+	if len(g.StartedWithRoster) == 0 || len(playing) != 1 {
+		return finisher, "", nil
+	}
+	g.FinishedCalled = true
+	g.FinishedWith = playing[0]
+	return finisher, playing[0], nil
+}
+
+func (g *SpyGame) Rebuy(ctx context.Context, playerName string) error {
+	if g.EntryError != nil {
+		return g.EntryError
+	}
+	g.Rebuys = append(g.Rebuys, playerName)
+	g.EliminatedPlayers = slices.DeleteFunc(g.EliminatedPlayers, func(name string) bool { return name == playerName })
+	return nil
+}
+
+func (g *SpyGame) AddOn(ctx context.Context, playerName string) error {
+	if g.EntryError != nil {
+		return g.EntryError
+	}
+	g.AddOns = append(g.AddOns, playerName)
+	return nil
+}
+
+func (g *SpyGame) Register(ctx context.Context, player poker.Entrant) error {
+	if g.EntryError != nil {
+		return g.EntryError
+	}
+	g.Registered = append(g.Registered, player.Name)
+	g.StartedWith++
+	g.StartedWithRoster = append(g.StartedWithRoster, player)
+	return nil
+}
+
+func (g *SpyGame) Status() (poker.GameStatus, error) {
+	if !g.StartCalled {
+		return poker.GameStatus{}, poker.ErrGameNotStarted
+	}
+	status := g.GameStatus
+	status.PlayersRemaining = g.StartedWith - len(g.EliminatedPlayers)
+	return status, nil
+}
+
+func (g *SpyGame) BlindStructures() []string {
+	return []string{"standard", "turbo"}
 }
 
 func TestCLI(t *testing.T) {
@@ -35,13 +175,15 @@ func TestCLI(t *testing.T) {
 		game := &SpyGame{}
 		stdout := &bytes.Buffer{}
 
-		input := strings.NewReader("3\nAndre wins\n")
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nAndre wins\n")
 
 		cli := poker.NewCLI(input, stdout, game)
 		cli.PlayPoker()
 
-		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt)
 		assertStartCalledWith(t, game, 3)
+		assertStartCalledWithBlinds(t, game, "")
+		assertStartCalledInLeague(t, game, "")
 		assertFinishCalledWith(t, game, "Andre")
 	})
 
@@ -49,17 +191,130 @@ func TestCLI(t *testing.T) {
 		game := &SpyGame{}
 		stdout := &bytes.Buffer{}
 
-		input := strings.NewReader("8\nChris wins\n")
+		input := strings.NewReader("Andre, Chris, John, Mary, Paul, Rita, Sam, Tom\n5000\nturbo\nfriday\nChris wins\n")
 
 		cli := poker.NewCLI(input, stdout, game)
 		cli.PlayPoker()
 
-		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt)
 		assertStartCalledWith(t, game, 8)
+		assertStartCalledWithBlinds(t, game, "turbo")
+		assertStartCalledInLeague(t, game, "friday")
+		assertFinishCalledWith(t, game, "Chris")
+	})
+
+	t.Run("start every player with the stack entered", func(t *testing.T) {
+		game := &SpyGame{}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre,Chris\n5000\n\n\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		want := []poker.Entrant{{Name: "Andre", Stack: 5000}, {Name: "Chris", Stack: 5000}}
+		if !reflect.DeepEqual(game.StartedWithRoster, want) {
+			t.Errorf("wanted Start called with roster %v, got %v", want, game.StartedWithRoster)
+		}
+	})
+
+	t.Run("print error on a bad starting stack", func(t *testing.T) {
+		game := &SpyGame{}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris\nlots\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		err := cli.PlayPoker()
+
+		if !errors.Is(err, poker.ErrInvalidRoster) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidRoster)
+		}
+		assertGameNotStarted(t, game)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.InvalidStackErrorPrompt)
+	})
+
+	t.Run("print error on the same player entered twice", func(t *testing.T) {
+		input := strings.NewReader("Andre, Andre\n")
+		stdout := &bytes.Buffer{}
+
+		game := &SpyGame{}
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertGameNotStarted(t, game)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.InvalidPlayerErrorPrompt)
+	})
+
+	t.Run("knock players out until one is left and wins", func(t *testing.T) {
+		game := &SpyGame{}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nJohn out\nAndre out\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt,
+			fmt.Sprintf(poker.EliminatedPrompt, "John", 3),
+			fmt.Sprintf(poker.EliminatedPrompt, "Andre", 2),
+			fmt.Sprintf(poker.WonPrompt, "Chris"),
+		)
 		assertFinishCalledWith(t, game, "Chris")
 	})
 
-	t.Run("print error on bad number of players input", func(t *testing.T) {
+	t.Run("players buy entries during the game with the rules of the cli", func(t *testing.T) {
+		game := &SpyGame{GameStatus: poker.GameStatus{PrizePool: 40}}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nJohn out\nJohn rebuys\nAndre addon\nMary joins\nAndre wins\n")
+
+		rules := poker.EntryRules{RebuyLevels: 3, AddOn: true, LateRegistrationLevels: 2}
+		cli := poker.NewCLI(input, stdout, game).WithEntries(10, rules)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt,
+			fmt.Sprintf(poker.EliminatedPrompt, "John", 3),
+			fmt.Sprintf(poker.EntryPrompt, "John", 40),
+			fmt.Sprintf(poker.EntryPrompt, "Andre", 40),
+			fmt.Sprintf(poker.EntryPrompt, "Mary", 40),
+		)
+		if game.StartedWithBuyIn != 10 || game.StartedWithRules != rules {
+			t.Errorf("got game started with a buy-in of %d and rules %+v", game.StartedWithBuyIn, game.StartedWithRules)
+		}
+		if !slices.Equal(game.Rebuys, []string{"John"}) || !slices.Equal(game.AddOns, []string{"Andre"}) || !slices.Equal(game.Registered, []string{"Mary"}) {
+			t.Errorf("got rebuys %v, add-ons %v and registered %v", game.Rebuys, game.AddOns, game.Registered)
+		}
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("print entry errors and carry on", func(t *testing.T) {
+		game := &SpyGame{EntryError: poker.ErrEntriesClosed}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nMary joins\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt, poker.ErrEntriesClosed.Error()+"\n")
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("print elimination errors and carry on", func(t *testing.T) {
+		game := &SpyGame{EliminateError: poker.ErrInvalidElimination}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nMary out\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt, poker.ErrInvalidElimination.Error()+"\n")
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("print error on fewer than 2 players", func(t *testing.T) {
 		input := strings.NewReader("abc\n")
 		stdout := &bytes.Buffer{}
 
@@ -75,12 +330,160 @@ func TestCLI(t *testing.T) {
 		game := &SpyGame{}
 		stdout := &bytes.Buffer{}
 
-		input := strings.NewReader("3\nNot a good input")
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nNot a good input")
 
 		cli := poker.NewCLI(input, stdout, game)
 		cli.PlayPoker()
 
-		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.InvalidWinnerErrorPrompt)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt, poker.InvalidWinnerErrorPrompt)
+	})
+
+	t.Run("print error on bad input and carry on with the game", func(t *testing.T) {
+		game := &SpyGame{}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nAndre\n\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt,
+			poker.InvalidWinnerErrorPrompt, poker.InvalidWinnerErrorPrompt)
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("players can have spaces in their names", func(t *testing.T) {
+		game := &SpyGame{}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre Silva, Chris, John Smith\n\n\n\nJohn Smith out\nAndre Silva wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt,
+			fmt.Sprintf(poker.EliminatedPrompt, "John Smith", 3))
+		assertFinishCalledWith(t, game, "Andre Silva")
+	})
+
+	t.Run("stop without finishing the game when the input is over", func(t *testing.T) {
+		game := &SpyGame{}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\npause\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		if err := cli.PlayPoker(); err != nil {
+			t.Fatalf("didn't expect an error but got one, %v", err)
+		}
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt)
+		if game.FinishedCalled {
+			t.Error("the game was finished with no winner entered")
+		}
+	})
+
+	t.Run("control the blind clock before declaring the winner", func(t *testing.T) {
+		game := &SpyGame{}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\npause\nresume\nskip\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt)
+		assertClockCommands(t, game, "pause", "resume", "skip")
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("print clock errors and carry on", func(t *testing.T) {
+		game := &SpyGame{ClockError: poker.ErrClockNotPaused}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nresume\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt, poker.ErrClockNotPaused.Error()+"\n")
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("print error when the win can't be recorded and ask again", func(t *testing.T) {
+		game := &SpyGame{FinishError: errors.New("disk full")}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nAndre wins\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt, "disk full\n", poker.RecordWinErrorPrompt+"\n")
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("print error on unknown blind structure", func(t *testing.T) {
+		game := &SpyGame{StartError: fmt.Errorf("%w %q", poker.ErrUnknownBlindStructure, "hyper")}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\nhyper\n\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		err := cli.PlayPoker()
+
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+		assertGameNotStarted(t, game)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt, poker.InvalidBlindsErrorPrompt)
+	})
+
+	t.Run("print error on unknown league", func(t *testing.T) {
+		game := &SpyGame{StartError: fmt.Errorf("%w: %s", poker.ErrLeagueNotFound, "nowhere")}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\nnowhere\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		err := cli.PlayPoker()
+
+		if !errors.Is(err, poker.ErrLeagueNotFound) {
+			t.Errorf("got error %v, want %v", err, poker.ErrLeagueNotFound)
+		}
+		assertGameNotStarted(t, game)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt, poker.InvalidLeagueErrorPrompt)
+	})
+
+	t.Run("carry on with the game that was running without asking for a new one", func(t *testing.T) {
+		game := &SpyGame{SavedGame: true}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("pause\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.RestoredGamePrompt)
+		if game.RestoredTo != stdout {
+			t.Error("the blind alerts of the restored game don't go to the cli")
+		}
+		assertClockCommands(t, game, "pause")
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("print error when the game can't be restored and start a new one", func(t *testing.T) {
+		game := &SpyGame{RestoreError: poker.ErrInvalidActiveGame}
+		stdout := &bytes.Buffer{}
+
+		input := strings.NewReader("Andre, Chris, John\n\n\n\nAndre wins\n")
+
+		cli := poker.NewCLI(input, stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.ErrInvalidActiveGame.Error()+"\n", poker.PlayerPrompt, poker.StackPrompt, poker.BlindsPrompt, poker.LeaguePrompt)
+		assertStartCalledWith(t, game, 3)
+		assertFinishCalledWith(t, game, "Andre")
 	})
 }
 
@@ -107,6 +510,30 @@ func assertStartCalledWith(t testing.TB, game *SpyGame, want int) {
 	}
 }
 
+func assertStartCalledWithBlinds(t testing.TB, game *SpyGame, want string) {
+	t.Helper()
+
+	if game.StartedWithBlinds != want {
+		t.Errorf("wanted Start called with blind structure %q, got %q", want, game.StartedWithBlinds)
+	}
+}
+
+func assertStartCalledInLeague(t testing.TB, game *SpyGame, want string) {
+	t.Helper()
+
+	if game.StartedInLeague != want {
+		t.Errorf("wanted Start called in league %q, got %q", want, game.StartedInLeague)
+	}
+}
+
+func assertClockCommands(t testing.TB, game *SpyGame, want ...string) {
+	t.Helper()
+
+	if !reflect.DeepEqual(game.ClockCommands, want) {
+		t.Errorf("wanted clock commands %v, got %v", want, game.ClockCommands)
+	}
+}
+
 func assertFinishCalledWith(t testing.TB, game *SpyGame, want string) {
 	t.Helper()
 
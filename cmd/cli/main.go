@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/andremfp/poker-app"
 )
@@ -11,14 +14,67 @@ import (
 const dbFileName = "game.db.json"
 
 func main() {
-	store, close, err := poker.FsPlayerStoreFromFile(dbFileName)
+	storeSpec := flag.String("store", "json:"+dbFileName, "where to keep the leagues, json:path, sqlite:path or events:path")
+	blindsFile := flag.String("blinds", "", "path to a .json or .yaml blind structure file to add to the presets")
+	seasonLength := flag.String("seasons", string(poker.DefaultSeasonLength), "how long a season lasts, monthly, quarterly or yearly")
+	tableSize := flag.Int("table-size", 0, "seats at a table, players are seated and moved over as many tables as they need, 0 plays at a single table")
+	buyIn := flag.Int("buy-in", 0, "what each entry costs, the prize pool and the profit of players are worked out from it")
+	var entries poker.EntryRules
+	flag.IntVar(&entries.RebuyLevels, "rebuy-levels", 0, "players can rebuy until the end of this blind level, 0 for no rebuys")
+	flag.IntVar(&entries.MaxRebuys, "max-rebuys", 0, "rebuys each player can make, 0 for any number")
+	flag.BoolVar(&entries.AddOn, "add-on", false, "players still in can buy an add-on at the first break")
+	flag.IntVar(&entries.AddOnCost, "add-on-cost", 0, "what an add-on costs, the buy-in when 0")
+	flag.IntVar(&entries.LateRegistrationLevels, "late-registration-levels", 0, "players can join until the end of this blind level, 0 for no late registration")
+	flag.Parse()
+
+	seasons, err := poker.NewSeasonCalendar(*seasonLength)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	blinds := poker.BlindPresets()
+	if *blindsFile != "" {
+		structure, err := poker.LoadBlindStructure(*blindsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := blinds.Add(structure); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	store, close, err := poker.OpenPlayerStore(*storeSpec)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer close()
 
+	leagues, err := store.GetLeagues(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	var open []string
+	for _, league := range leagues {
+		if !league.Archived {
+			open = append(open, league.ID)
+		}
+	}
+
 	fmt.Println("Let's play poker")
+	fmt.Printf("Blind structures available: %s\n", strings.Join(blinds.Names(), ", "))
+	fmt.Printf("Leagues available: %s\n", strings.Join(open, ", "))
+	fmt.Printf("Season: %s\n", seasons.Current())
+	fmt.Println("Type 'pause', 'resume' or 'skip' to control the blind clock")
+	fmt.Println("Type '{Name} out' when a player is knocked out, the last one left wins")
 	fmt.Println("Type '{Name} wins' to record a win")
-	game := poker.NewTexasHoldem(store, poker.BlindAlerterFunc(poker.Alerter))
-	poker.NewCLI(os.Stdin, os.Stdout, game).PlayPoker()
+	fmt.Println("Type '{Name} rebuys', '{Name} addon' or '{Name} joins' when a player buys an entry")
+	var game poker.Game = poker.NewTexasHoldem(store, poker.BlindAlerterFunc(poker.Alerter), blinds)
+	if *tableSize > 0 {
+		tournament, err := poker.NewTournament(game, *tableSize, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game = tournament
+	}
+	poker.NewCLI(os.Stdin, os.Stdout, game).WithEntries(*buyIn, entries).PlayPoker()
 }
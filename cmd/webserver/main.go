@@ -1,8 +1,14 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/andremfp/poker-app"
 )
@@ -10,18 +16,95 @@ import (
 const dbFileName = "game.db.json"
 
 func main() {
-	store, close, err := poker.FsPlayerStoreFromFile(dbFileName)
+	storeSpec := flag.String("store", "json:"+dbFileName, "where to keep the leagues, json:path, sqlite:path or events:path")
+	blindsFile := flag.String("blinds", "", "path to a .json or .yaml blind structure file to add to the presets")
+	seasonLength := flag.String("seasons", string(poker.DefaultSeasonLength), "how long a season lasts, monthly, quarterly or yearly")
+	ratingK := flag.Float64("rating-k", poker.DefaultRatingK, "how far a single game moves a player rating")
+	initialRating := flag.Float64("rating-initial", poker.DefaultInitialRating, "the rating of a player before their first game")
+	usersFile := flag.String("users", "", "path to a .json or .yaml users file, without one anyone can record results")
+	sessionLength := flag.Duration("session-length", poker.DefaultSessionLength, "how long a login lasts")
+	hashPassword := flag.Bool("hash-password", false, "hash a password read from stdin for the users file and exit")
+	newToken := flag.Bool("new-token", false, "print a new api token and its hash for the users file and exit")
+	tableSize := flag.Int("table-size", 0, "seats at a table, players are seated and moved over as many tables as they need, 0 plays at a single table")
+	flag.Parse()
+
+	if *hashPassword {
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			log.Fatalf("could not read password, %v", err)
+		}
+		hash, err := poker.HashPassword(strings.TrimRight(password, "\r\n"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(hash)
+		return
+	}
+	if *newToken {
+		token, err := poker.NewToken()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("token: %s\nhash:  %s\n", token, poker.HashToken(token))
+		return
+	}
+
+	var auth *poker.Authenticator
+	if *usersFile != "" {
+		users, err := poker.LoadUsers(*usersFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		auth, err = poker.NewAuthenticator(users, *sessionLength)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		log.Println("no -users file, anyone can start games and record results")
+	}
+
+	seasons, err := poker.NewSeasonCalendar(*seasonLength)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	blinds := poker.BlindPresets()
+	if *blindsFile != "" {
+		structure, err := poker.LoadBlindStructure(*blindsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := blinds.Add(structure); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	store, close, err := poker.OpenPlayerStore(*storeSpec)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer close()
 
-	game := poker.NewTexasHoldem(store, poker.BlindAlerterFunc(poker.Alerter))
+	var game poker.Game = poker.NewTexasHoldem(store, poker.BlindAlerterFunc(poker.Alerter), blinds)
+	if *tableSize > 0 {
+		tournament, err := poker.NewTournament(game, *tableSize, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game = tournament
+	}
 
-	server, err := poker.NewPlayerServer(store, game)
+	server, err := poker.NewPlayerServer(store, game, seasons, poker.RatingParams{Initial: *initialRating, K: *ratingK}, auth)
 	if err != nil {
 		log.Fatal("problem creating player server", err)
 	}
+	restored, err := server.RestoreGame(context.Background())
+	if err != nil {
+		log.Printf("could not carry on with the game being played, %v", err)
+	}
+	if restored {
+		log.Println("carrying on with the game being played, the host can rejoin it at /game")
+	}
 
 	if err := http.ListenAndServe(":5000", server); err != nil {
 		log.Fatalf("could not listen on port 5000, %v", err)
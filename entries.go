@@ -0,0 +1,104 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrEntriesClosed = errors.New("entries closed")
+	ErrInvalidEntry  = errors.New("invalid entry")
+)
+
+// notices of the blind clock for the entries that open and close with a level
+const (
+	RebuysClosedNotice           = "Rebuys are closed"
+	LateRegistrationClosedNotice = "Late registration is closed"
+	AddOnsOpenNotice             = "Add-ons are open"
+)
+
+// EntryRules are the ways into a game besides buying in at the start. Blind levels are counted from 1 leaving out
+// the breaks, entries open until the end of a level close when the level or break after it starts.
+type EntryRules struct {
+	// players can buy in again until the end of this level, no rebuys when 0
+	RebuyLevels int `json:",omitempty"`
+	// rebuys each player can make, any number when 0
+	MaxRebuys int `json:",omitempty"`
+	// players still in can buy an add-on once during the first break
+	AddOn bool `json:",omitempty"`
+	// what an add-on costs, the buy-in when 0
+	AddOnCost int `json:",omitempty"`
+	// new players can join until the end of this level, no late registration when 0
+	LateRegistrationLevels int `json:",omitempty"`
+}
+
+func (r EntryRules) Validate() error {
+	if r.RebuyLevels < 0 || r.MaxRebuys < 0 || r.LateRegistrationLevels < 0 || r.AddOnCost < 0 {
+		return fmt.Errorf("%w, entry levels, max rebuys and the add-on cost can't be negative, got %+v", ErrInvalidEntry, r)
+	}
+	if r.MaxRebuys > 0 && r.RebuyLevels == 0 {
+		return fmt.Errorf("%w, max rebuys without rebuy levels", ErrInvalidEntry)
+	}
+	if r.AddOnCost > 0 && !r.AddOn {
+		return fmt.Errorf("%w, an add-on cost without add-ons", ErrInvalidEntry)
+	}
+	return nil
+}
+
+// withNotices has the levels of the structure that open and close entries say so when they start. Add-ons need a
+// break to be taken at.
+func (r EntryRules) withNotices(structure BlindStructure) (BlindStructure, error) {
+	levels := append([]BlindLevel(nil), structure.Levels...)
+	notify := func(index int, notice string) {
+		if index >= len(levels) {
+			return
+		}
+		levels[index].Notice = strings.TrimSpace(levels[index].Notice + " " + notice + ".")
+	}
+
+	if r.RebuyLevels > 0 {
+		notify(endOfLevel(levels, r.RebuyLevels)+1, RebuysClosedNotice)
+	}
+	if r.LateRegistrationLevels > 0 {
+		notify(endOfLevel(levels, r.LateRegistrationLevels)+1, LateRegistrationClosedNotice)
+	}
+	if r.AddOn {
+		index := firstBreak(levels)
+		if index < 0 {
+			return BlindStructure{}, fmt.Errorf("%w, add-ons are taken at the first break and %s has none", ErrInvalidEntry, structure.Name)
+		}
+		notify(index, AddOnsOpenNotice)
+	}
+
+	structure.Levels = levels
+	return structure, nil
+}
+
+// entriesOpen tells if entries open until the end of level are still open at the level of the structure at index
+func entriesOpen(levels []BlindLevel, level, index int) bool {
+	return level > 0 && index <= endOfLevel(levels, level)
+}
+
+// index of the blind level numbered from 1 leaving out the breaks, the last level of shorter structures
+func endOfLevel(levels []BlindLevel, number int) int {
+	for i, level := range levels {
+		if level.Break {
+			continue
+		}
+		if number--; number == 0 {
+			return i
+		}
+	}
+	return len(levels) - 1
+}
+
+// index of the first break, -1 without breaks
+func firstBreak(levels []BlindLevel) int {
+	for i, level := range levels {
+		if level.Break {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,508 @@
+package poker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// how many events are appended between snapshots by default
+const DefaultSnapshotEvery = 100
+
+// types of event in the log
+const (
+	ResultRecordedEvent = "result_recorded"
+	LeagueCreatedEvent  = "league_created"
+	LeagueArchivedEvent = "league_archived"
+	// the game being played was saved, without a game once it is over. Only in logs from before the game being
+	// played had its own file, it is read from them but no longer logged.
+	GameSavedEvent = "game_saved"
+)
+
+// StoreEvent is a line of the event log, the log is never rewritten so it doubles as an audit trail
+type StoreEvent struct {
+	Seq    int
+	Time   time.Time
+	Type   string
+	Result *GameResult `json:",omitempty"`
+	League *LeagueInfo `json:",omitempty"`
+	Game   *ActiveGame `json:",omitempty"`
+}
+
+// eventLogSnapshot is the state after applying every event up to Seq
+type eventLogSnapshot struct {
+	Seq     int
+	Leagues []LeagueInfo
+	Results []GameResult
+	// only in snapshots from before the game being played had its own file
+	ActiveGame *ActiveGame `json:",omitempty"`
+}
+
+// EventLogPlayerStore appends every change to a json lines log instead of rewriting the league.
+// The state is rebuilt on startup from the latest snapshot plus the events logged after it.
+type EventLogPlayerStore struct {
+	lock          sync.RWMutex
+	log           *os.File
+	snapshot      *tape
+	snapshotEvery int
+	// the game being played is saved on every change of the clock, it is kept in its own file so it doesn't fill
+	// the log and the replay on startup
+	activeGame *tape
+
+	seq         int
+	snapshotSeq int
+	leagues     leagueSet
+	results     []GameResult
+	active      *ActiveGame
+	standings   map[string]League
+}
+
+func NewEventLogPlayerStore(path string, snapshotEvery int) (*EventLogPlayerStore, error) {
+	logFile, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("problem opening %s %v", path, err)
+	}
+
+	store := &EventLogPlayerStore{
+		log:           logFile,
+		snapshot:      &tape{path: path + ".snapshot"},
+		snapshotEvery: snapshotEvery,
+		activeGame:    &tape{path: path + ".active"},
+		leagues:       newLeagueSet(nil),
+	}
+
+	if err := store.load(); err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("could not load event log %s, %v", path, err)
+	}
+
+	return store, nil
+}
+
+func EventLogPlayerStoreFromFile(path string) (*EventLogPlayerStore, func(), error) {
+	store, err := NewEventLogPlayerStore(path, DefaultSnapshotEvery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem creating event log player store, %v", err)
+	}
+
+	closeFunc := func() {
+		store.Close()
+	}
+
+	return store, closeFunc, nil
+}
+
+func (s *EventLogPlayerStore) Close() error {
+	return s.log.Close()
+}
+
+func (s *EventLogPlayerStore) GetPlayerScore(ctx context.Context, leagueID, playerName string) (int, error) {
+	league, err := s.GetLeague(ctx, leagueID)
+	if err != nil {
+		return 0, err
+	}
+
+	player := league.Find(playerName)
+	if player != nil {
+		return player.Wins, nil
+	}
+	return 0, nil
+}
+
+// GetSeasonLeague keeps the standings of all time from the cache, the standings of a season are worked out from its
+// results
+func (s *EventLogPlayerStore) GetSeasonLeague(ctx context.Context, leagueID string, season Season) (League, error) {
+	if season.ID == AllSeasons {
+		return s.GetLeague(ctx, leagueID)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	league, err := s.leagues.get(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return NewLeagueFromResults(resultsInSeason(s.results, leagueID, season), league.ScoringRule()), nil
+}
+
+func (s *EventLogPlayerStore) GetLeagueResults(ctx context.Context, leagueID string, season Season) ([]GameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if _, err := s.leagues.get(leagueID); err != nil {
+		return nil, err
+	}
+	return resultsInSeason(s.results, leagueID, season), nil
+}
+
+func (s *EventLogPlayerStore) RecordWin(ctx context.Context, leagueID, playerName string) error {
+	return s.RecordResult(ctx, winResult(leagueID, playerName))
+}
+
+func (s *EventLogPlayerStore) GetLeague(ctx context.Context, leagueID string) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if _, err := s.leagues.get(leagueID); err != nil {
+		return nil, err
+	}
+	if league, ok := s.standings[leagueID]; ok {
+		return league, nil
+	}
+	return League{}, nil
+}
+
+func (s *EventLogPlayerStore) RecordResult(ctx context.Context, result GameResult) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result, err := newGameResult(result)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.leagues.checkOpen(result.League); err != nil {
+		return err
+	}
+	if err := checkNotRecorded(s.results, result.ID); err != nil {
+		return err
+	}
+	return s.append(StoreEvent{Type: ResultRecordedEvent, Result: &result})
+}
+
+func (s *EventLogPlayerStore) GetResults(ctx context.Context) ([]GameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]GameResult(nil), s.results...), nil
+}
+
+func (s *EventLogPlayerStore) GetPlayerResults(ctx context.Context, playerName string) ([]GameResult, error) {
+	results, err := s.GetResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ResultsForPlayer(results, playerName), nil
+}
+
+func (s *EventLogPlayerStore) CreateLeague(ctx context.Context, league LeagueInfo) (LeagueInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return LeagueInfo{}, err
+	}
+
+	league, err := newLeagueInfo(league)
+	if err != nil {
+		return LeagueInfo{}, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.leagues.add(league); err != nil {
+		return LeagueInfo{}, err
+	}
+	if err := s.append(StoreEvent{Type: LeagueCreatedEvent, League: &league}); err != nil {
+		return LeagueInfo{}, err
+	}
+	return league, nil
+}
+
+func (s *EventLogPlayerStore) GetLeagues(ctx context.Context) ([]LeagueInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]LeagueInfo(nil), s.leagues...), nil
+}
+
+func (s *EventLogPlayerStore) ArchiveLeague(ctx context.Context, leagueID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.leagues.archive(leagueID); err != nil {
+		return err
+	}
+	return s.append(StoreEvent{Type: LeagueArchivedEvent, League: &LeagueInfo{ID: leagueID}})
+}
+
+func (s *EventLogPlayerStore) SaveActiveGame(ctx context.Context, game *ActiveGame) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	game = game.copy()
+	if err := s.writeActiveGame(game); err != nil {
+		return fmt.Errorf("could not save the game being played, %v", err)
+	}
+	s.active = game
+	return nil
+}
+
+func (s *EventLogPlayerStore) GetActiveGame(ctx context.Context) (*ActiveGame, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.active.copy(), nil
+}
+
+// Events returns every event in the log, oldest first
+func (s *EventLogPlayerStore) Events() ([]StoreEvent, error) {
+	// reading seeks the shared log file and can cut off a torn last line, so readers take the write lock too
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var events []StoreEvent
+	err := s.readLog(func(event StoreEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+// Rebuild replays the whole log ignoring the snapshot, e.g. after bad data was fixed by editing the log,
+// and takes a new snapshot of the result
+func (s *EventLogPlayerStore) Rebuild() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.seq, s.snapshotSeq, s.leagues, s.results, s.active = 0, 0, newLeagueSet(nil), nil, nil
+	if err := s.replay(); err != nil {
+		return err
+	}
+	if err := s.loadActiveGame(); err != nil {
+		return err
+	}
+	return s.takeSnapshot()
+}
+
+// append logs the event and applies it, callers hold the write lock and have checked the event can be applied
+func (s *EventLogPlayerStore) append(event StoreEvent) error {
+	event.Seq = s.seq + 1
+	event.Time = time.Now().UTC()
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not encode event, %v", err)
+	}
+
+	// a single write per event so a crash can at most leave a torn last line
+	if _, err := s.log.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not append event to %s, %v", s.log.Name(), err)
+	}
+	if err := s.log.Sync(); err != nil {
+		return fmt.Errorf("could not sync %s, %v", s.log.Name(), err)
+	}
+
+	s.apply(event)
+	s.standings = standingsByLeague(s.leagues, s.results)
+
+	if s.snapshotEvery > 0 && s.seq-s.snapshotSeq >= s.snapshotEvery {
+		// the event is safely in the log, a failed snapshot only makes the next startup slower
+		if err := s.takeSnapshot(); err != nil {
+			log.Printf("could not take snapshot of %s, %v\n", s.log.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// updates the state with the event, the standings are left for the caller to rebuild.
+// Events logged before there were leagues have results with no league, they go to the default league.
+func (s *EventLogPlayerStore) apply(event StoreEvent) {
+	switch event.Type {
+	case ResultRecordedEvent:
+		if event.Result != nil {
+			s.results = append(s.results, withDefaultLeague([]GameResult{*event.Result})...)
+		}
+	case LeagueCreatedEvent:
+		if event.League != nil {
+			if leagues, err := s.leagues.add(*event.League); err == nil {
+				s.leagues = leagues
+			}
+		}
+	case LeagueArchivedEvent:
+		if event.League != nil {
+			if leagues, err := s.leagues.archive(event.League.ID); err == nil {
+				s.leagues = leagues
+			}
+		}
+	case GameSavedEvent:
+		s.active = event.Game
+	}
+	s.seq = event.Seq
+}
+
+// starts from the snapshot when there is a usable one and replays the events logged after it
+func (s *EventLogPlayerStore) load() error {
+	content, err := os.ReadFile(s.snapshot.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not read snapshot %s, %v", s.snapshot.path, err)
+	}
+
+	if len(content) > 0 {
+		var snapshot eventLogSnapshot
+		if err := json.Unmarshal(content, &snapshot); err != nil {
+			log.Printf("ignoring unreadable snapshot %s, replaying the whole log, %v\n", s.snapshot.path, err)
+		} else {
+			s.seq, s.snapshotSeq = snapshot.Seq, snapshot.Seq
+			s.leagues, s.results = newLeagueSet(snapshot.Leagues), withDefaultLeague(snapshot.Results)
+			s.active = snapshot.ActiveGame
+		}
+	}
+
+	if err := s.replay(); err != nil {
+		return err
+	}
+	return s.loadActiveGame()
+}
+
+// reads the game being played from its file. Logs from before it had its own file have it in their events, it is
+// moved to the file so it isn't lost once a snapshot is taken.
+func (s *EventLogPlayerStore) loadActiveGame() error {
+	content, err := os.ReadFile(s.activeGame.path)
+	if os.IsNotExist(err) {
+		if s.active == nil {
+			return nil
+		}
+		return s.writeActiveGame(s.active)
+	}
+	if err != nil {
+		return fmt.Errorf("could not read %s, %v", s.activeGame.path, err)
+	}
+
+	var game *ActiveGame
+	if err := json.Unmarshal(content, &game); err != nil {
+		return fmt.Errorf("could not parse %s, %v", s.activeGame.path, err)
+	}
+	s.active = game
+	return nil
+}
+
+// replaces the file of the game being played, it holds null once the game is over
+func (s *EventLogPlayerStore) writeActiveGame(game *ActiveGame) error {
+	content, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+	_, err = s.activeGame.Write(content)
+	return err
+}
+
+// applies the logged events newer than the current state
+func (s *EventLogPlayerStore) replay() error {
+	from := s.seq
+	err := s.readLog(func(event StoreEvent) error {
+		if event.Seq <= from {
+			return nil
+		}
+		// events can be removed from the log to fix bad data but never reordered
+		if event.Seq <= s.seq {
+			return fmt.Errorf("event %d is logged after event %d", event.Seq, s.seq)
+		}
+		s.apply(event)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.standings = standingsByLeague(s.leagues, s.results)
+	return nil
+}
+
+// calls apply for every event in the log. A torn last line left by a crash while appending is cut off the log.
+// Callers hold the write lock.
+func (s *EventLogPlayerStore) readLog(apply func(StoreEvent) error) error {
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(s.log)
+	offset := int64(0)
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			if readErr == io.EOF {
+				return nil
+			}
+			offset += int64(len(line))
+			continue
+		}
+
+		var event StoreEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			if readErr == io.EOF {
+				log.Printf("removing torn event at the end of %s, %v\n", s.log.Name(), err)
+				return s.log.Truncate(offset)
+			}
+			return fmt.Errorf("could not parse event on line %d, %v", lineNumber, err)
+		}
+
+		if err := apply(event); err != nil {
+			return err
+		}
+
+		offset += int64(len(line))
+		if readErr == io.EOF {
+			// the next event has to start on its own line
+			if !bytes.HasSuffix(line, []byte("\n")) {
+				_, err := s.log.Write([]byte("\n"))
+				return err
+			}
+			return nil
+		}
+	}
+}
+
+func (s *EventLogPlayerStore) takeSnapshot() error {
+	content, err := json.Marshal(eventLogSnapshot{Seq: s.seq, Leagues: s.leagues, Results: s.results})
+	if err != nil {
+		return err
+	}
+	if _, err := s.snapshot.Write(content); err != nil {
+		return err
+	}
+	s.snapshotSeq = s.seq
+	return nil
+}
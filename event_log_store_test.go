@@ -0,0 +1,227 @@
+package poker_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+	"github.com/andremfp/poker-app/playerstoretest"
+)
+
+func TestEventLogStoreConformance(t *testing.T) {
+	playerstoretest.RunConformance(t, func(t *testing.T) (poker.PlayerStore, func() poker.PlayerStore) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		open := func() poker.PlayerStore {
+			// snapshot often so reopening goes through both the snapshot and the log
+			return createEventLogStore(t, path, 3)
+		}
+		return open(), open
+	})
+}
+
+func TestEventLogStore(t *testing.T) {
+
+	t.Run("every change is logged with its time", func(t *testing.T) {
+		store := createEventLogStore(t, filepath.Join(t.TempDir(), "game.events.jsonl"), 0)
+		before := time.Now().UTC()
+
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Chris"))
+
+		events, err := store.Events()
+		assertNoError(t, err)
+
+		if len(events) != 2 {
+			t.Fatalf("got %d events, want 2", len(events))
+		}
+		for i, event := range events {
+			if event.Seq != i+1 || event.Type != poker.ResultRecordedEvent || event.Time.Before(before) {
+				t.Errorf("got event %+v, want result %d recorded after %v", event, i+1, before)
+			}
+		}
+		if events[1].Result.Winner() != "Chris" {
+			t.Errorf("got winner %q in the last event, want Chris", events[1].Result.Winner())
+		}
+	})
+
+	t.Run("snapshots are taken every n events and replayed with the rest of the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		store := createEventLogStore(t, path, 2)
+
+		recordWins(t, store, "Andre", 3)
+
+		assertFileContains(t, path+".snapshot", `"Seq":2`)
+
+		reopened := createEventLogStore(t, path, 2)
+		assertPlayerScore(t, reopened, "Andre", 3)
+	})
+
+	t.Run("the log wins over a snapshot that can't be read", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		recordWins(t, createEventLogStore(t, path, 1), "Andre", 2)
+
+		assertNoError(t, os.WriteFile(path+".snapshot", []byte("{not json"), 0666))
+
+		reopened := createEventLogStore(t, path, 1)
+		assertPlayerScore(t, reopened, "Andre", 2)
+	})
+
+	t.Run("the log can be read by many at once", func(t *testing.T) {
+		store := createEventLogStore(t, filepath.Join(t.TempDir(), "game.events.jsonl"), 0)
+		recordWins(t, store, "Andre", 5)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				events, err := store.Events()
+				if err != nil || len(events) != 5 {
+					t.Errorf("got %d events and error %v, want 5", len(events), err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("the game being played is kept out of the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		store := createEventLogStore(t, path, 2)
+		ctx := context.Background()
+
+		for minute := 1; minute <= 10; minute++ {
+			game := &poker.ActiveGame{Result: poker.GameResult{ID: "game-1", NumPlayers: 3}}
+			game.Clock.Elapsed = time.Duration(minute) * time.Minute
+			assertNoError(t, store.SaveActiveGame(ctx, game))
+		}
+		assertNoError(t, store.RecordWin(ctx, poker.DefaultLeagueID, "Andre"))
+
+		events, err := store.Events()
+		assertNoError(t, err)
+		if len(events) != 1 || events[0].Type != poker.ResultRecordedEvent {
+			t.Errorf("got events %+v, want just the result", events)
+		}
+
+		game, err := createEventLogStore(t, path, 2).GetActiveGame(ctx)
+		assertNoError(t, err)
+		if game == nil || game.Result.ID != "game-1" || game.Clock.Elapsed != 10*time.Minute {
+			t.Fatalf("got game %+v after reopening, want game-1 as it was last saved", game)
+		}
+
+		assertNoError(t, store.SaveActiveGame(ctx, nil))
+		game, err = createEventLogStore(t, path, 2).GetActiveGame(ctx)
+		assertNoError(t, err)
+		if game != nil {
+			t.Errorf("got game %+v after it was cleared, want none", game)
+		}
+	})
+
+	t.Run("a game saved in the log of an older version carries on", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		createEventLogStore(t, path, 0)
+		appendToFile(t, path, `{"Seq":1,"Type":"game_saved","Game":{"Result":{"ID":"game-1","NumPlayers":3}}}`+"\n")
+
+		store := createEventLogStore(t, path, 1)
+		// the snapshot no longer has the game in it
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+
+		game, err := createEventLogStore(t, path, 1).GetActiveGame(context.Background())
+		assertNoError(t, err)
+		if game == nil || game.Result.ID != "game-1" {
+			t.Errorf("got game %+v, want game-1 carried on", game)
+		}
+	})
+
+	t.Run("a torn event at the end of the log is dropped", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		recordWins(t, createEventLogStore(t, path, 0), "Andre", 2)
+		appendToFile(t, path, `{"Seq":3,"Type":"result_rec`)
+
+		reopened := createEventLogStore(t, path, 0)
+		assertPlayerScore(t, reopened, "Andre", 2)
+
+		assertNoError(t, reopened.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+		events, err := reopened.Events()
+		assertNoError(t, err)
+		if len(events) != 3 {
+			t.Errorf("got %d events, want 3", len(events))
+		}
+	})
+
+	t.Run("results logged before leagues are in the default league", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		createEventLogStore(t, path, 0)
+		appendToFile(t, path, `{"Seq":1,"Type":"result_recorded","Result":{"ID":"old","Finishers":[{"Name":"Andre","Position":1}]}}`+"\n")
+
+		store := createEventLogStore(t, path, 0)
+
+		assertPlayerScore(t, store, "Andre", 1)
+	})
+
+	t.Run("a bad event inside the log is an error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		assertNoError(t, createEventLogStore(t, path, 0).RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+		appendToFile(t, path, "{not json\n")
+		appendToFile(t, path, `{"Seq":3,"Type":"result_recorded"}`+"\n")
+
+		_, err := poker.NewEventLogPlayerStore(path, 0)
+		assertErrorContains(t, err, "could not parse event on line 2")
+	})
+
+	t.Run("rebuild replays the fixed log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.events.jsonl")
+		store := createEventLogStore(t, path, 1)
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andrew"))
+
+		content, err := os.ReadFile(path)
+		assertNoError(t, err)
+		fixed := strings.ReplaceAll(string(content), `"Andrew"`, `"Andre"`)
+		assertNoError(t, os.WriteFile(path, []byte(fixed), 0666))
+
+		assertNoError(t, store.Rebuild())
+
+		assertPlayerScore(t, store, "Andre", 2)
+		assertPlayerScore(t, store, "Andrew", 0)
+
+		// the snapshot is taken from the fixed log
+		reopened := createEventLogStore(t, path, 1)
+		assertPlayerScore(t, reopened, "Andre", 2)
+	})
+}
+
+func createEventLogStore(t testing.TB, path string, snapshotEvery int) *poker.EventLogPlayerStore {
+	t.Helper()
+
+	store, err := poker.NewEventLogPlayerStore(path, snapshotEvery)
+	assertNoError(t, err)
+	t.Cleanup(func() {
+		store.Close()
+	})
+
+	return store
+}
+
+func recordWins(t testing.TB, store poker.PlayerStore, playerName string, wins int) {
+	t.Helper()
+	for i := 0; i < wins; i++ {
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, playerName))
+	}
+}
+
+func appendToFile(t testing.TB, path, content string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0666)
+	assertNoError(t, err)
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatalf("could not append to %s, %v", path, err)
+	}
+}
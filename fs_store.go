@@ -1,95 +1,350 @@
 package poker
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"sync"
 )
 
+// backups of the db file kept next to it, path.1 being the newest
+const DefaultFsStoreBackups = 3
+
 type FsPlayerStore struct {
+	lock     sync.RWMutex
 	database *json.Encoder
-	league   League
+	// writes the same db file without rotating the backups, for the game being played that is saved on every change
+	// and would push the backups of the results out in one evening
+	activeDatabase *json.Encoder
+	leagues        leagueSet
+	results        []GameResult
+	active         *ActiveGame
+	// derived from results, kept to avoid counting wins on every read
+	standings map[string]League
 }
 
-// only read from disk once
-func NewFsPlayerStore(file *os.File) (*FsPlayerStore, error) {
+// fsDatabase is the content of the db file
+type fsDatabase struct {
+	Leagues []LeagueInfo
+	Results []GameResult
+	// the game being played, if any
+	ActiveGame *ActiveGame `json:",omitempty"`
+}
 
-	err := initializePlayerDbFile(file)
-	if err != nil {
-		return nil, fmt.Errorf("could not initialize player db file, %v", err)
-	}
+// NewFsPlayerStore reads the db file at path once, creating it if needed.
+// A corrupt db file is replaced by the newest of its backups that can be read.
+func NewFsPlayerStore(path string, backups int) (*FsPlayerStore, error) {
+	database := &tape{path, backups}
 
-	league, err := NewLeague(file)
+	content, err := loadFsDatabase(database)
 	if err != nil {
-		return nil, fmt.Errorf("could not load player store form file %s, %v", file.Name(), err)
+		return nil, fmt.Errorf("could not load player store form file %s, %v", path, err)
 	}
 
+	leagues := newLeagueSet(content.Leagues)
 	return &FsPlayerStore{
 		// using the tape type, allows to have a custom Write function
-		database: json.NewEncoder(&tape{file}),
-		league:   league,
+		database:       json.NewEncoder(database),
+		activeDatabase: json.NewEncoder(&tape{path, 0}),
+		leagues:        leagues,
+		results:        content.Results,
+		active:         content.ActiveGame,
+		standings:      standingsByLeague(leagues, content.Results),
 	}, nil
 }
 
 func FsPlayerStoreFromFile(path string) (*FsPlayerStore, func(), error) {
-	// create db file. Rw or create if does not exist.
-	db, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	store, err := NewFsPlayerStore(path, DefaultFsStoreBackups)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
+		return nil, nil, fmt.Errorf("problem creating file system player store, %v ", err)
 	}
 
-	closeFunc := func() {
-		db.Close()
+	// the db file is only open while it is read or written
+	closeFunc := func() {}
+
+	return store, closeFunc, nil
+}
+
+func (f *FsPlayerStore) GetLeague(ctx context.Context, leagueID string) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	store, err := NewFsPlayerStore(db)
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if _, err := f.leagues.get(leagueID); err != nil {
+		return nil, err
+	}
+	if league, ok := f.standings[leagueID]; ok {
+		return league, nil
+	}
+	return League{}, nil
+}
 
+func (f *FsPlayerStore) GetPlayerScore(ctx context.Context, leagueID, playerName string) (int, error) {
+	league, err := f.GetLeague(ctx, leagueID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("problem creating file system player store, %v ", err)
+		return 0, err
 	}
 
-	return store, closeFunc, nil
+	player := league.Find(playerName)
+	if player != nil {
+		return player.Wins, nil
+	}
+
+	return 0, nil
 }
 
-func (f *FsPlayerStore) GetLeague() League {
-	return f.league
+// GetSeasonLeague keeps the standings of all time from the cache, the standings of a season are worked out from its
+// results
+func (f *FsPlayerStore) GetSeasonLeague(ctx context.Context, leagueID string, season Season) (League, error) {
+	if season.ID == AllSeasons {
+		return f.GetLeague(ctx, leagueID)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	league, err := f.leagues.get(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return NewLeagueFromResults(resultsInSeason(f.results, leagueID, season), league.ScoringRule()), nil
 }
 
-func (f *FsPlayerStore) GetPlayerScore(playerName string) int {
-	player := f.league.Find(playerName)
-	if player != nil {
-		return player.Wins
+func (f *FsPlayerStore) GetLeagueResults(ctx context.Context, leagueID string, season Season) ([]GameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if _, err := f.leagues.get(leagueID); err != nil {
+		return nil, err
 	}
+	return resultsInSeason(f.results, leagueID, season), nil
+}
 
-	return 0
+func (f *FsPlayerStore) RecordWin(ctx context.Context, leagueID, playerName string) error {
+	return f.RecordResult(ctx, winResult(leagueID, playerName))
 }
 
-func (f *FsPlayerStore) RecordWin(playerName string) {
-	player := f.league.Find(playerName)
-	if player != nil {
-		player.Wins++
-	} else {
-		f.league = append(f.league, Player{playerName, 1})
+func (f *FsPlayerStore) RecordResult(ctx context.Context, result GameResult) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result, err := newGameResult(result)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.leagues.checkOpen(result.League); err != nil {
+		return err
+	}
+	if err := checkNotRecorded(f.results, result.ID); err != nil {
+		return err
+	}
+
+	results := append(f.results[:len(f.results):len(f.results)], result)
+	if err := f.save(f.leagues, results); err != nil {
+		return fmt.Errorf("could not save result, %v", err)
+	}
+	return nil
+}
+
+func (f *FsPlayerStore) GetResults(ctx context.Context) ([]GameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	f.database.Encode(f.league)
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return append([]GameResult(nil), f.results...), nil
 }
 
-func initializePlayerDbFile(file *os.File) error {
-	// always start from the beginning of the reader
-	file.Seek(0, 0)
+func (f *FsPlayerStore) GetPlayerResults(ctx context.Context, playerName string) ([]GameResult, error) {
+	results, err := f.GetResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ResultsForPlayer(results, playerName), nil
+}
 
-	info, err := file.Stat()
+func (f *FsPlayerStore) CreateLeague(ctx context.Context, league LeagueInfo) (LeagueInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return LeagueInfo{}, err
+	}
+
+	league, err := newLeagueInfo(league)
 	if err != nil {
-		return fmt.Errorf("could not get file info from file %s, %v", file.Name(), err)
+		return LeagueInfo{}, err
 	}
 
-	// if exists but is empty, write empty json and seek back to beginning
-	if info.Size() == 0 {
-		file.Write([]byte("[]"))
-		file.Seek(0, 0)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	leagues, err := f.leagues.add(league)
+	if err != nil {
+		return LeagueInfo{}, err
+	}
+	if err := f.save(leagues, f.results); err != nil {
+		return LeagueInfo{}, fmt.Errorf("could not save league %s, %v", league.ID, err)
 	}
+	return league, nil
+}
 
+func (f *FsPlayerStore) GetLeagues(ctx context.Context) ([]LeagueInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return append([]LeagueInfo(nil), f.leagues...), nil
+}
+
+func (f *FsPlayerStore) ArchiveLeague(ctx context.Context, leagueID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	leagues, err := f.leagues.archive(leagueID)
+	if err != nil {
+		return err
+	}
+	if err := f.save(leagues, f.results); err != nil {
+		return fmt.Errorf("could not archive league %s, %v", leagueID, err)
+	}
+	return nil
+}
+
+func (f *FsPlayerStore) SaveActiveGame(ctx context.Context, game *ActiveGame) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	game = game.copy()
+	if err := f.activeDatabase.Encode(fsDatabase{f.leagues, f.results, game}); err != nil {
+		return fmt.Errorf("could not save the game being played, %v", err)
+	}
+	f.active = game
+	return nil
+}
+
+func (f *FsPlayerStore) GetActiveGame(ctx context.Context) (*ActiveGame, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.active.copy(), nil
+}
+
+// save writes the db file and only then keeps the new state, so nothing is kept that isn't safely on disk.
+// Callers hold the write lock.
+func (f *FsPlayerStore) save(leagues leagueSet, results []GameResult) error {
+	if err := f.database.Encode(fsDatabase{leagues, results, f.active}); err != nil {
+		return err
+	}
+
+	f.leagues = leagues
+	f.results = results
+	f.standings = standingsByLeague(leagues, results)
 	return nil
 }
+
+// loadFsDatabase reads the db file, falling back to its backups when it is corrupt.
+// A missing or empty db file with no backups is a new database.
+func loadFsDatabase(database *tape) (fsDatabase, error) {
+	content, err := readDatabaseFile(database.path)
+	if err == nil {
+		return content, nil
+	}
+
+	for i := 1; i <= database.backups; i++ {
+		backup := backupPath(database.path, i)
+		content, backupErr := readDatabaseFile(backup)
+		if backupErr != nil {
+			continue
+		}
+
+		log.Printf("db file %s could not be read (%v), recovering from backup %s\n", database.path, err, backup)
+		if err := restoreBackup(database, content); err != nil {
+			return fsDatabase{}, fmt.Errorf("could not restore backup %s, %v", backup, err)
+		}
+		return content, nil
+	}
+
+	if err == errEmptyDatabase {
+		return fsDatabase{}, nil
+	}
+	return fsDatabase{}, err
+}
+
+var errEmptyDatabase = errors.New("db file is empty")
+
+func readDatabaseFile(path string) (fsDatabase, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(bytes.TrimSpace(content)) == 0) {
+		return fsDatabase{}, errEmptyDatabase
+	}
+	if err != nil {
+		return fsDatabase{}, err
+	}
+	return parseDatabase(content)
+}
+
+// keeps the unreadable db file as path.corrupt, out of the backup rotation, and writes the recovered content
+func restoreBackup(database *tape, recovered fsDatabase) error {
+	err := os.Rename(database.path, database.path+".corrupt")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content, err := json.Marshal(recovered)
+	if err != nil {
+		return err
+	}
+	_, err = database.Write(content)
+	return err
+}
+
+// reads the db file content. Files holding just the league from before results were recorded get a result
+// for every win, results from before there were leagues go to the default league.
+func parseDatabase(content []byte) (fsDatabase, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		league, err := NewLeague(bytes.NewReader(content))
+		if err != nil {
+			return fsDatabase{}, err
+		}
+		return fsDatabase{Results: importLeague(league)}, nil
+	}
+
+	var database fsDatabase
+	if err := json.Unmarshal(content, &database); err != nil {
+		return fsDatabase{}, fmt.Errorf("unable to parse results, %v", err)
+	}
+	database.Results = withDefaultLeague(database.Results)
+	return database, nil
+}
@@ -1,10 +1,16 @@
 package poker_test
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/andremfp/poker-app"
+	"github.com/andremfp/poker-app/playerstoretest"
 )
 
 func TestFileSystemStore(t *testing.T) {
@@ -16,19 +22,19 @@ func TestFileSystemStore(t *testing.T) {
 			{"Name": "Chris", "Wins": 10}]`)
 		defer cleanDatabase()
 
-		store, err := poker.NewFsPlayerStore(database)
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
 		assertNoError(t, err)
 
-		got := store.GetLeague()
+		got := getLeague(t, store)
 		want := []poker.Player{
-			{"Andre", 20},
-			{"Chris", 10},
+			{Name: "Andre", Wins: 20, Points: 20, Played: 20, AverageFinish: 1},
+			{Name: "Chris", Wins: 10, Points: 10, Played: 10, AverageFinish: 1},
 		}
 
 		assertLeague(t, got, want)
 
 		// read again
-		got = store.GetLeague()
+		got = getLeague(t, store)
 		assertLeague(t, got, want)
 	})
 
@@ -39,19 +45,19 @@ func TestFileSystemStore(t *testing.T) {
 			{"Name": "Chris", "Wins": 33}]`)
 		defer cleanDatabase()
 
-		store, err := poker.NewFsPlayerStore(database)
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
 		assertNoError(t, err)
 
-		got := store.GetLeague()
+		got := getLeague(t, store)
 		want := []poker.Player{
-			{"Chris", 33},
-			{"Andre", 10},
+			{Name: "Chris", Wins: 33, Points: 33, Played: 33, AverageFinish: 1},
+			{Name: "Andre", Wins: 10, Points: 10, Played: 10, AverageFinish: 1},
 		}
 
 		assertLeague(t, got, want)
 
 		// read again
-		got = store.GetLeague()
+		got = getLeague(t, store)
 		assertLeague(t, got, want)
 	})
 
@@ -62,13 +68,10 @@ func TestFileSystemStore(t *testing.T) {
 			{"Name": "Chris", "Wins": 33}]`)
 		defer cleanDatabase()
 
-		store, err := poker.NewFsPlayerStore(database)
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
 		assertNoError(t, err)
 
-		got := store.GetPlayerScore("Andre")
-		want := 10
-
-		assertPlayerScore(t, got, want)
+		assertPlayerScore(t, store, "Andre", 10)
 	})
 
 	t.Run("record win for existing players", func(t *testing.T) {
@@ -78,14 +81,11 @@ func TestFileSystemStore(t *testing.T) {
 			{"Name": "Chris", "Wins": 33}]`)
 		defer cleanDatabase()
 
-		store, err := poker.NewFsPlayerStore(database)
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
 		assertNoError(t, err)
 
-		store.RecordWin("Andre")
-		got := store.GetPlayerScore("Andre")
-		want := 11
-
-		assertPlayerScore(t, got, want)
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+		assertPlayerScore(t, store, "Andre", 11)
 	})
 
 	t.Run("record win for new player", func(t *testing.T) {
@@ -93,14 +93,72 @@ func TestFileSystemStore(t *testing.T) {
 		database, cleanDatabase := createTempFile(t, `[]`)
 		defer cleanDatabase()
 
-		store, err := poker.NewFsPlayerStore(database)
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
+		assertNoError(t, err)
+
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+		assertPlayerScore(t, store, "Andre", 1)
+	})
+
+	t.Run("record results and read them back from the file", func(t *testing.T) {
+
+		database, cleanDatabase := createTempFile(t, `[{"Name": "Chris", "Wins": 1}]`)
+		defer cleanDatabase()
+
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
+		assertNoError(t, err)
+
+		result := poker.GameResult{
+			ID:         "game-1",
+			NumPlayers: 3,
+			BuyIn:      10,
+			Finishers: []poker.Finisher{
+				{Name: "John", Position: 3},
+				{Name: "Andre", Position: 1, Payout: 30},
+			},
+		}
+		assertNoError(t, store.RecordResult(context.Background(), result))
+
+		reopened, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
+		assertNoError(t, err)
+
+		results, err := reopened.GetPlayerResults(context.Background(), "Andre")
+		assertNoError(t, err)
+		if len(results) != 1 {
+			t.Fatalf("got %d results for Andre, want 1", len(results))
+		}
+		assertFinishers(t, results[0].Finishers, []poker.Finisher{
+			{Name: "Andre", Position: 1, Payout: 30},
+			{Name: "John", Position: 3},
+		})
+
+		if len(getResults(t, reopened)) != 2 {
+			t.Errorf("got %d results, want the imported win and the new result", len(getResults(t, reopened)))
+		}
+		assertLeague(t, getLeague(t, reopened), []poker.Player{
+			{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+			{Name: "Chris", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+			{Name: "John", Wins: 0, Points: 0, Played: 1, AverageFinish: 3},
+		})
+	})
+
+	t.Run("results from before leagues are in the default league", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `{"Results": [{"ID": "game-1", "Finishers": [{"Name": "Andre", "Position": 1}]}]}`)
+		defer cleanDatabase()
+
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
 		assertNoError(t, err)
 
-		store.RecordWin("Andre")
-		got := store.GetPlayerScore("Andre")
-		want := 1
+		assertPlayerScore(t, store, "Andre", 1)
+		if got := getResults(t, store)[0].League; got != poker.DefaultLeagueID {
+			t.Errorf("got result in league %q, want %q", got, poker.DefaultLeagueID)
+		}
 
-		assertPlayerScore(t, got, want)
+		// the leagues are written with the next change
+		_, err = store.CreateLeague(context.Background(), poker.LeagueInfo{Name: "Friday"})
+		assertNoError(t, err)
+		assertFileContains(t, database.Name(), `"ID":"friday"`)
+		assertFileContains(t, database.Name(), `"League":"default"`)
 	})
 
 	t.Run("works with empty file", func(t *testing.T) {
@@ -108,16 +166,144 @@ func TestFileSystemStore(t *testing.T) {
 		database, cleanDatabase := createTempFile(t, "")
 		defer cleanDatabase()
 
-		_, err := poker.NewFsPlayerStore(database)
+		_, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
+		assertNoError(t, err)
+	})
+}
+
+func TestFileSystemStoreRecovery(t *testing.T) {
+	valid := `[{"Name": "Andre", "Wins": 2}]`
+	older := `[{"Name": "Andre", "Wins": 1}]`
+
+	t.Run("corrupt file is recovered from the newest valid backup", func(t *testing.T) {
+		path := writeDbFiles(t, "{not json", "also not json", valid, older)
+
+		store, err := poker.NewFsPlayerStore(path, 3)
+		assertNoError(t, err)
+
+		assertPlayerScore(t, store, "Andre", 2)
+		assertFileContains(t, path+".corrupt", "{not json")
+
+		// the recovered data is written back to the db file
+		reopened, err := poker.NewFsPlayerStore(path, 0)
+		assertNoError(t, err)
+		assertPlayerScore(t, reopened, "Andre", 2)
+	})
+
+	t.Run("missing file is recovered from a backup", func(t *testing.T) {
+		path := writeDbFiles(t, "", valid)
+		os.Remove(path)
+
+		store, err := poker.NewFsPlayerStore(path, 3)
+		assertNoError(t, err)
+
+		assertPlayerScore(t, store, "Andre", 2)
+	})
+
+	t.Run("empty file is recovered from a backup", func(t *testing.T) {
+		path := writeDbFiles(t, "", valid)
+
+		store, err := poker.NewFsPlayerStore(path, 3)
 		assertNoError(t, err)
+
+		assertPlayerScore(t, store, "Andre", 2)
+	})
+
+	t.Run("corrupt file without a valid backup is an error", func(t *testing.T) {
+		path := writeDbFiles(t, "{not json", "also not json")
+
+		_, err := poker.NewFsPlayerStore(path, 3)
+		assertErrorContains(t, err, "unable to parse")
+	})
+
+	t.Run("backups past the configured number are ignored", func(t *testing.T) {
+		path := writeDbFiles(t, "{not json", "also not json", valid)
+
+		_, err := poker.NewFsPlayerStore(path, 1)
+		assertErrorContains(t, err, "unable to parse")
 	})
 }
 
-// FsStore file just for testing with cleanup
+func TestFileSystemStoreBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.db.json")
+	store, err := poker.NewFsPlayerStore(path, 2)
+	assertNoError(t, err)
+
+	for _, winner := range []string{"Andre", "Chris", "John"} {
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, winner))
+	}
+
+	assertFileContains(t, path, "John")
+	assertFileContains(t, path+".1", "Chris")
+	assertFileNotContains(t, path+".1", "John")
+	assertFileContains(t, path+".2", "Andre")
+	assertFileNotContains(t, path+".2", "Chris")
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups, found %s.3", path)
+	}
+}
+
+func TestFileSystemStoreActiveGameBackups(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "game.db.json")
+	store, err := poker.NewFsPlayerStore(path, 2)
+	assertNoError(t, err)
+
+	for _, winner := range []string{"Andre", "Chris"} {
+		assertNoError(t, store.RecordWin(ctx, poker.DefaultLeagueID, winner))
+	}
+	// every pause, elimination and entry of a game saves it
+	game := &poker.ActiveGame{
+		Result: poker.GameResult{ID: "game-1", NumPlayers: 3},
+		Clock:  poker.ClockState{Levels: []poker.ScheduledLevel{{Level: poker.BlindLevel{SmallBlind: 100, BigBlind: 200}}}},
+	}
+	for i := 0; i < 5; i++ {
+		assertNoError(t, store.SaveActiveGame(ctx, game))
+	}
+	assertNoError(t, store.SaveActiveGame(ctx, nil))
+
+	assertFileContains(t, path, "Chris")
+	assertFileContains(t, path+".1", "Andre")
+	assertFileNotContains(t, path+".1", "Chris")
+	if _, err := os.Stat(path + ".2"); !os.IsNotExist(err) {
+		t.Errorf("got %s.2 rotated in by the saves of the game being played", path)
+	}
+}
+
+func TestFileSystemStoreWriteFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+	assertNoError(t, os.Mkdir(dir, 0777))
+	store, err := poker.NewFsPlayerStore(filepath.Join(dir, "game.db.json"), 0)
+	assertNoError(t, err)
+
+	// nothing can be written once the directory is gone
+	assertNoError(t, os.RemoveAll(dir))
+
+	if err := store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"); err == nil {
+		t.Fatal("expected an error but didn't get one")
+	}
+	assertPlayerScore(t, store, "Andre", 0)
+}
+
+func TestFileSystemStoreConformance(t *testing.T) {
+	playerstoretest.RunConformance(t, func(t *testing.T) (poker.PlayerStore, func() poker.PlayerStore) {
+		path := filepath.Join(t.TempDir(), "game.db.json")
+		open := func() poker.PlayerStore {
+			store, closeStore, err := poker.FsPlayerStoreFromFile(path)
+			assertNoError(t, err)
+			t.Cleanup(closeStore)
+			return store
+		}
+		return open(), open
+	})
+}
+
+// FsStore file just for testing with cleanup, backups are made in the same temp dir
 func createTempFile(t testing.TB, initialData string) (*os.File, func()) {
 	t.Helper()
 
-	tmpfile, err := os.CreateTemp("", "db")
+	tmpfile, err := os.CreateTemp(t.TempDir(), "db")
 	if err != nil {
 		t.Fatalf("could not create temp file %v", err)
 	}
@@ -139,9 +325,72 @@ func assertNoError(t testing.TB, err error) {
 	}
 }
 
-func assertPlayerScore(t testing.TB, got, want int) {
+// writes the db file and its backups in order, returning the db file path
+func writeDbFiles(t testing.TB, db string, backups ...string) string {
 	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "game.db.json")
+	files := append([]string{db}, backups...)
+	for i, content := range files {
+		name := path
+		if i > 0 {
+			name = fmt.Sprintf("%s.%d", path, i)
+		}
+		if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+			t.Fatalf("could not write %s, %v", name, err)
+		}
+	}
+	return path
+}
+
+func assertFileContains(t testing.TB, path, want string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read %s, %v", path, err)
+	}
+	if !strings.Contains(string(content), want) {
+		t.Errorf("got %s containing %q, want it to contain %q", path, content, want)
+	}
+}
+
+func assertFileNotContains(t testing.TB, path, unwanted string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read %s, %v", path, err)
+	}
+	if strings.Contains(string(content), unwanted) {
+		t.Errorf("got %s containing %q, want it not to", path, content)
+	}
+}
+
+func assertFinishers(t testing.TB, got, want []poker.Finisher) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got finishers %v, want %v", got, want)
+	}
+}
+
+func assertPlayerScore(t testing.TB, store poker.PlayerStore, playerName string, want int) {
+	t.Helper()
+	got, err := store.GetPlayerScore(context.Background(), poker.DefaultLeagueID, playerName)
+	assertNoError(t, err)
 	if got != want {
-		t.Errorf("got score of %d, wanted %d", got, want)
+		t.Errorf("got score of %d for %s, wanted %d", got, playerName, want)
 	}
 }
+
+func getLeague(t testing.TB, store poker.PlayerStore) poker.League {
+	t.Helper()
+	league, err := store.GetLeague(context.Background(), poker.DefaultLeagueID)
+	assertNoError(t, err)
+	return league
+}
+
+func getResults(t testing.TB, store poker.PlayerStore) []poker.GameResult {
+	t.Helper()
+	results, err := store.GetResults(context.Background())
+	assertNoError(t, err)
+	return results
+}
@@ -1,8 +1,113 @@
 package poker
 
-import "io"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultStartingStack is the stack of players that start without one
+const DefaultStartingStack = 10000
+
+var ErrInvalidRoster = errors.New("invalid roster")
 
 type Game interface {
-	Start(numPlayers int, alertsDestination io.Writer)
-	Finish(winner string)
+	// Start a game with the players of the settings
+	Start(ctx context.Context, settings GameSettings, alertsDestination io.Writer) error
+	// Restore carries on with the game that was running before a restart, restored is false when there was none
+	Restore(ctx context.Context, alertsDestination io.Writer) (restored bool, err error)
+	Pause() error
+	Resume() error
+	SkipLevel() error
+	// Eliminate knocks the player out in the last position still open. Once one player is left the result is recorded
+	// with them the winner and the game is over, winner is empty until then. The finisher is empty when the
+	// elimination was refused, an error with a finisher is a player knocked out whose result couldn't be recorded.
+	Eliminate(ctx context.Context, playerName string) (finisher Finisher, winner string, err error)
+	// Finish records the result, the winner first then the players eliminated
+	Finish(ctx context.Context, winner string) error
+	// Rebuy buys the player back in while rebuys are open, players knocked out are back in the game
+	Rebuy(ctx context.Context, playerName string) error
+	// AddOn buys a player still in the add-on of the first break
+	AddOn(ctx context.Context, playerName string) error
+	// Register adds a player to the roster while late registration is open
+	Register(ctx context.Context, player Entrant) error
+	BlindStructures() []string
+	// Status of the running game, ErrGameNotStarted when there's none
+	Status() (GameStatus, error)
+}
+
+// GameStatus is where a running game is at, what someone joining halfway through needs to catch up
+type GameStatus struct {
+	League         string
+	BlindStructure string
+	Started        time.Time
+	Level          BlindLevel
+	// time left in the level being played, 0 at the last level
+	NextLevelIn      time.Duration
+	Paused           bool
+	PlayersRemaining int
+	// the players still in, in the order of the roster
+	Playing []string `json:",omitempty"`
+	// every buy-in, rebuy and add-on paid so far
+	PrizePool int `json:",omitempty"`
+}
+
+// GameSettings are what a game starts with
+type GameSettings struct {
+	Players        []Entrant
+	BlindStructure string // the default structure when empty
+	League         string // the default league when empty
+	// the levels not played yet get shorter as players are eliminated, for blind structures with a duration per player
+	AdaptiveLevels bool `json:",omitempty"`
+	// what each entry costs, the prize pool is made of them
+	BuyIn   int        `json:",omitempty"`
+	Entries EntryRules `json:",omitempty"`
+}
+
+// Entrant is a player on the roster of a game and the chips they start with, DefaultStartingStack when 0
+type Entrant struct {
+	Name  string
+	Stack int `json:",omitempty"`
+	// entries bought after the buy-in
+	Rebuys int  `json:",omitempty"`
+	AddOn  bool `json:",omitempty"`
+}
+
+// Validate checks there are at least 2 players with different, valid names and no negative stacks, buy-in or
+// entry rules
+func (s GameSettings) Validate() error {
+	if s.BuyIn < 0 {
+		return fmt.Errorf("%w, the buy-in can't be negative, got %d", ErrInvalidEntry, s.BuyIn)
+	}
+	if err := s.Entries.Validate(); err != nil {
+		return err
+	}
+	if len(s.Players) < 2 {
+		return fmt.Errorf("%w, a game needs at least 2 players, got %d", ErrInvalidRoster, len(s.Players))
+	}
+	seen := map[string]bool{}
+	for _, player := range s.Players {
+		if err := ValidatePlayerName(player.Name); err != nil {
+			return fmt.Errorf("%w, %v", ErrInvalidRoster, err)
+		}
+		if seen[player.Name] {
+			return fmt.Errorf("%w, %s is on it twice", ErrInvalidRoster, player.Name)
+		}
+		seen[player.Name] = true
+		if player.Stack < 0 {
+			return fmt.Errorf("%w, %s can't start with %d chips", ErrInvalidRoster, player.Name, player.Stack)
+		}
+	}
+	return nil
+}
+
+// Roster of named players without stacks of their own, they start with DefaultStartingStack
+func Roster(names ...string) []Entrant {
+	players := make([]Entrant, len(names))
+	for i, name := range names {
+		players[i] = Entrant{Name: name}
+	}
+	return players
 }
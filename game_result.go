@@ -0,0 +1,212 @@
+package poker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrInvalidGameResult is wrapped by the errors of results that can't be recorded because of their content
+var ErrInvalidGameResult = errors.New("invalid game result")
+
+// GameResult is everything recorded about a finished game, the league is built from these
+type GameResult struct {
+	ID             string
+	League         string // id of the league the game was played in, the default league when empty
+	Started        time.Time
+	Finished       time.Time
+	NumPlayers     int
+	BlindStructure string
+	BuyIn          int
+	AddOnCost      int `json:",omitempty"`
+	// ordered by finishing position, the winner first
+	Finishers []Finisher
+}
+
+type Finisher struct {
+	Name      string
+	Position  int
+	Payout    int
+	Knockouts int // players this finisher knocked out, for bounty scoring
+	// when the player was knocked out, results recorded by hand and winners don't have it
+	Eliminated *time.Time `json:",omitempty"`
+	// entries bought after the buy-in
+	Rebuys int  `json:",omitempty"`
+	AddOn  bool `json:",omitempty"`
+}
+
+// Winner is the player that finished first, empty if the result has no winner
+func (r GameResult) Winner() string {
+	for _, finisher := range r.Finishers {
+		if finisher.Position == 1 {
+			return finisher.Name
+		}
+	}
+	return ""
+}
+
+// PaidIn is what the finisher paid to play, their buy-in, rebuys and add-on
+func (r GameResult) PaidIn(finisher Finisher) int {
+	paid := r.BuyIn * (1 + finisher.Rebuys)
+	if finisher.AddOn {
+		paid += r.AddOnCost
+	}
+	return paid
+}
+
+// PrizePool is every entry paid into the game, players without a finishing position bought in once
+func (r GameResult) PrizePool() int {
+	pool := r.BuyIn * max(r.NumPlayers-len(r.Finishers), 0)
+	for _, finisher := range r.Finishers {
+		pool += r.PaidIn(finisher)
+	}
+	return pool
+}
+
+// Finisher returns the finishing details of a player, nil if they didn't play
+func (r GameResult) Finisher(playerName string) *Finisher {
+	for i, finisher := range r.Finishers {
+		if finisher.Name == playerName {
+			return &r.Finishers[i]
+		}
+	}
+	return nil
+}
+
+func (r GameResult) Validate() error {
+	if len(r.Finishers) == 0 {
+		return fmt.Errorf("game result has no finishers")
+	}
+	if r.NumPlayers > 0 && len(r.Finishers) > r.NumPlayers {
+		return fmt.Errorf("game result has %d finishers for %d players", len(r.Finishers), r.NumPlayers)
+	}
+	if r.BuyIn < 0 {
+		return fmt.Errorf("game result has a negative buy-in of %d", r.BuyIn)
+	}
+	if r.AddOnCost < 0 {
+		return fmt.Errorf("game result has a negative add-on cost of %d", r.AddOnCost)
+	}
+	if !r.Started.IsZero() && r.Finished.Before(r.Started) {
+		return fmt.Errorf("game result finished at %v before it started at %v", r.Finished, r.Started)
+	}
+
+	names := map[string]bool{}
+	positions := map[int]bool{}
+	for _, finisher := range r.Finishers {
+		if finisher.Name == "" {
+			return fmt.Errorf("game result has a finisher with no name")
+		}
+		if err := ValidatePlayerName(finisher.Name); err != nil {
+			return err
+		}
+		if names[finisher.Name] {
+			return fmt.Errorf("player %q finished the game more than once", finisher.Name)
+		}
+		if finisher.Position < 1 || (r.NumPlayers > 0 && finisher.Position > r.NumPlayers) {
+			return fmt.Errorf("player %q has an invalid finishing position of %d", finisher.Name, finisher.Position)
+		}
+		if positions[finisher.Position] {
+			return fmt.Errorf("more than one player finished in position %d", finisher.Position)
+		}
+		if finisher.Payout < 0 {
+			return fmt.Errorf("player %q has a negative payout of %d", finisher.Name, finisher.Payout)
+		}
+		if finisher.Knockouts < 0 {
+			return fmt.Errorf("player %q has a negative number of knockouts %d", finisher.Name, finisher.Knockouts)
+		}
+		if finisher.Rebuys < 0 {
+			return fmt.Errorf("player %q has a negative number of rebuys %d", finisher.Name, finisher.Rebuys)
+		}
+		names[finisher.Name] = true
+		positions[finisher.Position] = true
+	}
+
+	return nil
+}
+
+// newGameResult fills in what stores need to save a result: an ID, a finish time and finishers sorted by position
+func newGameResult(result GameResult) (GameResult, error) {
+	if err := result.Validate(); err != nil {
+		return GameResult{}, fmt.Errorf("%w, %v", ErrInvalidGameResult, err)
+	}
+
+	if result.ID == "" {
+		result.ID = newID()
+	}
+	if result.League == "" {
+		result.League = DefaultLeagueID
+	}
+	if result.Finished.IsZero() {
+		result.Finished = time.Now().UTC()
+	}
+
+	finishers := make([]Finisher, len(result.Finishers))
+	copy(finishers, result.Finishers)
+	sort.Slice(finishers, func(i, j int) bool {
+		return finishers[i].Position < finishers[j].Position
+	})
+	result.Finishers = finishers
+
+	return result, nil
+}
+
+// checkNotRecorded fails for a result with the id of one of the results already recorded
+func checkNotRecorded(results []GameResult, id string) error {
+	for _, result := range results {
+		if result.ID == id {
+			return fmt.Errorf("%w, result %s was already recorded", ErrInvalidGameResult, id)
+		}
+	}
+	return nil
+}
+
+// winResult is the result recorded when only the winner of a game is known
+func winResult(leagueID, playerName string) GameResult {
+	return GameResult{League: leagueID, Finishers: []Finisher{{Name: playerName, Position: 1}}}
+}
+
+// ResultsForPlayer returns the results of the games the player finished in
+func ResultsForPlayer(results []GameResult, playerName string) []GameResult {
+	var played []GameResult
+	for _, result := range results {
+		if result.Finisher(playerName) != nil {
+			played = append(played, result)
+		}
+	}
+	return played
+}
+
+// withDefaultLeague puts the results recorded before there were leagues in the default league
+func withDefaultLeague(results []GameResult) []GameResult {
+	for i := range results {
+		if results[i].League == "" {
+			results[i].League = DefaultLeagueID
+		}
+	}
+	return results
+}
+
+// importLeague turns the win counters of the original league format into results, one per win
+func importLeague(league League) []GameResult {
+	var results []GameResult
+	for _, player := range league {
+		for i := 1; i <= player.Wins; i++ {
+			result := winResult(DefaultLeagueID, player.Name)
+			result.ID = fmt.Sprintf("imported-%s-%d", player.Name, i)
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails if the OS can't provide randomness
+		panic(fmt.Sprintf("could not generate id, %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,140 @@
+package poker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestGameResultValidate(t *testing.T) {
+	start := time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		result  poker.GameResult
+		wantErr string
+	}{
+		{
+			name: "valid result",
+			result: poker.GameResult{NumPlayers: 3, BuyIn: 10, Started: start, Finished: start.Add(time.Hour), Finishers: []poker.Finisher{
+				{Name: "Andre", Position: 1, Payout: 30}, {Name: "Chris", Position: 2}, {Name: "John", Position: 3},
+			}},
+		},
+		{
+			name:   "only the winner is known",
+			result: poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}},
+		},
+		{
+			name:    "no finishers",
+			result:  poker.GameResult{NumPlayers: 3},
+			wantErr: "no finishers",
+		},
+		{
+			name:    "more finishers than players",
+			result:  poker.GameResult{NumPlayers: 1, Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Chris", Position: 2}}},
+			wantErr: "2 finishers for 1 players",
+		},
+		{
+			name:    "finisher without a name",
+			result:  poker.GameResult{Finishers: []poker.Finisher{{Position: 1}}},
+			wantErr: "no name",
+		},
+		{
+			name:    "finisher with an unusable name",
+			result:  poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre/Chris", Position: 1}}},
+			wantErr: "invalid player name",
+		},
+		{
+			name:    "player finishing twice",
+			result:  poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Andre", Position: 2}}},
+			wantErr: `player "Andre" finished the game more than once`,
+		},
+		{
+			name:    "shared position",
+			result:  poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Chris", Position: 1}}},
+			wantErr: "more than one player finished in position 1",
+		},
+		{
+			name:    "position outside the field",
+			result:  poker.GameResult{NumPlayers: 2, Finishers: []poker.Finisher{{Name: "Andre", Position: 3}}},
+			wantErr: "invalid finishing position of 3",
+		},
+		{
+			name:    "negative payout",
+			result:  poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1, Payout: -5}}},
+			wantErr: "negative payout",
+		},
+		{
+			name:    "negative rebuys",
+			result:  poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1, Rebuys: -1}}},
+			wantErr: "negative number of rebuys",
+		},
+		{
+			name:    "negative buy-in",
+			result:  poker.GameResult{BuyIn: -5, Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}},
+			wantErr: "negative buy-in",
+		},
+		{
+			name:    "finished before it started",
+			result:  poker.GameResult{Started: start, Finished: start.Add(-time.Minute), Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}},
+			wantErr: "before it started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorContains(t, tt.result.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestGameResultPrizePool(t *testing.T) {
+	result := poker.GameResult{NumPlayers: 4, BuyIn: 10, AddOnCost: 5, Finishers: []poker.Finisher{
+		{Name: "Andre", Position: 1, Rebuys: 2, AddOn: true}, {Name: "Chris", Position: 2, AddOn: true}, {Name: "John", Position: 3, Rebuys: 1},
+	}}
+
+	for name, want := range map[string]int{"Andre": 35, "Chris": 15, "John": 20} {
+		if got := result.PaidIn(*result.Finisher(name)); got != want {
+			t.Errorf("got %s paid in %d want %d", name, got, want)
+		}
+	}
+	// the player without a finishing position bought in once
+	if got := result.PrizePool(); got != 80 {
+		t.Errorf("got a prize pool of %d want 80", got)
+	}
+}
+
+func TestNewLeagueFromResults(t *testing.T) {
+	results := []poker.GameResult{
+		{Finishers: []poker.Finisher{{Name: "Chris", Position: 1}, {Name: "Andre", Position: 2}, {Name: "John", Position: 3}}},
+		{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Chris", Position: 2}}},
+		{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}},
+	}
+
+	wins, err := poker.GetScoringRule(poker.WinsScoring)
+	assertNoError(t, err)
+
+	got := poker.NewLeagueFromResults(results, wins)
+	want := []poker.Player{
+		{Name: "Andre", Wins: 2, Points: 2, Played: 3, AverageFinish: 1.33},
+		{Name: "Chris", Wins: 1, Points: 1, Played: 2, AverageFinish: 1.5},
+		{Name: "John", Wins: 0, Points: 0, Played: 1, AverageFinish: 3},
+	}
+	assertLeague(t, got, want)
+
+	t.Run("ties are ordered by name", func(t *testing.T) {
+		got := poker.NewLeagueFromResults(results[:2], wins)
+		want := []poker.Player{
+			{Name: "Andre", Wins: 1, Points: 1, Played: 2, AverageFinish: 1.5},
+			{Name: "Chris", Wins: 1, Points: 1, Played: 2, AverageFinish: 1.5},
+			{Name: "John", Wins: 0, Points: 0, Played: 1, AverageFinish: 3},
+		}
+		assertLeague(t, got, want)
+	})
+
+	t.Run("results for a player", func(t *testing.T) {
+		got := poker.ResultsForPlayer(results, "Chris")
+		assertResults(t, got, results[:2])
+	})
+}
@@ -0,0 +1,657 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/andremfp/poker-app/cards"
+)
+
+var (
+	ErrHandOver         = errors.New("the hand is over")
+	ErrNotYourTurn      = errors.New("not your turn to act")
+	ErrInvalidAction    = errors.New("invalid action")
+	ErrNotEnoughPlayers = errors.New("a hand needs at least 2 players with chips")
+	ErrHandOnBreak      = errors.New("no hands are dealt during a break")
+)
+
+type Street int
+
+const (
+	Preflop Street = iota
+	Flop
+	Turn
+	River
+	Showdown
+)
+
+var streetNames = []string{"preflop", "flop", "turn", "river", "showdown"}
+
+func (s Street) String() string {
+	if s < 0 || int(s) >= len(streetNames) {
+		return "unknown"
+	}
+	return streetNames[s]
+}
+
+// cards dealt to the board on each street
+var boardCards = map[Street]int{Flop: 3, Turn: 1, River: 1}
+
+type HandActionType string
+
+const (
+	FoldAction  HandActionType = "fold"
+	CheckAction HandActionType = "check"
+	CallAction  HandActionType = "call"
+	// a bet when nobody has bet yet, the amount is what the player's bet comes to in the betting round
+	RaiseAction HandActionType = "raise"
+	AllInAction HandActionType = "all-in"
+)
+
+// HandAction is what a player does when it's their turn, Amount is only for raises
+type HandAction struct {
+	Player string
+	Type   HandActionType
+	Amount int `json:",omitempty"`
+}
+
+type HandEventType string
+
+const (
+	AntePostedEvent     HandEventType = "ante-posted"
+	BlindPostedEvent    HandEventType = "blind-posted"
+	HoleCardsDealtEvent HandEventType = "hole-cards-dealt"
+	// the player whose turn it is, with what it takes to call and the smallest raise
+	ActionOnEvent    HandEventType = "action-on"
+	PlayerActedEvent HandEventType = "player-acted"
+	// the part of a bet nobody called goes back to the player
+	BetReturnedEvent HandEventType = "bet-returned"
+	BoardDealtEvent  HandEventType = "board-dealt"
+	ShowdownEvent    HandEventType = "showdown"
+	PotWonEvent      HandEventType = "pot-won"
+	HandEndedEvent   HandEventType = "hand-ended"
+)
+
+// HandEvent is something that happened in the hand. Hole cards are only meant for the player they were dealt to until
+// they are shown down, it's up to whoever drives the hand to keep them private.
+type HandEvent struct {
+	Type   HandEventType
+	Player string         `json:",omitempty"`
+	Action HandActionType `json:",omitempty"`
+	// chips posted, bet, returned or won, for ActionOnEvent what it takes to call
+	Amount int `json:",omitempty"`
+	// the pot won, 0 for the main pot and then the side pots in the order they were made
+	Pot int `json:",omitempty"`
+	// the smallest a raise can come to, 0 when the player can't raise
+	MinRaise int            `json:",omitempty"`
+	Street   Street         `json:",omitempty"`
+	Cards    []cards.Card   `json:",omitempty"`
+	Rank     cards.HandRank `json:",omitempty"`
+}
+
+// HandSeat is a player sitting at the table and their chips, players without chips sit the hand out
+type HandSeat struct {
+	Name  string
+	Stack int
+}
+
+type handPlayer struct {
+	HandSeat
+	hole []cards.Card
+	// chips put in during this betting round and the whole hand
+	bet         int
+	contributed int
+	folded      bool
+	allIn       bool
+	// acted since the last full raise, a short all-in raise doesn't give them another go
+	acted bool
+	out   bool
+}
+
+// canAct is true for the players that still have decisions to make in the hand
+func (p *handPlayer) canAct() bool {
+	return !p.out && !p.folded && !p.allIn
+}
+
+func (p *handPlayer) inHand() bool {
+	return !p.out && !p.folded
+}
+
+// Hand is one hand of Texas Hold'em as a state machine, actions go in and the events they lead to come out. It does no
+// I/O and isn't safe for concurrent use, whoever drives it keeps it to one goroutine or behind a lock.
+type Hand struct {
+	players []*handPlayer
+	button  int
+	level   BlindLevel
+	deck    *cards.Deck
+
+	street Street
+	board  []cards.Card
+	pot    int
+	toAct  int
+	// the bet to call in this betting round and the smallest a raise can add to it
+	currentBet int
+	minRaise   int
+	over       bool
+}
+
+// NewHand posts the antes and blinds of the level and deals the hole cards one at a time starting left of the button.
+// A card is burnt before each street. The events end with the first player to act.
+func NewHand(seats []HandSeat, button int, level BlindLevel, deck *cards.Deck) (*Hand, []HandEvent, error) {
+	if level.Break {
+		return nil, nil, ErrHandOnBreak
+	}
+	if button < 0 || button >= len(seats) || seats[button].Stack <= 0 {
+		return nil, nil, fmt.Errorf("the button has to be on a player with chips, got seat %d", button)
+	}
+
+	h := &Hand{button: button, level: level, deck: deck, minRaise: level.BigBlind}
+	dealtIn := 0
+	for _, seat := range seats {
+		player := &handPlayer{HandSeat: seat, out: seat.Stack <= 0}
+		if !player.out {
+			dealtIn++
+		}
+		h.players = append(h.players, player)
+	}
+	if dealtIn < 2 {
+		return nil, nil, ErrNotEnoughPlayers
+	}
+
+	var events []HandEvent
+	if level.Ante > 0 {
+		for _, player := range h.inSeatOrder(h.next(button)) {
+			posted := h.post(player, level.Ante)
+			player.bet = 0
+			events = append(events, HandEvent{Type: AntePostedEvent, Player: player.Name, Amount: posted})
+		}
+	}
+
+	// heads up the button is the small blind
+	smallBlind := h.next(button)
+	if dealtIn == 2 {
+		smallBlind = button
+	}
+	bigBlind := h.next(smallBlind)
+	for _, blind := range []struct {
+		seat   int
+		amount int
+	}{{smallBlind, level.SmallBlind}, {bigBlind, level.BigBlind}} {
+		player := h.players[blind.seat]
+		posted := h.post(player, blind.amount)
+		events = append(events, HandEvent{Type: BlindPostedEvent, Player: player.Name, Amount: posted})
+	}
+	// a big blind that is short still has to be called in full
+	h.currentBet = level.BigBlind
+
+	dealOrder := h.inSeatOrder(h.next(button))
+	for round := 0; round < 2; round++ {
+		for _, player := range dealOrder {
+			card, err := deck.Deal()
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not deal the hole cards, %w", err)
+			}
+			player.hole = append(player.hole, card)
+		}
+	}
+	for _, player := range dealOrder {
+		events = append(events, HandEvent{Type: HoleCardsDealtEvent, Player: player.Name, Cards: player.hole})
+	}
+
+	h.toAct = bigBlind
+	more, err := h.advance()
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, append(events, more...), nil
+}
+
+// Act plays the action of the player whose turn it is
+func (h *Hand) Act(action HandAction) ([]HandEvent, error) {
+	if h.over {
+		return nil, ErrHandOver
+	}
+	player := h.players[h.toAct]
+	if action.Player != player.Name {
+		return nil, fmt.Errorf("%w, it's on %s", ErrNotYourTurn, player.Name)
+	}
+
+	toCall := h.currentBet - player.bet
+	acted := HandEvent{Type: PlayerActedEvent, Player: player.Name, Action: action.Type}
+
+	switch action.Type {
+	case FoldAction:
+		player.folded = true
+
+	case CheckAction:
+		if toCall > 0 {
+			return nil, fmt.Errorf("%w, %s has %d to call", ErrInvalidAction, player.Name, toCall)
+		}
+
+	case CallAction:
+		if toCall <= 0 {
+			return nil, fmt.Errorf("%w, there's nothing to call", ErrInvalidAction)
+		}
+		acted.Amount = h.post(player, toCall)
+
+	case RaiseAction:
+		if err := h.validateRaise(player, action.Amount); err != nil {
+			return nil, err
+		}
+		acted.Amount = h.raise(player, action.Amount)
+
+	case AllInAction:
+		allIn := player.bet + player.Stack
+		if allIn <= h.currentBet {
+			acted.Amount = h.post(player, player.Stack)
+			break
+		}
+		if player.acted {
+			return nil, fmt.Errorf("%w, %s already acted and can only call or fold", ErrInvalidAction, player.Name)
+		}
+		acted.Amount = h.raise(player, allIn)
+
+	default:
+		return nil, fmt.Errorf("%w, unknown action %q", ErrInvalidAction, action.Type)
+	}
+	player.acted = true
+
+	events := []HandEvent{acted}
+	more, err := h.advance()
+	if err != nil {
+		return nil, err
+	}
+	return append(events, more...), nil
+}
+
+// Over is true once the pot was won
+func (h *Hand) Over() bool {
+	return h.over
+}
+
+func (h *Hand) Street() Street {
+	return h.street
+}
+
+func (h *Hand) Board() []cards.Card {
+	return append([]cards.Card(nil), h.board...)
+}
+
+// Pot is every chip put in during the hand, bets included
+func (h *Hand) Pot() int {
+	return h.pot
+}
+
+// ToAct is the player whose turn it is, empty once the hand is over
+func (h *Hand) ToAct() string {
+	if h.over {
+		return ""
+	}
+	return h.players[h.toAct].Name
+}
+
+// Seats are the players in seat order with the chips they have behind
+func (h *Hand) Seats() []HandSeat {
+	seats := make([]HandSeat, len(h.players))
+	for i, player := range h.players {
+		seats[i] = player.HandSeat
+	}
+	return seats
+}
+
+func (h *Hand) validateRaise(player *handPlayer, to int) error {
+	if player.acted {
+		return fmt.Errorf("%w, %s already acted and can only call or fold", ErrInvalidAction, player.Name)
+	}
+	if to-player.bet > player.Stack {
+		return fmt.Errorf("%w, %s only has %d", ErrInvalidAction, player.Name, player.Stack)
+	}
+	if to <= h.currentBet {
+		return fmt.Errorf("%w, a raise has to come to more than %d, call or go all in instead", ErrInvalidAction, h.currentBet)
+	}
+	// going all in for less than a full raise is allowed
+	if to-player.bet < player.Stack && to < h.currentBet+h.minRaise {
+		return fmt.Errorf("%w, a raise has to come to at least %d", ErrInvalidAction, h.currentBet+h.minRaise)
+	}
+	return nil
+}
+
+// raise brings the bet of the player up to to, a full raise gives everyone else another go
+func (h *Hand) raise(player *handPlayer, to int) int {
+	posted := h.post(player, to-player.bet)
+	if raisedBy := player.bet - h.currentBet; raisedBy >= h.minRaise {
+		h.minRaise = raisedBy
+		for _, other := range h.players {
+			other.acted = false
+		}
+	}
+	// an all in for less than the bet never lowers what the others owe
+	h.currentBet = max(h.currentBet, player.bet)
+	return posted
+}
+
+// post moves chips from the stack of the player to the pot, as many as they have
+func (h *Hand) post(player *handPlayer, amount int) int {
+	if amount >= player.Stack {
+		amount = player.Stack
+		player.allIn = true
+	}
+	player.Stack -= amount
+	player.bet += amount
+	player.contributed += amount
+	h.pot += amount
+	return amount
+}
+
+// advance moves the hand on after an action, to the next player, the next street or the end of the hand
+func (h *Hand) advance() ([]HandEvent, error) {
+	var events []HandEvent
+
+	if h.playersIn() == 1 {
+		events = append(events, h.returnUncalled()...)
+		return append(events, h.win()...), nil
+	}
+
+	if !h.roundOver() {
+		h.toAct = h.nextToAct(h.toAct)
+		return append(events, h.actionOn()), nil
+	}
+
+	events = append(events, h.returnUncalled()...)
+	for {
+		for _, player := range h.players {
+			player.bet = 0
+			player.acted = false
+		}
+		h.currentBet = 0
+		h.minRaise = h.level.BigBlind
+
+		if h.street == River {
+			return append(events, h.showdown()...), nil
+		}
+		h.street++
+		dealt, err := h.dealBoard()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, dealt)
+
+		// with everyone all in but one there's no betting left, the board is run out
+		if h.playersAbleToAct() >= 2 {
+			h.toAct = h.nextToAct(h.button)
+			return append(events, h.actionOn()), nil
+		}
+	}
+}
+
+// roundOver is true when every player that can act has had their go and matched the bet. A player left on their
+// own against all in players only has to match what was bet.
+func (h *Hand) roundOver() bool {
+	if h.playersAbleToAct() == 1 {
+		for _, player := range h.players {
+			if player.canAct() {
+				return player.bet >= h.highestBet(player)
+			}
+		}
+	}
+
+	for _, player := range h.players {
+		if player.canAct() && (!player.acted || player.bet < h.currentBet) {
+			return false
+		}
+	}
+	return true
+}
+
+// highestBet of everyone but the player in this betting round
+func (h *Hand) highestBet(but *handPlayer) int {
+	highest := 0
+	for _, player := range h.players {
+		if player != but && player.bet > highest {
+			highest = player.bet
+		}
+	}
+	return highest
+}
+
+// returnUncalled gives back the part of the biggest bet of the round that nobody else put in
+func (h *Hand) returnUncalled() []HandEvent {
+	var top *handPlayer
+	for _, player := range h.players {
+		if top == nil || player.bet > top.bet {
+			top = player
+		}
+	}
+	uncalled := top.bet - h.highestBet(top)
+	if uncalled <= 0 {
+		return nil
+	}
+
+	top.Stack += uncalled
+	top.bet -= uncalled
+	top.contributed -= uncalled
+	top.allIn = top.Stack == 0
+	h.pot -= uncalled
+	return []HandEvent{{Type: BetReturnedEvent, Player: top.Name, Amount: uncalled}}
+}
+
+func (h *Hand) dealBoard() (HandEvent, error) {
+	if _, err := h.deck.Deal(); err != nil {
+		return HandEvent{}, fmt.Errorf("could not burn a card, %w", err)
+	}
+	dealt, err := h.deck.DealN(boardCards[h.street])
+	if err != nil {
+		return HandEvent{}, fmt.Errorf("could not deal the %s, %w", h.street, err)
+	}
+	h.board = append(h.board, dealt...)
+	return HandEvent{Type: BoardDealtEvent, Street: h.street, Cards: dealt}, nil
+}
+
+func (h *Hand) actionOn() HandEvent {
+	player := h.players[h.toAct]
+	event := HandEvent{Type: ActionOnEvent, Player: player.Name, Street: h.street, Amount: h.currentBet - player.bet}
+	if event.Amount > player.Stack {
+		event.Amount = player.Stack
+	}
+	if !player.acted && player.bet+player.Stack > h.currentBet {
+		event.MinRaise = min(h.currentBet+h.minRaise, player.bet+player.Stack)
+	}
+	return event
+}
+
+// win gives the pot to the last player in, nobody shows their cards
+func (h *Hand) win() []HandEvent {
+	h.over = true
+	for _, player := range h.players {
+		if player.inHand() {
+			player.Stack += h.pot
+			return []HandEvent{
+				{Type: PotWonEvent, Player: player.Name, Amount: h.pot},
+				{Type: HandEndedEvent},
+			}
+		}
+	}
+	return nil
+}
+
+// showdown awards the main pot and each side pot to the best hand that can win it, the odd chips go to the winners
+// closest to the left of the button
+func (h *Hand) showdown() []HandEvent {
+	h.over = true
+	h.street = Showdown
+
+	var events []HandEvent
+	var contributions []Contribution
+	ranks := map[string]cards.HandRank{}
+	byName := map[string]*handPlayer{}
+	for _, player := range h.inSeatOrder(h.next(h.button)) {
+		contributions = append(contributions, Contribution{Player: player.Name, Amount: player.contributed, Folded: player.folded})
+		byName[player.Name] = player
+		if player.folded {
+			continue
+		}
+		rank, _ := cards.Evaluate(append(append([]cards.Card(nil), player.hole...), h.board...)...)
+		ranks[player.Name] = rank
+		events = append(events, HandEvent{Type: ShowdownEvent, Player: player.Name, Cards: player.hole, Rank: rank})
+	}
+
+	for _, share := range AwardPots(BuildPots(contributions), ranks) {
+		byName[share.Player].Stack += share.Amount
+		events = append(events, HandEvent{Type: PotWonEvent, Player: share.Player, Amount: share.Amount, Pot: share.Pot, Rank: ranks[share.Player]})
+	}
+	return append(events, HandEvent{Type: HandEndedEvent})
+}
+
+func (h *Hand) playersIn() int {
+	in := 0
+	for _, player := range h.players {
+		if player.inHand() {
+			in++
+		}
+	}
+	return in
+}
+
+func (h *Hand) playersAbleToAct() int {
+	able := 0
+	for _, player := range h.players {
+		if player.canAct() {
+			able++
+		}
+	}
+	return able
+}
+
+// next seat after seat with a player dealt in
+func (h *Hand) next(seat int) int {
+	for i := 1; i <= len(h.players); i++ {
+		next := (seat + i) % len(h.players)
+		if !h.players[next].out {
+			return next
+		}
+	}
+	return seat
+}
+
+// nextToAct is the next seat after seat with a decision to make
+func (h *Hand) nextToAct(seat int) int {
+	for i := 1; i <= len(h.players); i++ {
+		next := (seat + i) % len(h.players)
+		if player := h.players[next]; player.canAct() && (!player.acted || player.bet < h.currentBet) {
+			return next
+		}
+	}
+	return seat
+}
+
+// inSeatOrder are the players dealt in going round the table from seat
+func (h *Hand) inSeatOrder(from int) []*handPlayer {
+	var players []*handPlayer
+	for i := 0; i < len(h.players); i++ {
+		if player := h.players[(from+i)%len(h.players)]; !player.out {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+// Dealer deals one hand after another at a table. The stacks carry over from hand to hand and the button moves on to
+// the next player with chips.
+type Dealer struct {
+	seats  []HandSeat
+	button int
+	rng    *rand.Rand
+	hand   *Hand
+}
+
+// NewDealer seats the players in the order given with the button on the first of them with chips. Without a rng the
+// deck is shuffled with one seeded from crypto/rand.
+func NewDealer(seats []HandSeat, rng *rand.Rand) (*Dealer, error) {
+	if rng == nil {
+		var err error
+		if rng, err = cards.NewCryptoRand(); err != nil {
+			return nil, err
+		}
+	}
+
+	d := &Dealer{seats: append([]HandSeat(nil), seats...), button: -1, rng: rng}
+	d.button = d.nextWithChips(len(d.seats) - 1)
+	if d.button < 0 {
+		return nil, ErrNotEnoughPlayers
+	}
+	return d, nil
+}
+
+// Deal shuffles a new deck and starts a hand with the blinds and antes of level, the last hand has to be over
+func (d *Dealer) Deal(level BlindLevel) ([]HandEvent, error) {
+	if d.hand != nil && !d.hand.Over() {
+		return nil, fmt.Errorf("%w, the hand being played isn't over", ErrInvalidAction)
+	}
+
+	deck := cards.NewDeck()
+	deck.Shuffle(d.rng)
+	hand, events, err := NewHand(d.seats, d.button, level, deck)
+	if err != nil {
+		return nil, err
+	}
+	d.hand = hand
+	d.handOver()
+	return events, nil
+}
+
+func (d *Dealer) Act(action HandAction) ([]HandEvent, error) {
+	if d.hand == nil {
+		return nil, fmt.Errorf("%w, no hand was dealt", ErrInvalidAction)
+	}
+	events, err := d.hand.Act(action)
+	if err != nil {
+		return nil, err
+	}
+	d.handOver()
+	return events, nil
+}
+
+// Hand being played or last played, nil before the first deal
+func (d *Dealer) Hand() *Hand {
+	return d.hand
+}
+
+// Seats have the stacks as they were after the last hand
+func (d *Dealer) Seats() []HandSeat {
+	return append([]HandSeat(nil), d.seats...)
+}
+
+// Button is the seat with the button for the next hand
+func (d *Dealer) Button() int {
+	return d.button
+}
+
+// handOver takes the stacks from a hand that is over and moves the button on
+func (d *Dealer) handOver() {
+	if !d.hand.Over() {
+		return
+	}
+	d.seats = d.hand.Seats()
+	if next := d.nextWithChips(d.button); next >= 0 {
+		d.button = next
+	}
+}
+
+// nextWithChips is the first seat after seat with a player that has chips, -1 if there are fewer than 2 of them
+func (d *Dealer) nextWithChips(seat int) int {
+	withChips, next := 0, -1
+	for i := 1; i <= len(d.seats); i++ {
+		candidate := (seat + i) % len(d.seats)
+		if d.seats[candidate].Stack > 0 {
+			withChips++
+			if next < 0 {
+				next = candidate
+			}
+		}
+	}
+	if withChips < 2 {
+		return -1
+	}
+	return next
+}
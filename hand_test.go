@@ -0,0 +1,430 @@
+package poker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andremfp/poker-app"
+	"github.com/andremfp/poker-app/cards"
+)
+
+func TestHand(t *testing.T) {
+	level := poker.BlindLevel{SmallBlind: 5, BigBlind: 10}
+	seats := func(stacks ...int) []poker.HandSeat {
+		names := []string{"Andre", "Chris", "John", "Mary"}
+		seats := make([]poker.HandSeat, len(stacks))
+		for i, stack := range stacks {
+			seats[i] = poker.HandSeat{Name: names[i], Stack: stack}
+		}
+		return seats
+	}
+
+	t.Run("blinds are posted and the player left of the big blind acts first", func(t *testing.T) {
+		hand, events := mustDealHand(t, seats(1000, 1000, 1000), 0, level, stackedDeck(t, "", "As Ad", "Ks Kd", "Qs Qd"))
+
+		assertHandEvents(t, events,
+			poker.HandEvent{Type: poker.BlindPostedEvent, Player: "Chris", Amount: 5},
+			poker.HandEvent{Type: poker.BlindPostedEvent, Player: "John", Amount: 10},
+			poker.HandEvent{Type: poker.HoleCardsDealtEvent, Player: "Chris", Cards: cards.MustParseCards("As Ad")},
+			poker.HandEvent{Type: poker.HoleCardsDealtEvent, Player: "John", Cards: cards.MustParseCards("Ks Kd")},
+			poker.HandEvent{Type: poker.HoleCardsDealtEvent, Player: "Andre", Cards: cards.MustParseCards("Qs Qd")},
+			poker.HandEvent{Type: poker.ActionOnEvent, Player: "Andre", Amount: 10, MinRaise: 20},
+		)
+		if hand.Pot() != 15 || hand.Street() != poker.Preflop {
+			t.Errorf("got pot %d on the %s, want 15 preflop", hand.Pot(), hand.Street())
+		}
+	})
+
+	t.Run("antes are posted by everyone before the blinds", func(t *testing.T) {
+		withAnte := poker.BlindLevel{SmallBlind: 5, BigBlind: 10, Ante: 2}
+		hand, events := mustDealHand(t, seats(1000, 1000, 1000), 0, withAnte, stackedDeck(t, ""))
+
+		assertHandEvents(t, events[:3],
+			poker.HandEvent{Type: poker.AntePostedEvent, Player: "Chris", Amount: 2},
+			poker.HandEvent{Type: poker.AntePostedEvent, Player: "John", Amount: 2},
+			poker.HandEvent{Type: poker.AntePostedEvent, Player: "Andre", Amount: 2},
+		)
+		if hand.Pot() != 21 {
+			t.Errorf("got pot %d want 21", hand.Pot())
+		}
+		// the antes don't count towards calling the big blind
+		assertActionOn(t, events, "Andre", 10)
+	})
+
+	t.Run("heads up the button is the small blind and acts first only preflop", func(t *testing.T) {
+		hand, events := mustDealHand(t, seats(1000, 1000), 0, level, stackedDeck(t, ""))
+
+		assertHandEvents(t, events[:2],
+			poker.HandEvent{Type: poker.BlindPostedEvent, Player: "Andre", Amount: 5},
+			poker.HandEvent{Type: poker.BlindPostedEvent, Player: "Chris", Amount: 10},
+		)
+		assertActionOn(t, events, "Andre", 5)
+
+		mustAct(t, hand, "Andre", poker.CallAction, 0)
+		events = mustAct(t, hand, "Chris", poker.CheckAction, 0)
+		if hand.Street() != poker.Flop || len(hand.Board()) != 3 {
+			t.Fatalf("got %s with board %v, want the flop", hand.Street(), hand.Board())
+		}
+		assertActionOn(t, events, "Chris", 0)
+	})
+
+	t.Run("everyone folding gives the pot to the last player without a showdown", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(1000, 1000, 1000), 0, level, stackedDeck(t, ""))
+
+		mustAct(t, hand, "Andre", poker.FoldAction, 0)
+		events := mustAct(t, hand, "Chris", poker.FoldAction, 0)
+
+		assertHandEvents(t, events,
+			poker.HandEvent{Type: poker.PlayerActedEvent, Player: "Chris", Action: poker.FoldAction},
+			poker.HandEvent{Type: poker.BetReturnedEvent, Player: "John", Amount: 5},
+			poker.HandEvent{Type: poker.PotWonEvent, Player: "John", Amount: 10},
+			poker.HandEvent{Type: poker.HandEndedEvent},
+		)
+		assertStacks(t, hand, 1000, 995, 1005)
+		if !hand.Over() || hand.ToAct() != "" {
+			t.Error("the hand should be over")
+		}
+		if _, err := hand.Act(poker.HandAction{Player: "John", Type: poker.CheckAction}); err != poker.ErrHandOver {
+			t.Errorf("got error %v want %v", err, poker.ErrHandOver)
+		}
+	})
+
+	t.Run("the big blind gets the option when everyone calls", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(1000, 1000, 1000), 0, level, stackedDeck(t, ""))
+
+		mustAct(t, hand, "Andre", poker.CallAction, 0)
+		events := mustAct(t, hand, "Chris", poker.CallAction, 0)
+		assertActionOn(t, events, "John", 0)
+
+		events = mustAct(t, hand, "John", poker.RaiseAction, 30)
+		assertActionOn(t, events, "Andre", 20)
+	})
+
+	t.Run("the best hand at showdown wins the pot", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(1000, 1000, 1000), 0, level,
+			stackedDeck(t, "2c 7d 9h Jc Qs", "As Ad", "Ks Kd", "Qh Qd"))
+
+		mustAct(t, hand, "Andre", poker.RaiseAction, 30)
+		mustAct(t, hand, "Chris", poker.CallAction, 0)
+		mustAct(t, hand, "John", poker.FoldAction, 0)
+		for _, street := range []poker.Street{poker.Flop, poker.Turn} {
+			mustAct(t, hand, "Chris", poker.CheckAction, 0)
+			events := mustAct(t, hand, "Andre", poker.CheckAction, 0)
+			assertHandEvents(t, events[1:2], poker.HandEvent{Type: poker.BoardDealtEvent, Street: street + 1, Cards: hand.Board()[len(hand.Board())-1:]})
+		}
+		mustAct(t, hand, "Chris", poker.RaiseAction, 100)
+		events := mustAct(t, hand, "Andre", poker.CallAction, 0)
+
+		chris, _ := cards.Evaluate(cards.MustParseCards("As Ad 2c 7d 9h Jc Qs")...)
+		andre, _ := cards.Evaluate(cards.MustParseCards("Qh Qd 2c 7d 9h Jc Qs")...)
+		assertHandEvents(t, events[1:],
+			poker.HandEvent{Type: poker.ShowdownEvent, Player: "Chris", Cards: cards.MustParseCards("As Ad"), Rank: chris},
+			poker.HandEvent{Type: poker.ShowdownEvent, Player: "Andre", Cards: cards.MustParseCards("Qh Qd"), Rank: andre},
+			poker.HandEvent{Type: poker.PotWonEvent, Player: "Andre", Amount: 270, Rank: andre},
+			poker.HandEvent{Type: poker.HandEndedEvent},
+		)
+		assertStacks(t, hand, 1140, 870, 990)
+		if hand.Street() != poker.Showdown {
+			t.Errorf("got %s want the showdown", hand.Street())
+		}
+	})
+
+	t.Run("tied hands split the pot and the odd chip goes left of the button", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(1000, 1000, 1000), 0, level,
+			stackedDeck(t, "Ah Kh Qh Jh Th", "2c 3d", "4c 5d", "6c 7d"))
+
+		mustAct(t, hand, "Andre", poker.CallAction, 0)
+		mustAct(t, hand, "Chris", poker.FoldAction, 0)
+		mustAct(t, hand, "John", poker.CheckAction, 0)
+		for i := 0; i < 3; i++ {
+			mustAct(t, hand, "John", poker.CheckAction, 0)
+			mustAct(t, hand, "Andre", poker.CheckAction, 0)
+		}
+
+		assertStacks(t, hand, 1002, 995, 1003)
+	})
+
+	t.Run("a bet nobody can call in full is returned and the board is run out", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(1000, 300), 0, level, stackedDeck(t, "2c 7d 9h Jc 3s", "As Ad", "Ks Kd"))
+
+		mustAct(t, hand, "Andre", poker.AllInAction, 0)
+		events := mustAct(t, hand, "Chris", poker.CallAction, 0)
+
+		assertHandEvents(t, events[:2],
+			poker.HandEvent{Type: poker.PlayerActedEvent, Player: "Chris", Action: poker.CallAction, Amount: 290},
+			poker.HandEvent{Type: poker.BetReturnedEvent, Player: "Andre", Amount: 700},
+		)
+		if !hand.Over() || len(hand.Board()) != 5 {
+			t.Fatalf("got board %v, want it run out to the showdown", hand.Board())
+		}
+		// Chris is dealt first and has the aces
+		assertStacks(t, hand, 700, 600)
+	})
+
+	t.Run("all ins for different stacks are won through side pots", func(t *testing.T) {
+		// Chris has the best hand but only covers the main pot, John beats Andre for the side pot
+		hand, _ := mustDealHand(t, seats(1000, 100, 400), 0, level,
+			stackedDeck(t, "2c 7d 9h Jc 3s", "As Ad", "Ks Kd", "Qh Qd"))
+
+		mustAct(t, hand, "Andre", poker.RaiseAction, 1000)
+		mustAct(t, hand, "Chris", poker.AllInAction, 0)
+		events := mustAct(t, hand, "John", poker.AllInAction, 0)
+
+		assertHandEvents(t, events[1:2], poker.HandEvent{Type: poker.BetReturnedEvent, Player: "Andre", Amount: 600})
+		var won []poker.HandEvent
+		for _, event := range events {
+			if event.Type == poker.PotWonEvent {
+				event.Rank = 0
+				won = append(won, event)
+			}
+		}
+		assertHandEvents(t, won,
+			poker.HandEvent{Type: poker.PotWonEvent, Player: "Chris", Amount: 300},
+			poker.HandEvent{Type: poker.PotWonEvent, Player: "John", Amount: 600, Pot: 1},
+		)
+		assertStacks(t, hand, 600, 300, 600)
+	})
+
+	t.Run("a short all in raise doesn't let players that acted raise again", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(1000, 1000, 150), 0, level, stackedDeck(t, ""))
+
+		mustAct(t, hand, "Andre", poker.RaiseAction, 100)
+		mustAct(t, hand, "Chris", poker.CallAction, 0)
+		events := mustAct(t, hand, "John", poker.AllInAction, 0)
+		assertHandEvents(t, events[1:], poker.HandEvent{Type: poker.ActionOnEvent, Player: "Andre", Amount: 50})
+
+		_, err := hand.Act(poker.HandAction{Player: "Andre", Type: poker.RaiseAction, Amount: 300})
+		assertHandError(t, err, poker.ErrInvalidAction)
+		mustAct(t, hand, "Andre", poker.CallAction, 0)
+		events = mustAct(t, hand, "Chris", poker.CallAction, 0)
+
+		if hand.Street() != poker.Flop || hand.Pot() != 450 {
+			t.Errorf("got pot %d on the %s want 450 on the flop", hand.Pot(), hand.Street())
+		}
+		assertActionOn(t, events, "Chris", 0)
+	})
+
+	t.Run("an all in for less than the bet leaves the players after it owing the full bet", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(60, 1000, 1000, 1000), 0, level, stackedDeck(t, ""))
+
+		mustAct(t, hand, "Mary", poker.RaiseAction, 100)
+		_, err := hand.Act(poker.HandAction{Player: "Andre", Type: poker.RaiseAction, Amount: 60})
+		assertHandError(t, err, poker.ErrInvalidAction)
+
+		events := mustAct(t, hand, "Andre", poker.AllInAction, 0)
+		assertActionOn(t, events, "Chris", 95)
+		events = mustAct(t, hand, "Chris", poker.CallAction, 0)
+		assertActionOn(t, events, "John", 90)
+	})
+
+	t.Run("actions that can't be played", func(t *testing.T) {
+		hand, _ := mustDealHand(t, seats(1000, 1000, 1000), 0, level, stackedDeck(t, ""))
+
+		tests := []struct {
+			name   string
+			action poker.HandAction
+			want   error
+		}{
+			{"out of turn", poker.HandAction{Player: "Chris", Type: poker.FoldAction}, poker.ErrNotYourTurn},
+			{"check facing a bet", poker.HandAction{Player: "Andre", Type: poker.CheckAction}, poker.ErrInvalidAction},
+			{"raise less than the big blind", poker.HandAction{Player: "Andre", Type: poker.RaiseAction, Amount: 15}, poker.ErrInvalidAction},
+			{"raise more than the stack", poker.HandAction{Player: "Andre", Type: poker.RaiseAction, Amount: 1001}, poker.ErrInvalidAction},
+			{"unknown action", poker.HandAction{Player: "Andre", Type: "dance"}, poker.ErrInvalidAction},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := hand.Act(tt.action)
+				assertHandError(t, err, tt.want)
+			})
+		}
+
+		mustAct(t, hand, "Andre", poker.CallAction, 0)
+		mustAct(t, hand, "Chris", poker.CallAction, 0)
+		_, err := hand.Act(poker.HandAction{Player: "John", Type: poker.CallAction})
+		assertHandError(t, err, poker.ErrInvalidAction)
+	})
+
+	t.Run("hands need two players with chips and can't be dealt on a break", func(t *testing.T) {
+		if _, _, err := poker.NewHand(seats(1000, 0), 0, level, cards.NewDeck()); err != poker.ErrNotEnoughPlayers {
+			t.Errorf("got error %v want %v", err, poker.ErrNotEnoughPlayers)
+		}
+		if _, _, err := poker.NewHand(seats(1000, 1000), 0, poker.BlindLevel{Break: true}, cards.NewDeck()); err != poker.ErrHandOnBreak {
+			t.Errorf("got error %v want %v", err, poker.ErrHandOnBreak)
+		}
+	})
+}
+
+func TestDealer(t *testing.T) {
+	level := poker.BlindLevel{SmallBlind: 5, BigBlind: 10}
+	seats := []poker.HandSeat{{Name: "Andre", Stack: 1000}, {Name: "Chris", Stack: 0}, {Name: "John", Stack: 1000}, {Name: "Mary", Stack: 1000}}
+
+	t.Run("the button moves on after each hand and stacks carry over", func(t *testing.T) {
+		dealer, err := poker.NewDealer(seats, rand.New(rand.NewSource(1)))
+		assertNoError(t, err)
+
+		events, err := dealer.Deal(level)
+		assertNoError(t, err)
+		// Chris has no chips and sits out
+		assertActionOn(t, events, "Andre", 10)
+		if _, err := dealer.Deal(level); !errors.Is(err, poker.ErrInvalidAction) {
+			t.Errorf("got error %v want %v while a hand is played", err, poker.ErrInvalidAction)
+		}
+
+		mustDealerAct(t, dealer, "Andre", poker.FoldAction)
+		mustDealerAct(t, dealer, "John", poker.FoldAction)
+
+		want := []poker.HandSeat{{Name: "Andre", Stack: 1000}, {Name: "Chris", Stack: 0}, {Name: "John", Stack: 995}, {Name: "Mary", Stack: 1005}}
+		if got := dealer.Seats(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got seats %+v want %+v", got, want)
+		}
+		if dealer.Button() != 2 {
+			t.Errorf("got the button on seat %d want 2", dealer.Button())
+		}
+
+		events, err = dealer.Deal(level)
+		assertNoError(t, err)
+		assertHandEvents(t, events[:2],
+			poker.HandEvent{Type: poker.BlindPostedEvent, Player: "Mary", Amount: 5},
+			poker.HandEvent{Type: poker.BlindPostedEvent, Player: "Andre", Amount: 10},
+		)
+	})
+
+	t.Run("a table needs two players with chips", func(t *testing.T) {
+		if _, err := poker.NewDealer(seats[:2], nil); err != poker.ErrNotEnoughPlayers {
+			t.Errorf("got error %v want %v", err, poker.ErrNotEnoughPlayers)
+		}
+	})
+
+	t.Run("the game deals with the level being played", func(t *testing.T) {
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, &SpyBlindAlerter{}, poker.BlindPresets())
+		dealer, err := poker.NewDealer(seats, nil)
+		assertNoError(t, err)
+
+		if _, err := game.DealHand(dealer); err != poker.ErrGameNotStarted {
+			t.Errorf("got error %v want %v", err, poker.ErrGameNotStarted)
+		}
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(3, "", ""), io.Discard))
+		status, err := game.Status()
+		assertNoError(t, err)
+		events, err := game.DealHand(dealer)
+		assertNoError(t, err)
+		if events[1].Amount != status.Level.BigBlind {
+			t.Errorf("got big blind %d want %d", events[1].Amount, status.Level.BigBlind)
+		}
+	})
+}
+
+// stackedDeck deals the hole cards in the order they are dealt, starting left of the button, then the board with a
+// burnt card before each street. The cards not given follow in order, so hands without any are dealt a new deck.
+func stackedDeck(t testing.TB, board string, holes ...string) *cards.Deck {
+	t.Helper()
+	var order []cards.Card
+	for round := 0; round < 2; round++ {
+		for _, hole := range holes {
+			order = append(order, cards.MustParseCards(hole)[round])
+		}
+	}
+
+	used := map[cards.Card]bool{}
+	for _, card := range append(cards.MustParseCards(board), order...) {
+		used[card] = true
+	}
+	rest := cards.NewDeck()
+	burn := func() cards.Card {
+		for {
+			card, err := rest.Deal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !used[card] {
+				used[card] = true
+				return card
+			}
+		}
+	}
+
+	for i, card := range cards.MustParseCards(board) {
+		if i == 0 || i >= 3 {
+			order = append(order, burn())
+		}
+		order = append(order, card)
+	}
+	for rest.Len() > 0 {
+		if card, _ := rest.Deal(); !used[card] {
+			order = append(order, card)
+		}
+	}
+	return cards.DeckOf(order...)
+}
+
+func mustDealHand(t testing.TB, seats []poker.HandSeat, button int, level poker.BlindLevel, deck *cards.Deck) (*poker.Hand, []poker.HandEvent) {
+	t.Helper()
+	hand, events, err := poker.NewHand(seats, button, level, deck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hand, events
+}
+
+func mustAct(t testing.TB, hand *poker.Hand, player string, action poker.HandActionType, amount int) []poker.HandEvent {
+	t.Helper()
+	events, err := hand.Act(poker.HandAction{Player: player, Type: action, Amount: amount})
+	if err != nil {
+		t.Fatalf("%s could not %s, %v", player, action, err)
+	}
+	return events
+}
+
+func mustDealerAct(t testing.TB, dealer *poker.Dealer, player string, action poker.HandActionType) {
+	t.Helper()
+	if _, err := dealer.Act(poker.HandAction{Player: player, Type: action}); err != nil {
+		t.Fatalf("%s could not %s, %v", player, action, err)
+	}
+}
+
+func assertHandEvents(t testing.TB, got []poker.HandEvent, want ...poker.HandEvent) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events\n%s\nwant\n%s", formatHandEvents(got), formatHandEvents(want))
+	}
+}
+
+func formatHandEvents(events []poker.HandEvent) string {
+	lines := make([]string, len(events))
+	for i, event := range events {
+		lines[i] = fmt.Sprintf("%+v", event)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// assertActionOn checks the last event hands the action to player with toCall to call
+func assertActionOn(t testing.TB, events []poker.HandEvent, player string, toCall int) {
+	t.Helper()
+	last := events[len(events)-1]
+	if last.Type != poker.ActionOnEvent || last.Player != player || last.Amount != toCall {
+		t.Errorf("got %+v want the action on %s with %d to call", last, player, toCall)
+	}
+}
+
+func assertStacks(t testing.TB, hand *poker.Hand, want ...int) {
+	t.Helper()
+	for i, seat := range hand.Seats() {
+		if seat.Stack != want[i] {
+			t.Errorf("got %s with %d chips want %d", seat.Name, seat.Stack, want[i])
+		}
+	}
+}
+
+func assertHandError(t testing.TB, got, want error) {
+	t.Helper()
+	if !errors.Is(got, want) {
+		t.Errorf("got error %v want %v", got, want)
+	}
+}
@@ -0,0 +1,287 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// how often spectators get the time left in the level
+const DefaultTickInterval = time.Second
+
+// how many of the last messages of a game are kept for those resuming after losing their connection
+const historySize = 100
+
+var ErrGameNotFound = errors.New("game not found")
+
+// Subscriber gets the messages of a game, like a websocket connection
+type Subscriber interface {
+	Send(message Message) error
+}
+
+// GameHub has the games being played, each broadcasting what happens in it to everyone watching
+type GameHub struct {
+	tick time.Duration
+
+	lock  sync.Mutex
+	games map[string]*LiveGame
+}
+
+func NewGameHub(tick time.Duration) *GameHub {
+	return &GameHub{tick: tick, games: map[string]*LiveGame{}}
+}
+
+// Open starts broadcasting a new game, status is asked for where the game is at on every tick and for every late joiner
+func (h *GameHub) Open(status func() (GameStatus, error)) *LiveGame {
+	game := &LiveGame{
+		ID:          newID(),
+		status:      status,
+		subscribers: map[*subscription]struct{}{},
+		stop:        make(chan struct{}),
+	}
+	go game.tickEvery(h.tick)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.games[game.ID] = game
+	return game
+}
+
+func (h *GameHub) Get(id string) (*LiveGame, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	game, ok := h.games[id]
+	if !ok {
+		return nil, fmt.Errorf("%w, %q", ErrGameNotFound, id)
+	}
+	return game, nil
+}
+
+// Close sends the last message of the game to everyone watching and hangs up on them
+func (h *GameHub) Close(id string, last Message) {
+	h.lock.Lock()
+	game, ok := h.games[id]
+	delete(h.games, id)
+	h.lock.Unlock()
+
+	if ok {
+		game.close(last)
+	}
+}
+
+// CloseOthers ends the games besides id, for games that can only be played one at a time
+func (h *GameHub) CloseOthers(id string) {
+	h.lock.Lock()
+	var others []string
+	for other := range h.games {
+		if other != id {
+			others = append(others, other)
+		}
+	}
+	h.lock.Unlock()
+
+	for _, other := range others {
+		h.Close(other, NewMessage(EndedMessage, nil))
+	}
+}
+
+// LiveGameStatus is a game being played, for those looking for one to watch
+type LiveGameStatus struct {
+	ID string
+	GameStatus
+}
+
+// Games being played, sorted by id. Games without a status yet are left out.
+func (h *GameHub) Games() []LiveGameStatus {
+	h.lock.Lock()
+	games := make([]*LiveGame, 0, len(h.games))
+	for _, game := range h.games {
+		games = append(games, game)
+	}
+	h.lock.Unlock()
+
+	statuses := []LiveGameStatus{}
+	for _, game := range games {
+		if status, err := game.Status(); err == nil {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses
+}
+
+// LiveGame sends the messages announced in it, like the blind levels, to every subscriber. It is also an io.Writer
+// for alerters that only write text.
+type LiveGame struct {
+	ID     string
+	status func() (GameStatus, error)
+
+	lock        sync.Mutex
+	subscribers map[*subscription]struct{}
+	seq         int64
+	history     []Message
+	// the last message that is no longer in the history
+	forgotten int64
+	closed    bool
+	stop      chan struct{}
+}
+
+type subscription struct {
+	subscriber Subscriber
+	done       chan struct{}
+	once       sync.Once
+}
+
+func (s *subscription) cancel() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (g *LiveGame) Status() (LiveGameStatus, error) {
+	status, err := g.status()
+	if err != nil {
+		return LiveGameStatus{}, err
+	}
+	return LiveGameStatus{ID: g.ID, GameStatus: status}, nil
+}
+
+// Subscribe sends a status message with where the game is at and then every message of the game. done is closed once
+// the game is over, or straight away when it already is.
+func (g *LiveGame) Subscribe(subscriber Subscriber) (done <-chan struct{}, unsubscribe func()) {
+	// no message is numbered -1 so there's always a status to catch up with
+	return g.Resume(subscriber, -1)
+}
+
+// Resume is Subscribe for those that lost their connection after the message numbered after. The messages they missed
+// are sent again when they are still kept, otherwise they get a status message like everyone joining.
+func (g *LiveGame) Resume(subscriber Subscriber, after int64) (done <-chan struct{}, unsubscribe func()) {
+	sub := &subscription{subscriber: subscriber, done: make(chan struct{})}
+	// asked for before locking, the game announces while holding its own locks
+	status, statusErr := g.Status()
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.closed {
+		sub.cancel()
+		return sub.done, func() {}
+	}
+
+	if err := g.catchUp(subscriber, after, status, statusErr); err != nil {
+		sub.cancel()
+		return sub.done, func() {}
+	}
+	g.subscribers[sub] = struct{}{}
+
+	return sub.done, func() {
+		g.lock.Lock()
+		defer g.lock.Unlock()
+		delete(g.subscribers, sub)
+		sub.cancel()
+	}
+}
+
+func (g *LiveGame) catchUp(subscriber Subscriber, after int64, status LiveGameStatus, statusErr error) error {
+	if after >= g.seq {
+		return nil
+	}
+
+	if after >= 0 && after >= g.forgotten {
+		for _, message := range g.history {
+			if message.Seq <= after {
+				continue
+			}
+			if err := subscriber.Send(message); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if statusErr != nil {
+		return nil
+	}
+	// numbered like the last message sent, it sums up everything up to there
+	message := NewMessage(StatusMessage, status)
+	message.Seq = g.seq
+	return subscriber.Send(message)
+}
+
+// Announce numbers the message and sends it to every subscriber, those that can't be sent to are dropped
+func (g *LiveGame) Announce(message Message) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.closed {
+		return
+	}
+	g.broadcast(message)
+}
+
+// Write announces p as a text message, there's nothing to announce for empty writes
+func (g *LiveGame) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	g.Announce(NewMessage(TextMessage, TextPayload{Text: string(p)}))
+	return len(p), nil
+}
+
+func (g *LiveGame) broadcast(message Message) {
+	g.seq++
+	message.Seq = g.seq
+	// a tick is out of date by the next one so they aren't kept
+	if message.Type != TickMessage {
+		g.history = append(g.history, message)
+	}
+	if len(g.history) > historySize {
+		g.forgotten = g.history[len(g.history)-historySize-1].Seq
+		g.history = g.history[len(g.history)-historySize:]
+	}
+	for sub := range g.subscribers {
+		if err := sub.subscriber.Send(message); err != nil {
+			delete(g.subscribers, sub)
+			sub.cancel()
+		}
+	}
+}
+
+func (g *LiveGame) close(last Message) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.closed {
+		return
+	}
+	g.broadcast(last)
+	g.closed = true
+	close(g.stop)
+
+	for sub := range g.subscribers {
+		delete(g.subscribers, sub)
+		sub.cancel()
+	}
+}
+
+// tickEvery tells everyone how long is left in the level, a paused clock or the last level has nothing to count down
+func (g *LiveGame) tickEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+			status, err := g.status()
+			if err != nil || status.Paused || status.NextLevelIn <= 0 {
+				continue
+			}
+			g.Announce(NewMessage(TickMessage, TickPayload{NextLevelIn: status.NextLevelIn.Round(time.Second)}))
+		}
+	}
+}
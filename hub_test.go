@@ -0,0 +1,256 @@
+package poker_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestGameHub(t *testing.T) {
+	status := poker.GameStatus{
+		League:           "friday",
+		Level:            poker.BlindLevel{SmallBlind: 100, BigBlind: 200},
+		NextLevelIn:      5 * time.Minute,
+		PlayersRemaining: 8,
+	}
+	statusOf := func(status poker.GameStatus) func() (poker.GameStatus, error) {
+		return func() (poker.GameStatus, error) { return status, nil }
+	}
+	notStarted := func() (poker.GameStatus, error) { return poker.GameStatus{}, poker.ErrGameNotStarted }
+	level := poker.NewMessage(poker.BlindLevelMessage, status.Level)
+
+	t.Run("announcements go to every subscriber, numbered", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(notStarted)
+
+		first, second := newSpySubscriber(), newSpySubscriber()
+		game.Subscribe(first)
+		game.Subscribe(second)
+
+		game.Announce(level)
+		game.Announce(level)
+
+		for _, subscriber := range []spySubscriber{first, second} {
+			assertSeq(t, subscriber.assertMessage(t, poker.BlindLevelMessage), 1)
+			assertSeq(t, subscriber.assertMessage(t, poker.BlindLevelMessage), 2)
+		}
+	})
+
+	t.Run("text written to the game is announced", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(notStarted)
+
+		subscriber := newSpySubscriber()
+		game.Subscribe(subscriber)
+		fmt.Fprint(game, "Shuffle up and deal")
+
+		var text poker.TextPayload
+		assertPayload(t, subscriber.assertMessage(t, poker.TextMessage), &text)
+		if text.Text != "Shuffle up and deal" {
+			t.Errorf("got text %q", text.Text)
+		}
+	})
+
+	t.Run("late joiners catch up with where the game is at", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(statusOf(status))
+		game.Announce(level)
+
+		late := newSpySubscriber()
+		game.Subscribe(late)
+
+		message := late.assertMessage(t, poker.StatusMessage)
+		assertSeq(t, message, 1)
+		var got poker.LiveGameStatus
+		assertPayload(t, message, &got)
+		if want := (poker.LiveGameStatus{ID: game.ID, GameStatus: status}); !reflect.DeepEqual(got, want) {
+			t.Errorf("got status %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("resuming sends the messages that were missed", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(statusOf(status))
+		for i := 0; i < 3; i++ {
+			game.Announce(level)
+		}
+
+		resumed := newSpySubscriber()
+		game.Resume(resumed, 1)
+
+		assertSeq(t, resumed.assertMessage(t, poker.BlindLevelMessage), 2)
+		assertSeq(t, resumed.assertMessage(t, poker.BlindLevelMessage), 3)
+		resumed.assertNoMessage(t, 10*time.Millisecond)
+
+		upToDate := newSpySubscriber()
+		game.Resume(upToDate, 3)
+		upToDate.assertNoMessage(t, 10*time.Millisecond)
+	})
+
+	t.Run("resuming from a message no longer kept catches up with the status", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(statusOf(status))
+		for i := 0; i < 150; i++ {
+			game.Announce(level)
+		}
+
+		resumed := newSpySubscriber()
+		game.Resume(resumed, 10)
+
+		assertSeq(t, resumed.assertMessage(t, poker.StatusMessage), 150)
+		resumed.assertNoMessage(t, 10*time.Millisecond)
+	})
+
+	t.Run("ticks count down to the next level", func(t *testing.T) {
+		hub := poker.NewGameHub(5 * time.Millisecond)
+		game := hub.Open(statusOf(status))
+
+		subscriber := newSpySubscriber()
+		game.Subscribe(subscriber)
+		subscriber.assertMessage(t, poker.StatusMessage)
+
+		var tick poker.TickPayload
+		assertPayload(t, subscriber.assertMessage(t, poker.TickMessage), &tick)
+		if tick.NextLevelIn != 5*time.Minute {
+			t.Errorf("got next level in %v want 5m", tick.NextLevelIn)
+		}
+	})
+
+	t.Run("no ticks while paused", func(t *testing.T) {
+		paused := status
+		paused.Paused = true
+		hub := poker.NewGameHub(time.Millisecond)
+		game := hub.Open(statusOf(paused))
+
+		subscriber := newSpySubscriber()
+		game.Subscribe(subscriber)
+		subscriber.assertMessage(t, poker.StatusMessage)
+
+		subscriber.assertNoMessage(t, 20*time.Millisecond)
+	})
+
+	t.Run("closing sends the result and ends every subscription", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(notStarted)
+
+		subscriber := newSpySubscriber()
+		done, _ := game.Subscribe(subscriber)
+
+		hub.Close(game.ID, poker.NewMessage(poker.ResultMessage, poker.PlayerPayload{Player: "Andre"}))
+
+		subscriber.assertMessage(t, poker.ResultMessage)
+		assertClosed(t, done)
+		if _, err := hub.Get(game.ID); !errors.Is(err, poker.ErrGameNotFound) {
+			t.Errorf("got %v want %v", err, poker.ErrGameNotFound)
+		}
+
+		done, _ = game.Subscribe(newSpySubscriber())
+		assertClosed(t, done)
+	})
+
+	t.Run("subscribers that can't be sent to are dropped", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(notStarted)
+
+		done, _ := game.Subscribe(failingSubscriber{})
+		game.Announce(level)
+
+		assertClosed(t, done)
+	})
+
+	t.Run("starting a game ends the others", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		old := hub.Open(notStarted)
+		subscriber := newSpySubscriber()
+		done, _ := old.Subscribe(subscriber)
+
+		current := hub.Open(notStarted)
+		hub.CloseOthers(current.ID)
+
+		subscriber.assertMessage(t, poker.EndedMessage)
+		assertClosed(t, done)
+		if _, err := hub.Get(current.ID); err != nil {
+			t.Errorf("the current game was closed too, %v", err)
+		}
+	})
+
+	t.Run("lists the games that started", func(t *testing.T) {
+		hub := poker.NewGameHub(time.Hour)
+		game := hub.Open(statusOf(status))
+		hub.Open(notStarted)
+
+		got := hub.Games()
+		want := []poker.LiveGameStatus{{ID: game.ID, GameStatus: status}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got games %+v want %+v", got, want)
+		}
+	})
+}
+
+// spySubscriber hands every message over on a channel, so the ticks can be waited for
+type spySubscriber chan poker.Message
+
+func newSpySubscriber() spySubscriber {
+	return make(spySubscriber, 16)
+}
+
+func (s spySubscriber) Send(message poker.Message) error {
+	s <- message
+	return nil
+}
+
+func (s spySubscriber) assertMessage(t testing.TB, want poker.MessageType) poker.Message {
+	t.Helper()
+	select {
+	case got := <-s:
+		if got.Type != want {
+			t.Errorf("got message %s want %s", got.Type, want)
+		}
+		return got
+	case <-time.After(100 * time.Millisecond):
+		t.Fatalf("timed out waiting for %s", want)
+	}
+	return poker.Message{}
+}
+
+func (s spySubscriber) assertNoMessage(t testing.TB, wait time.Duration) {
+	t.Helper()
+	select {
+	case got := <-s:
+		t.Errorf("got message %s want none", got.Type)
+	case <-time.After(wait):
+	}
+}
+
+type failingSubscriber struct{}
+
+func (failingSubscriber) Send(message poker.Message) error {
+	return errors.New("connection lost")
+}
+
+func assertSeq(t testing.TB, message poker.Message, want int64) {
+	t.Helper()
+	if message.Seq != want {
+		t.Errorf("got %s numbered %d want %d", message.Type, message.Seq, want)
+	}
+}
+
+func assertPayload(t testing.TB, message poker.Message, payload any) {
+	t.Helper()
+	if err := message.DecodePayload(payload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertClosed(t testing.TB, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("the subscription didn't end")
+	}
+}
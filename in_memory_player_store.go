@@ -1,6 +1,9 @@
 package poker
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 /*
 This store is not being used.
@@ -8,34 +11,171 @@ It was used to help build the server code with a dummy db store.
 */
 
 type InMemoryPlayerStore struct {
-	lock   sync.RWMutex
-	scores map[string]int
+	lock    sync.RWMutex
+	leagues leagueSet
+	results []GameResult
+	active  *ActiveGame
 }
 
 func NewInMemoryPlayerStore() *InMemoryPlayerStore {
-	return &InMemoryPlayerStore{sync.RWMutex{}, map[string]int{}}
+	return &InMemoryPlayerStore{leagues: newLeagueSet(nil)}
 }
 
-func (s *InMemoryPlayerStore) GetPlayerScore(playerName string) int {
+func (s *InMemoryPlayerStore) GetPlayerScore(ctx context.Context, leagueID, playerName string) (int, error) {
+	league, err := s.GetLeague(ctx, leagueID)
+	if err != nil {
+		return 0, err
+	}
+
+	player := league.Find(playerName)
+	if player != nil {
+		return player.Wins, nil
+	}
+	return 0, nil
+}
+
+func (s *InMemoryPlayerStore) RecordWin(ctx context.Context, leagueID, playerName string) error {
+	return s.RecordResult(ctx, winResult(leagueID, playerName))
+}
+
+func (s *InMemoryPlayerStore) GetLeague(ctx context.Context, leagueID string) (League, error) {
+	return s.GetSeasonLeague(ctx, leagueID, AllTime())
+}
+
+func (s *InMemoryPlayerStore) GetSeasonLeague(ctx context.Context, leagueID string, season Season) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.lock.RLock()
 	defer s.lock.RUnlock()
-	return s.scores[playerName]
+
+	league, err := s.leagues.get(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return NewLeagueFromResults(resultsInSeason(s.results, leagueID, season), league.ScoringRule()), nil
 }
 
-func (s *InMemoryPlayerStore) RecordWin(playerName string) {
+func (s *InMemoryPlayerStore) GetLeagueResults(ctx context.Context, leagueID string, season Season) ([]GameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if _, err := s.leagues.get(leagueID); err != nil {
+		return nil, err
+	}
+	return resultsInSeason(s.results, leagueID, season), nil
+}
+
+func (s *InMemoryPlayerStore) RecordResult(ctx context.Context, result GameResult) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result, err := newGameResult(result)
+	if err != nil {
+		return err
+	}
+
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	s.scores[playerName]++
+
+	if err := s.leagues.checkOpen(result.League); err != nil {
+		return err
+	}
+	if err := checkNotRecorded(s.results, result.ID); err != nil {
+		return err
+	}
+	s.results = append(s.results, result)
+	return nil
+}
+
+func (s *InMemoryPlayerStore) GetResults(ctx context.Context) ([]GameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]GameResult(nil), s.results...), nil
 }
 
-func (s *InMemoryPlayerStore) GetLeague() League {
-	// pre allocate slice size for efficiency
-	league := make([]Player, len(s.scores))
-	i := 0
-	for name, wins := range s.scores {
-		league[i] = Player{name, wins}
-		i++
+func (s *InMemoryPlayerStore) GetPlayerResults(ctx context.Context, playerName string) ([]GameResult, error) {
+	results, err := s.GetResults(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return ResultsForPlayer(results, playerName), nil
+}
 
-	return league
+func (s *InMemoryPlayerStore) CreateLeague(ctx context.Context, league LeagueInfo) (LeagueInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return LeagueInfo{}, err
+	}
+
+	league, err := newLeagueInfo(league)
+	if err != nil {
+		return LeagueInfo{}, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	leagues, err := s.leagues.add(league)
+	if err != nil {
+		return LeagueInfo{}, err
+	}
+	s.leagues = leagues
+	return league, nil
+}
+
+func (s *InMemoryPlayerStore) GetLeagues(ctx context.Context) ([]LeagueInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]LeagueInfo(nil), s.leagues...), nil
+}
+
+func (s *InMemoryPlayerStore) ArchiveLeague(ctx context.Context, leagueID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	leagues, err := s.leagues.archive(leagueID)
+	if err != nil {
+		return err
+	}
+	s.leagues = leagues
+	return nil
+}
+
+func (s *InMemoryPlayerStore) SaveActiveGame(ctx context.Context, game *ActiveGame) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.active = game.copy()
+	return nil
+}
+
+func (s *InMemoryPlayerStore) GetActiveGame(ctx context.Context) (*ActiveGame, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.active.copy(), nil
 }
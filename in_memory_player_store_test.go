@@ -0,0 +1,14 @@
+package poker_test
+
+import (
+	"testing"
+
+	"github.com/andremfp/poker-app"
+	"github.com/andremfp/poker-app/playerstoretest"
+)
+
+func TestInMemoryPlayerStore(t *testing.T) {
+	playerstoretest.RunConformance(t, func(t *testing.T) (poker.PlayerStore, func() poker.PlayerStore) {
+		return poker.NewInMemoryPlayerStore(), nil
+	})
+}
@@ -0,0 +1,167 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultLeagueID is the league of games played without choosing one, and of everything recorded before there were leagues
+const DefaultLeagueID = "default"
+
+var (
+	ErrLeagueNotFound = errors.New("league not found")
+	ErrLeagueExists   = errors.New("league already exists")
+	ErrLeagueArchived = errors.New("league is archived")
+	ErrInvalidLeague  = errors.New("invalid league")
+)
+
+// LeagueInfo is a group of players with its own standings, e.g. friday night or lunchtime games.
+// Archived leagues keep their standings but no longer take results.
+type LeagueInfo struct {
+	ID       string
+	Name     string
+	Created  time.Time
+	Archived bool
+	// name of the scoring rule the standings are ranked by, DefaultScoring when empty
+	Scoring string
+}
+
+func DefaultLeague() LeagueInfo {
+	return LeagueInfo{ID: DefaultLeagueID, Name: "Default", Scoring: DefaultScoring}
+}
+
+// ScoringRule is the rule the standings of the league are ranked by
+func (l LeagueInfo) ScoringRule() ScoringRule {
+	rule, err := GetScoringRule(l.Scoring)
+	if err != nil {
+		// leagues are checked when they are created, so only a rule that was removed since gets here
+		rule, _ = GetScoringRule(DefaultScoring)
+	}
+	return rule
+}
+
+// LeagueID turns a league name into the id used in urls, "Friday Night" is "friday-night"
+func LeagueID(name string) string {
+	var id strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && id.Len() > 0 {
+				id.WriteRune('-')
+			}
+			id.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return id.String()
+}
+
+// newLeagueInfo is the league stores create from the name and scoring rule asked for
+func newLeagueInfo(league LeagueInfo) (LeagueInfo, error) {
+	name := strings.TrimSpace(league.Name)
+	id := LeagueID(name)
+	if id == "" {
+		return LeagueInfo{}, fmt.Errorf("%w, %q needs a letter or digit in its name", ErrInvalidLeague, name)
+	}
+
+	scoring := league.Scoring
+	if scoring == "" {
+		scoring = DefaultScoring
+	}
+	if _, err := GetScoringRule(scoring); err != nil {
+		return LeagueInfo{}, err
+	}
+
+	return LeagueInfo{ID: id, Name: name, Created: time.Now().UTC(), Scoring: scoring}, nil
+}
+
+// leagueSet is the leagues of a store that keeps them in memory, the default league is always there
+type leagueSet []LeagueInfo
+
+func newLeagueSet(leagues []LeagueInfo) leagueSet {
+	for _, league := range leagues {
+		if league.ID == DefaultLeagueID {
+			return leagues
+		}
+	}
+	return append(leagueSet{DefaultLeague()}, leagues...)
+}
+
+func (l leagueSet) get(id string) (LeagueInfo, error) {
+	for _, league := range l {
+		if league.ID == id {
+			return league, nil
+		}
+	}
+	return LeagueInfo{}, fmt.Errorf("%w: %s", ErrLeagueNotFound, id)
+}
+
+// checkOpen fails unless results can be recorded in the league
+func (l leagueSet) checkOpen(id string) error {
+	league, err := l.get(id)
+	if err != nil {
+		return err
+	}
+	if league.Archived {
+		return fmt.Errorf("%w: %s", ErrLeagueArchived, id)
+	}
+	return nil
+}
+
+// add returns a copy of the set with the league in it
+func (l leagueSet) add(league LeagueInfo) (leagueSet, error) {
+	if _, err := l.get(league.ID); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrLeagueExists, league.ID)
+	}
+	return append(l[:len(l):len(l)], league), nil
+}
+
+// archive returns a copy of the set with the league archived, archiving twice is fine
+func (l leagueSet) archive(id string) (leagueSet, error) {
+	if id == DefaultLeagueID {
+		return nil, fmt.Errorf("%w, the default league can't be archived", ErrInvalidLeague)
+	}
+	if _, err := l.get(id); err != nil {
+		return nil, err
+	}
+
+	archived := make(leagueSet, len(l))
+	copy(archived, l)
+	for i := range archived {
+		if archived[i].ID == id {
+			archived[i].Archived = true
+		}
+	}
+	return archived, nil
+}
+
+// ResultsForLeague returns the results of the games played in the league
+func ResultsForLeague(results []GameResult, leagueID string) []GameResult {
+	var played []GameResult
+	for _, result := range results {
+		if result.League == leagueID {
+			played = append(played, result)
+		}
+	}
+	return played
+}
+
+// standingsByLeague builds the standings of every league that has results, each ranked by its own scoring rule
+func standingsByLeague(leagues leagueSet, results []GameResult) map[string]League {
+	byLeague := map[string][]GameResult{}
+	for _, result := range results {
+		byLeague[result.League] = append(byLeague[result.League], result)
+	}
+
+	standings := make(map[string]League, len(byLeague))
+	for id, played := range byLeague {
+		league, _ := leagues.get(id)
+		standings[id] = NewLeagueFromResults(played, league.ScoringRule())
+	}
+	return standings
+}
@@ -0,0 +1,200 @@
+package poker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// MessageType says what a Message is about and what its payload is
+type MessageType string
+
+// sent by the host of a game
+const (
+	// StartPayload
+	StartMessage  MessageType = "start"
+	PauseMessage  MessageType = "pause"
+	ResumeMessage MessageType = "resume"
+	SkipMessage   MessageType = "skip"
+	// PlayerPayload
+	EliminateMessage MessageType = "eliminate"
+	// PlayerPayload
+	WinnerMessage MessageType = "winner"
+	// PlayerPayload
+	RebuyMessage MessageType = "rebuy"
+	// PlayerPayload
+	AddOnMessage MessageType = "add-on"
+	// Entrant, a player joining while late registration is open
+	RegisterMessage MessageType = "register"
+	// RejoinPayload, instead of start to get back to a game after losing the connection
+	RejoinMessage MessageType = "rejoin"
+)
+
+// sent to everyone following a game
+const (
+	// LiveGameStatus, once the game started
+	StartedMessage MessageType = "started"
+	// LiveGameStatus, where the game is at for those joining halfway through
+	StatusMessage MessageType = "status"
+	// BlindLevel
+	BlindLevelMessage MessageType = "blind-level"
+	PausedMessage     MessageType = "paused"
+	ResumedMessage    MessageType = "resumed"
+	// TickPayload
+	TickMessage MessageType = "tick"
+	// EliminatedPayload
+	EliminatedMessage MessageType = "eliminated"
+	// EntryPayload, a rebuy, add-on or late registration
+	EntryMessage MessageType = "entry"
+	// SeatPayload, where a player of a tournament sits at the start or moves to
+	SeatMessage MessageType = "seat"
+	// PlayerPayload with the winner, the last message of a game
+	ResultMessage MessageType = "result"
+	// the last message of a game that was replaced before it had a result
+	EndedMessage MessageType = "ended"
+	// TextPayload, anything written to the game that isn't one of the other messages
+	TextMessage MessageType = "text"
+	// ErrorPayload, only sent to the host whose message it answers
+	ErrorMessage MessageType = "error"
+)
+
+// ErrInvalidMessage is wrapped by the errors of messages that can't be understood or acted on
+var ErrInvalidMessage = errors.New("invalid message")
+
+// Message is the json envelope of everything sent over the websocket
+type Message struct {
+	Type MessageType
+	// numbers the messages of a game in the order they were sent, the host numbers their own
+	Seq     int64
+	Payload json.RawMessage `json:",omitempty"`
+}
+
+// NewMessage puts the payload in an envelope, the sequence number is set when the message is sent
+func NewMessage(messageType MessageType, payload any) Message {
+	message := Message{Type: messageType}
+	if payload != nil {
+		// the payloads are plain structs that always marshal
+		message.Payload, _ = json.Marshal(payload)
+	}
+	return message
+}
+
+// DecodePayload unmarshals the payload into v, wrapping ErrInvalidMessage when it doesn't fit
+func (m Message) DecodePayload(v any) error {
+	if len(m.Payload) == 0 {
+		return fmt.Errorf("%w, %s has no payload", ErrInvalidMessage, m.Type)
+	}
+	if err := json.Unmarshal(m.Payload, v); err != nil {
+		return fmt.Errorf("%w, could not parse the payload of %s, %v", ErrInvalidMessage, m.Type, err)
+	}
+	return nil
+}
+
+// StartPayload are the settings of the game to start
+type StartPayload GameSettings
+
+func (p StartPayload) Validate() error {
+	if err := GameSettings(p).Validate(); err != nil {
+		return fmt.Errorf("%w, %v", ErrInvalidMessage, err)
+	}
+	return nil
+}
+
+type RejoinPayload struct {
+	Game string
+	// the last message received, the ones after it are sent again
+	After int64
+}
+
+type PlayerPayload struct {
+	Player string
+}
+
+type EliminatedPayload struct {
+	Player           string
+	Position         int
+	PlayersRemaining int
+}
+
+type EntryPayload struct {
+	Player string
+	// RebuyMessage, AddOnMessage or RegisterMessage
+	Entry            MessageType
+	PrizePool        int
+	PlayersRemaining int
+}
+
+type SeatPayload struct {
+	Player string
+	// numbered from 1
+	Table int
+	Seat  int
+	// the player moved from another table rather than took their seat at the start
+	Moved bool `json:",omitempty"`
+}
+
+type TickPayload struct {
+	NextLevelIn time.Duration
+}
+
+type TextPayload struct {
+	Text string
+}
+
+// codes of the errors pages act on rather than only show
+const (
+	// the game isn't being played, the server may have restarted and carried on with it under a new id
+	GameNotFoundCode = "game-not-found"
+)
+
+type ErrorPayload struct {
+	Error string
+	// one of the error codes, empty for errors that are only shown
+	Code string `json:",omitempty"`
+	// the sequence number of the message that went wrong
+	ReplyTo int64
+}
+
+// Announcer is an alerts destination that takes the messages of the game as they are rather than as text
+type Announcer interface {
+	Announce(message Message)
+}
+
+// announceLevel tells out the level started, as a message when out is an Announcer and as text otherwise
+func announceLevel(out io.Writer, level BlindLevel) {
+	if announcer, ok := out.(Announcer); ok {
+		announcer.Announce(NewMessage(BlindLevelMessage, level))
+		return
+	}
+	fmt.Fprint(out, levelMessage(level))
+}
+
+func announceClock(out io.Writer, paused bool) {
+	if announcer, ok := out.(Announcer); ok {
+		if paused {
+			announcer.Announce(NewMessage(PausedMessage, nil))
+		} else {
+			announcer.Announce(NewMessage(ResumedMessage, nil))
+		}
+		return
+	}
+	if paused {
+		fmt.Fprintln(out, "Clock paused")
+	} else {
+		fmt.Fprintln(out, "Clock resumed")
+	}
+}
+
+func announceSeat(out io.Writer, seat SeatPayload) {
+	if announcer, ok := out.(Announcer); ok {
+		announcer.Announce(NewMessage(SeatMessage, seat))
+		return
+	}
+	if seat.Moved {
+		fmt.Fprintf(out, "%s move to table %d seat %d\n", seat.Player, seat.Table, seat.Seat)
+	} else {
+		fmt.Fprintf(out, "%s sit at table %d seat %d\n", seat.Player, seat.Table, seat.Seat)
+	}
+}
@@ -0,0 +1,16 @@
+package poker
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPIDocument describes every route of the versioned api
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+func (p *PlayerServer) showOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", JsonContentType)
+	w.Write(openAPIDocument)
+}
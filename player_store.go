@@ -0,0 +1,42 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpenPlayerStore opens the store described by spec as kind:path, e.g. "json:game.db.json", "sqlite:game.db"
+// or "events:game.events.jsonl".
+// A spec without a kind is a path to a json file.
+func OpenPlayerStore(spec string) (PlayerStore, func(), error) {
+	kind, path, found := strings.Cut(spec, ":")
+	if !found {
+		kind, path = "json", spec
+	}
+	if path == "" {
+		return nil, nil, fmt.Errorf("store %q has no path", spec)
+	}
+
+	switch kind {
+	case "json":
+		store, closeFunc, err := FsPlayerStoreFromFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	case "sqlite":
+		store, closeFunc, err := SQLitePlayerStoreFromFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	case "events":
+		store, closeFunc, err := EventLogPlayerStoreFromFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown store %q in %q, use json:path, sqlite:path or events:path", kind, spec)
+}
@@ -0,0 +1,43 @@
+package poker_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestOpenPlayerStore(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"json:" + filepath.Join(dir, "game.db.json"), "*poker.FsPlayerStore"},
+		{filepath.Join(dir, "other.db.json"), "*poker.FsPlayerStore"},
+		{"sqlite:" + filepath.Join(dir, "game.db"), "*poker.SQLitePlayerStore"},
+		{"events:" + filepath.Join(dir, "game.events.jsonl"), "*poker.EventLogPlayerStore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			store, closeStore, err := poker.OpenPlayerStore(tt.spec)
+			assertNoError(t, err)
+			defer closeStore()
+
+			if got := fmt.Sprintf("%T", store); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, spec := range []string{"mongo:game", "sqlite:"} {
+		t.Run("invalid "+spec, func(t *testing.T) {
+			if _, _, err := poker.OpenPlayerStore(spec); err == nil {
+				t.Errorf("expected an error opening %q", spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxPlayerNameLength = 64
+
+var ErrInvalidPlayerName = errors.New("invalid player name")
+
+// ValidatePlayerName checks a name can be used to record games and in urls
+func ValidatePlayerName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w, the name is empty", ErrInvalidPlayerName)
+	case strings.TrimSpace(name) != name:
+		return fmt.Errorf("%w, %q starts or ends with spaces", ErrInvalidPlayerName, name)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("%w, %q is not valid utf-8", ErrInvalidPlayerName, name)
+	case utf8.RuneCountInString(name) > MaxPlayerNameLength:
+		return fmt.Errorf("%w, %q is longer than %d characters", ErrInvalidPlayerName, name, MaxPlayerNameLength)
+	case strings.ContainsRune(name, '/'):
+		return fmt.Errorf("%w, %q has a /", ErrInvalidPlayerName, name)
+	}
+
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("%w, %q has unprintable characters", ErrInvalidPlayerName, name)
+		}
+	}
+	return nil
+}
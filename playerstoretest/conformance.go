@@ -0,0 +1,619 @@
+// Package playerstoretest holds the behaviour every poker.PlayerStore has to follow.
+// Backends run it from their own tests:
+//
+//	func TestMyStore(t *testing.T) {
+//		playerstoretest.RunConformance(t, func(t *testing.T) (poker.PlayerStore, func() poker.PlayerStore) {
+//			...
+//		})
+//	}
+package playerstoretest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+// Factory returns a new, empty store. The reopen func returns another store over the same storage
+// so RunConformance can check what was recorded survives, stores that don't persist return a nil reopen.
+type Factory func(t *testing.T) (store poker.PlayerStore, reopen func() poker.PlayerStore)
+
+// RunConformance runs the PlayerStore contract against stores created by newStore
+func RunConformance(t *testing.T, newStore Factory) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("unknown player has no score", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		assertScore(t, store, "Andre", 0)
+	})
+
+	t.Run("record win for new player", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		assertNoError(t, store.RecordWin(ctx, poker.DefaultLeagueID, "Andre"))
+
+		assertScore(t, store, "Andre", 1)
+	})
+
+	t.Run("record win for existing players", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		recordWins(t, store, "Andre", 10)
+		recordWins(t, store, "Chris", 3)
+		assertNoError(t, store.RecordWin(ctx, poker.DefaultLeagueID, "Andre"))
+
+		assertScore(t, store, "Andre", 11)
+		assertScore(t, store, "Chris", 3)
+	})
+
+	t.Run("league is empty without results", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		if league := getLeague(t, store, poker.DefaultLeagueID); len(league) != 0 {
+			t.Errorf("got league %v, want it empty", league)
+		}
+	})
+
+	t.Run("league is sorted by wins then name", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		recordWins(t, store, "John", 2)
+		recordWins(t, store, "Andre", 1)
+		recordWins(t, store, "Chris", 5)
+		recordWins(t, store, "Bob", 2)
+
+		want := poker.League{
+			{Name: "Chris", Wins: 5, Points: 5, Played: 5, AverageFinish: 1},
+			{Name: "Bob", Wins: 2, Points: 2, Played: 2, AverageFinish: 1},
+			{Name: "John", Wins: 2, Points: 2, Played: 2, AverageFinish: 1},
+			{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+		}
+		assertLeague(t, getLeague(t, store, poker.DefaultLeagueID), want)
+
+		// read again
+		assertLeague(t, getLeague(t, store, poker.DefaultLeagueID), want)
+	})
+
+	t.Run("league includes players that finished without winning", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		assertNoError(t, store.RecordResult(ctx, result("game-1", "Andre", "Chris")))
+
+		assertLeague(t, getLeague(t, store, poker.DefaultLeagueID), poker.League{
+			{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+			{Name: "Chris", Wins: 0, Points: 0, Played: 1, AverageFinish: 2},
+		})
+	})
+
+	t.Run("record and query results", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		first := result("game-1", "Andre", "Chris", "John")
+		second := result("game-2", "Chris", "Andre")
+		assertNoError(t, store.RecordResult(ctx, first))
+		assertNoError(t, store.RecordResult(ctx, second))
+
+		assertResults(t, getResults(t, store), []poker.GameResult{first, second})
+		assertResults(t, getPlayerResults(t, store, "John"), []poker.GameResult{first})
+		assertResults(t, getPlayerResults(t, store, "Bob"), nil)
+		assertScore(t, store, "Chris", 1)
+	})
+
+	t.Run("results are stored with their finishers in position order", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		recorded := result("game-1", "Andre", "Chris", "John")
+		recorded.Finishers[0], recorded.Finishers[2] = recorded.Finishers[2], recorded.Finishers[0]
+		assertNoError(t, store.RecordResult(ctx, recorded))
+
+		assertResults(t, getResults(t, store), []poker.GameResult{result("game-1", "Andre", "Chris", "John")})
+	})
+
+	t.Run("results get an id and finish time", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		assertNoError(t, store.RecordResult(ctx, poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}}))
+
+		results := getResults(t, store)
+		if len(results) != 1 {
+			t.Fatalf("got %d results, want 1", len(results))
+		}
+		if results[0].ID == "" || results[0].Finished.IsZero() {
+			t.Errorf("got result %+v, want an id and finish time", results[0])
+		}
+	})
+
+	t.Run("invalid results are not recorded", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		err := store.RecordResult(ctx, poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 0}}})
+		if !errors.Is(err, poker.ErrInvalidGameResult) {
+			t.Fatalf("got error %v, want %v", err, poker.ErrInvalidGameResult)
+		}
+
+		assertResults(t, getResults(t, store), nil)
+		assertScore(t, store, "Andre", 0)
+	})
+
+	t.Run("a result is only recorded once", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		recorded := result("game-1", "Andre", "Chris")
+		assertNoError(t, store.RecordResult(ctx, recorded))
+		err := store.RecordResult(ctx, result("game-1", "Chris", "Andre"))
+		if !errors.Is(err, poker.ErrInvalidGameResult) {
+			t.Fatalf("got error %v, want %v", err, poker.ErrInvalidGameResult)
+		}
+
+		assertResults(t, getResults(t, store), []poker.GameResult{recorded})
+		assertScore(t, store, "Andre", 1)
+		assertScore(t, store, "Chris", 0)
+	})
+
+	t.Run("nothing is recorded with a cancelled context", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if err := store.RecordWin(cancelled, poker.DefaultLeagueID, "Andre"); err == nil {
+			t.Fatal("expected an error but didn't get one")
+		}
+		if err := store.RecordResult(cancelled, result("game-1", "Andre", "Chris")); err == nil {
+			t.Fatal("expected an error but didn't get one")
+		}
+
+		assertResults(t, getResults(t, store), nil)
+	})
+
+	t.Run("concurrent writes are all recorded", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		const writers = 50
+		var wg sync.WaitGroup
+		wg.Add(writers)
+		for i := 0; i < writers; i++ {
+			go func() {
+				defer wg.Done()
+				if err := store.RecordWin(ctx, poker.DefaultLeagueID, "Andre"); err != nil {
+					t.Errorf("could not record win, %v", err)
+				}
+				store.GetLeague(ctx, poker.DefaultLeagueID)
+				store.GetPlayerScore(ctx, poker.DefaultLeagueID, "Andre")
+			}()
+		}
+		wg.Wait()
+
+		assertScore(t, store, "Andre", writers)
+	})
+
+	t.Run("there is always a default league", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		leagues := getLeagues(t, store)
+		if len(leagues) != 1 || leagues[0].ID != poker.DefaultLeagueID || leagues[0].Archived {
+			t.Errorf("got leagues %+v, want just the open default league", leagues)
+		}
+	})
+
+	t.Run("leagues keep their own standings", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		friday := createLeague(t, store, "Friday Night")
+		if friday.ID != "friday-night" || friday.Name != "Friday Night" || friday.Created.IsZero() {
+			t.Errorf("got league %+v, want id friday-night named Friday Night with a creation time", friday)
+		}
+
+		assertNoError(t, store.RecordWin(ctx, friday.ID, "Chris"))
+		inFriday := result("game-1", "Andre", "Chris")
+		inFriday.League = friday.ID
+		assertNoError(t, store.RecordResult(ctx, inFriday))
+		recordWins(t, store, "Andre", 3)
+
+		// tied on points and wins, Andre has the better average finish
+		assertLeague(t, getLeague(t, store, friday.ID), poker.League{
+			{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+			{Name: "Chris", Wins: 1, Points: 1, Played: 2, AverageFinish: 1.5},
+		})
+		assertLeague(t, getLeague(t, store, poker.DefaultLeagueID), poker.League{
+			{Name: "Andre", Wins: 3, Points: 3, Played: 3, AverageFinish: 1},
+		})
+		assertScore(t, store, "Chris", 0)
+
+		score, err := store.GetPlayerScore(ctx, friday.ID, "Chris")
+		assertNoError(t, err)
+		if score != 1 {
+			t.Errorf("got score of %d for Chris in %s, wanted 1", score, friday.ID)
+		}
+
+		// results still cover every league
+		if got := len(getPlayerResults(t, store, "Andre")); got != 4 {
+			t.Errorf("got %d results for Andre, want 4", got)
+		}
+
+		// a league with no results has empty standings
+		createLeague(t, store, "Lunchtime")
+		if league := getLeague(t, store, "lunchtime"); league == nil || len(league) != 0 {
+			t.Errorf("got league %#v, want it empty", league)
+		}
+	})
+
+	t.Run("leagues rank by their scoring rule", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		if online := createLeague(t, store, "Online"); online.Scoring != poker.DefaultScoring {
+			t.Errorf("got league %+v, want it scored by %s", online, poker.DefaultScoring)
+		}
+
+		f1 := createLeagueScoredBy(t, store, "F1", poker.FormulaOneScoring)
+		for _, played := range []poker.GameResult{
+			result("game-1", "Andre", "Chris", "John"),
+			result("game-2", "John", "Chris", "Andre"),
+			result("game-3", "Bob", "Chris"),
+		} {
+			played.League = f1.ID
+			assertNoError(t, store.RecordResult(ctx, played))
+		}
+
+		// Chris never won but finishing second every time is worth the most
+		assertLeague(t, getLeague(t, store, f1.ID), poker.League{
+			{Name: "Chris", Wins: 0, Points: 54, Played: 3, AverageFinish: 2},
+			{Name: "Andre", Wins: 1, Points: 40, Played: 2, AverageFinish: 2},
+			{Name: "John", Wins: 1, Points: 40, Played: 2, AverageFinish: 2},
+			{Name: "Bob", Wins: 1, Points: 25, Played: 1, AverageFinish: 1},
+		})
+
+		score, err := store.GetPlayerScore(ctx, f1.ID, "Chris")
+		assertNoError(t, err)
+		if score != 0 {
+			t.Errorf("got score of %d for Chris, the score is still the wins, want 0", score)
+		}
+	})
+
+	t.Run("seasons have the standings and results of the games that finished in them", func(t *testing.T) {
+		store, _ := newStore(t)
+		online := createLeague(t, store, "Online")
+
+		seasons := poker.SeasonCalendar{Length: poker.Quarterly}
+		q1, err := seasons.Season("2024-Q1")
+		assertNoError(t, err)
+
+		finishedAt := func(id, winner, leagueID string, finished time.Time) poker.GameResult {
+			r := result(id, winner)
+			r.League = leagueID
+			r.Started, r.Finished = finished.Add(-2*time.Hour), finished
+			return r
+		}
+		for _, played := range []poker.GameResult{
+			finishedAt("game-1", "Andre", poker.DefaultLeagueID, q1.Start.Add(500*time.Millisecond)),
+			finishedAt("game-2", "Chris", poker.DefaultLeagueID, q1.End.Add(-100*time.Millisecond)),
+			finishedAt("game-3", "John", poker.DefaultLeagueID, q1.End),
+			finishedAt("game-4", "Bob", online.ID, q1.Start.Add(time.Hour)),
+			finishedAt("game-5", "Andre", poker.DefaultLeagueID, q1.Start.Add(-time.Millisecond)),
+		} {
+			assertNoError(t, store.RecordResult(ctx, played))
+		}
+
+		league, err := store.GetSeasonLeague(ctx, poker.DefaultLeagueID, q1)
+		assertNoError(t, err)
+		assertLeague(t, league, poker.League{
+			{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+			{Name: "Chris", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+		})
+
+		results, err := store.GetLeagueResults(ctx, poker.DefaultLeagueID, q1)
+		assertNoError(t, err)
+		assertResultIDs(t, results, "game-1", "game-2")
+
+		results, err = store.GetLeagueResults(ctx, poker.DefaultLeagueID, poker.AllTime())
+		assertNoError(t, err)
+		assertResultIDs(t, results, "game-1", "game-2", "game-3", "game-5")
+
+		league, err = store.GetSeasonLeague(ctx, poker.DefaultLeagueID, poker.AllTime())
+		assertNoError(t, err)
+		assertLeague(t, league, getLeague(t, store, poker.DefaultLeagueID))
+
+		_, err = store.GetSeasonLeague(ctx, "nowhere", q1)
+		assertErrorIs(t, err, poker.ErrLeagueNotFound)
+		_, err = store.GetLeagueResults(ctx, "nowhere", q1)
+		assertErrorIs(t, err, poker.ErrLeagueNotFound)
+	})
+
+	t.Run("unknown leagues are not found", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		_, err := store.GetLeague(ctx, "nowhere")
+		assertErrorIs(t, err, poker.ErrLeagueNotFound)
+
+		_, err = store.GetPlayerScore(ctx, "nowhere", "Andre")
+		assertErrorIs(t, err, poker.ErrLeagueNotFound)
+
+		assertErrorIs(t, store.RecordWin(ctx, "nowhere", "Andre"), poker.ErrLeagueNotFound)
+		assertErrorIs(t, store.ArchiveLeague(ctx, "nowhere"), poker.ErrLeagueNotFound)
+		assertResults(t, getResults(t, store), nil)
+	})
+
+	t.Run("league names must be unique and usable", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		createLeague(t, store, "Online")
+
+		_, err := store.CreateLeague(ctx, poker.LeagueInfo{Name: " online "})
+		assertErrorIs(t, err, poker.ErrLeagueExists)
+
+		_, err = store.CreateLeague(ctx, poker.LeagueInfo{Name: "Default"})
+		assertErrorIs(t, err, poker.ErrLeagueExists)
+
+		_, err = store.CreateLeague(ctx, poker.LeagueInfo{Name: "  !! "})
+		assertErrorIs(t, err, poker.ErrInvalidLeague)
+
+		_, err = store.CreateLeague(ctx, poker.LeagueInfo{Name: "Golf", Scoring: "strokes"})
+		assertErrorIs(t, err, poker.ErrInvalidLeague)
+
+		if got := len(getLeagues(t, store)); got != 2 {
+			t.Errorf("got %d leagues, want 2", got)
+		}
+	})
+
+	t.Run("archived leagues keep their standings but take no results", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		online := createLeague(t, store, "Online")
+		assertNoError(t, store.RecordWin(ctx, online.ID, "Andre"))
+
+		assertNoError(t, store.ArchiveLeague(ctx, online.ID))
+		// archiving twice is fine
+		assertNoError(t, store.ArchiveLeague(ctx, online.ID))
+
+		assertErrorIs(t, store.RecordWin(ctx, online.ID, "Andre"), poker.ErrLeagueArchived)
+		assertLeague(t, getLeague(t, store, online.ID), poker.League{{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1}})
+
+		leagues := getLeagues(t, store)
+		if len(leagues) != 2 || !leagues[1].Archived {
+			t.Errorf("got leagues %+v, want online archived", leagues)
+		}
+
+		assertErrorIs(t, store.ArchiveLeague(ctx, poker.DefaultLeagueID), poker.ErrInvalidLeague)
+	})
+
+	t.Run("recorded data persists across reopen", func(t *testing.T) {
+		store, reopen := newStore(t)
+		if reopen == nil {
+			t.Skip("store does not persist")
+		}
+
+		recordWins(t, store, "Chris", 2)
+		friday := createLeagueScoredBy(t, store, "Friday", poker.FieldSizeScoring)
+		assertNoError(t, store.RecordWin(ctx, friday.ID, "John"))
+		archived := createLeague(t, store, "Old")
+		assertNoError(t, store.ArchiveLeague(ctx, archived.ID))
+		recorded := result("game-1", "Andre", "Chris")
+		assertNoError(t, store.RecordResult(ctx, recorded))
+
+		reopened := reopen()
+
+		assertScore(t, reopened, "Chris", 2)
+		assertLeague(t, getLeague(t, reopened, poker.DefaultLeagueID), poker.League{
+			{Name: "Chris", Wins: 2, Points: 2, Played: 3, AverageFinish: 1.33},
+			{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+		})
+		assertResults(t, getPlayerResults(t, reopened, "Andre"), []poker.GameResult{recorded})
+		assertLeague(t, getLeague(t, reopened, friday.ID), poker.League{{Name: "John", Wins: 1, Points: 1, Played: 1, AverageFinish: 1}})
+		if leagues := getLeagues(t, reopened); leagues[1].Scoring != poker.FieldSizeScoring {
+			t.Errorf("got league %+v, want it scored by %s", leagues[1], poker.FieldSizeScoring)
+		}
+		assertErrorIs(t, reopened.RecordWin(ctx, archived.ID, "John"), poker.ErrLeagueArchived)
+
+		// and keeps recording on top of what was there
+		assertNoError(t, reopened.RecordWin(ctx, poker.DefaultLeagueID, "Andre"))
+		assertScore(t, reopened, "Andre", 2)
+	})
+
+	t.Run("the game being played is kept until it is cleared", func(t *testing.T) {
+		store, reopen := newStore(t)
+		games, ok := store.(poker.ActiveGameStore)
+		if !ok {
+			t.Skip("store does not keep the game being played")
+		}
+		assertActiveGame(t, games, nil)
+
+		game := activeGame("game-1", "Chris")
+		assertNoError(t, games.SaveActiveGame(ctx, game))
+		assertActiveGame(t, games, game)
+
+		// what was saved isn't changed by the caller changing the game afterwards
+		game.Result.Finishers[0].Name = "John"
+		saved := activeGame("game-1", "Chris")
+		assertActiveGame(t, games, saved)
+
+		if reopen != nil {
+			assertActiveGame(t, reopen().(poker.ActiveGameStore), saved)
+		}
+
+		assertNoError(t, games.SaveActiveGame(ctx, nil))
+		assertActiveGame(t, games, nil)
+		if reopen != nil {
+			assertActiveGame(t, reopen().(poker.ActiveGameStore), nil)
+		}
+	})
+}
+
+// result of a game finished by the players in order, the winner first and the others knocked out 10 minutes apart.
+// The winner bought the add-on and the last player a rebuy.
+func result(id string, players ...string) poker.GameResult {
+	started := time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC)
+	r := poker.GameResult{
+		ID:             id,
+		League:         poker.DefaultLeagueID,
+		Started:        started,
+		Finished:       started.Add(2 * time.Hour),
+		NumPlayers:     len(players),
+		BlindStructure: "standard",
+		BuyIn:          10,
+		AddOnCost:      5,
+	}
+	for i, name := range players {
+		finisher := poker.Finisher{Name: name, Position: i + 1}
+		if i > 0 {
+			eliminated := r.Finished.Add(-time.Duration(i) * 10 * time.Minute)
+			finisher.Eliminated = &eliminated
+		}
+		r.Finishers = append(r.Finishers, finisher)
+	}
+	r.Finishers[0].Payout = 10 * len(players)
+	r.Finishers[0].Knockouts = len(players) - 1
+	r.Finishers[0].AddOn = true
+	if len(players) > 1 {
+		r.Finishers[len(players)-1].Rebuys = 1
+	}
+	return r
+}
+
+// activeGame of Andre, Bob, Chris, Dave and John with the eliminated players out in order, its clock paused in the
+// second level
+func activeGame(id string, eliminated ...string) *poker.ActiveGame {
+	game := &poker.ActiveGame{
+		Result: poker.GameResult{
+			ID:             id,
+			League:         poker.DefaultLeagueID,
+			Started:        time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC),
+			NumPlayers:     5,
+			BlindStructure: "standard",
+		},
+		Clock: poker.ClockState{
+			Levels: []poker.ScheduledLevel{
+				{At: 0, Level: poker.BlindLevel{SmallBlind: 100, BigBlind: 200, Duration: 10 * time.Minute}},
+				{At: 10 * time.Minute, Level: poker.BlindLevel{SmallBlind: 200, BigBlind: 400, Duration: 10 * time.Minute, Notice: "Rebuys are closed."}},
+			},
+			Elapsed:      12 * time.Minute,
+			RunningSince: time.Date(2024, 1, 5, 20, 12, 0, 0, time.UTC),
+			Paused:       true,
+		},
+		Players: []poker.Entrant{
+			{Name: "Andre", Stack: 10000}, {Name: "Bob", Stack: 10000}, {Name: "Chris", Stack: 10000},
+			{Name: "Dave", Stack: 5000, Rebuys: 1}, {Name: "John", Stack: 5000, AddOn: true},
+		},
+		AdaptiveLevels: true,
+		Entries:        poker.EntryRules{RebuyLevels: 1, MaxRebuys: 2, AddOn: true, LateRegistrationLevels: 1},
+	}
+	for i, name := range eliminated {
+		eliminated := game.Clock.RunningSince.Add(-time.Duration(i) * time.Minute)
+		game.Result.Finishers = append(game.Result.Finishers, poker.Finisher{Name: name, Position: 5 - i, Eliminated: &eliminated})
+	}
+	return game
+}
+
+func recordWins(t testing.TB, store poker.PlayerStore, playerName string, wins int) {
+	t.Helper()
+	for i := 0; i < wins; i++ {
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, playerName))
+	}
+}
+
+func getLeague(t testing.TB, store poker.PlayerStore, leagueID string) poker.League {
+	t.Helper()
+	league, err := store.GetLeague(context.Background(), leagueID)
+	assertNoError(t, err)
+	return league
+}
+
+func getResults(t testing.TB, store poker.PlayerStore) []poker.GameResult {
+	t.Helper()
+	results, err := store.GetResults(context.Background())
+	assertNoError(t, err)
+	return results
+}
+
+func getPlayerResults(t testing.TB, store poker.PlayerStore, playerName string) []poker.GameResult {
+	t.Helper()
+	results, err := store.GetPlayerResults(context.Background(), playerName)
+	assertNoError(t, err)
+	return results
+}
+
+func getLeagues(t testing.TB, store poker.PlayerStore) []poker.LeagueInfo {
+	t.Helper()
+	leagues, err := store.GetLeagues(context.Background())
+	assertNoError(t, err)
+	return leagues
+}
+
+func createLeague(t testing.TB, store poker.PlayerStore, name string) poker.LeagueInfo {
+	t.Helper()
+	return createLeagueScoredBy(t, store, name, "")
+}
+
+func createLeagueScoredBy(t testing.TB, store poker.PlayerStore, name, scoring string) poker.LeagueInfo {
+	t.Helper()
+	league, err := store.CreateLeague(context.Background(), poker.LeagueInfo{Name: name, Scoring: scoring})
+	assertNoError(t, err)
+	return league
+}
+
+func assertScore(t testing.TB, store poker.PlayerStore, playerName string, want int) {
+	t.Helper()
+	got, err := store.GetPlayerScore(context.Background(), poker.DefaultLeagueID, playerName)
+	assertNoError(t, err)
+	if got != want {
+		t.Errorf("got score of %d for %s, wanted %d", got, playerName, want)
+	}
+}
+
+func assertLeague(t testing.TB, got, want poker.League) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got league %v, wanted %v", got, want)
+	}
+}
+
+func assertResults(t testing.TB, got, want []poker.GameResult) {
+	t.Helper()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got results %+v, wanted %+v", got, want)
+	}
+}
+
+// assertResultIDs checks the results are the games with the ids, in the order they were recorded
+func assertResultIDs(t testing.TB, got []poker.GameResult, want ...string) {
+	t.Helper()
+	var ids []string
+	for _, result := range got {
+		ids = append(ids, result.ID)
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("got results %v, want %v", ids, want)
+	}
+}
+
+func assertActiveGame(t testing.TB, store poker.ActiveGameStore, want *poker.ActiveGame) {
+	t.Helper()
+	got, err := store.GetActiveGame(context.Background())
+	assertNoError(t, err)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got game being played %+v, want %+v", got, want)
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+}
+
+func assertErrorIs(t testing.TB, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("got error %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,111 @@
+package poker
+
+import (
+	"sort"
+
+	"github.com/andremfp/poker-app/cards"
+)
+
+// Contribution is every chip a player put in during a hand
+type Contribution struct {
+	Player string
+	Amount int
+	// folded players leave their chips in the pots but can't win them
+	Folded bool
+}
+
+// Pot is the main pot or a side pot and the players that can win it, in the order the contributions were given
+type Pot struct {
+	Amount   int
+	Eligible []string
+}
+
+// PotShare is what a player won of the pot at index Pot, the main pot being 0
+type PotShare struct {
+	Pot    int
+	Player string
+	Amount int
+}
+
+// BuildPots splits the contributions into the main pot and a side pot for each player that went all in for less than
+// the others. Every player still in can win the chips up to what they put in from each of the others. When everyone
+// that put chips in folded, the chips are a single pot for the players still in, none are eligible if no one is.
+func BuildPots(contributions []Contribution) []Pot {
+	var levels []int
+	folded := Pot{}
+	for _, contribution := range contributions {
+		if !contribution.Folded && contribution.Amount > 0 {
+			levels = append(levels, contribution.Amount)
+		}
+		folded.Amount += contribution.Amount
+		if !contribution.Folded {
+			folded.Eligible = append(folded.Eligible, contribution.Player)
+		}
+	}
+	if len(levels) == 0 {
+		if folded.Amount == 0 {
+			return nil
+		}
+		return []Pot{folded}
+	}
+	sort.Ints(levels)
+
+	var pots []Pot
+	previous := 0
+	for _, level := range levels {
+		if level == previous {
+			continue
+		}
+		pot := Pot{}
+		for _, contribution := range contributions {
+			pot.Amount += min(contribution.Amount, level) - min(contribution.Amount, previous)
+			if !contribution.Folded && contribution.Amount >= level {
+				pot.Eligible = append(pot.Eligible, contribution.Player)
+			}
+		}
+		pots = append(pots, pot)
+		previous = level
+	}
+
+	// folded players that put in more than anyone still in leave it to the last pot
+	for _, contribution := range contributions {
+		if over := contribution.Amount - previous; over > 0 && len(pots) > 0 {
+			pots[len(pots)-1].Amount += over
+		}
+	}
+	return pots
+}
+
+// AwardPots gives each pot to the best ranked of its eligible players. Tied players split it and the odd chips go one
+// each to the tied players that come first among the eligible.
+func AwardPots(pots []Pot, ranks map[string]cards.HandRank) []PotShare {
+	var shares []PotShare
+	for i, pot := range pots {
+		var winners []string
+		var best cards.HandRank
+		for _, player := range pot.Eligible {
+			rank, ok := ranks[player]
+			switch {
+			case !ok:
+				continue
+			case len(winners) == 0 || rank > best:
+				winners, best = []string{player}, rank
+			case rank == best:
+				winners = append(winners, player)
+			}
+		}
+		if len(winners) == 0 {
+			continue
+		}
+
+		share, oddChips := pot.Amount/len(winners), pot.Amount%len(winners)
+		for j, winner := range winners {
+			won := share
+			if j < oddChips {
+				won++
+			}
+			shares = append(shares, PotShare{Pot: i, Player: winner, Amount: won})
+		}
+	}
+	return shares
+}
@@ -0,0 +1,237 @@
+package poker_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andremfp/poker-app"
+	"github.com/andremfp/poker-app/cards"
+)
+
+func TestBuildPots(t *testing.T) {
+	in := func(player string, amount int) poker.Contribution {
+		return poker.Contribution{Player: player, Amount: amount}
+	}
+	folded := func(player string, amount int) poker.Contribution {
+		return poker.Contribution{Player: player, Amount: amount, Folded: true}
+	}
+
+	tests := []struct {
+		name          string
+		contributions []poker.Contribution
+		want          []poker.Pot
+	}{
+		{
+			name:          "everyone put in the same",
+			contributions: []poker.Contribution{in("Andre", 100), in("Chris", 100), in("John", 100)},
+			want:          []poker.Pot{{Amount: 300, Eligible: []string{"Andre", "Chris", "John"}}},
+		},
+		{
+			name:          "heads up",
+			contributions: []poker.Contribution{in("Andre", 50), in("Chris", 50)},
+			want:          []poker.Pot{{Amount: 100, Eligible: []string{"Andre", "Chris"}}},
+		},
+		{
+			name:          "one short all in makes a side pot",
+			contributions: []poker.Contribution{in("Andre", 50), in("Chris", 200), in("John", 200)},
+			want: []poker.Pot{
+				{Amount: 150, Eligible: []string{"Andre", "Chris", "John"}},
+				{Amount: 300, Eligible: []string{"Chris", "John"}},
+			},
+		},
+		{
+			name:          "every all in for a different amount makes its own pot",
+			contributions: []poker.Contribution{in("Andre", 300), in("Chris", 100), in("John", 200), in("Mary", 400)},
+			want: []poker.Pot{
+				{Amount: 400, Eligible: []string{"Andre", "Chris", "John", "Mary"}},
+				{Amount: 300, Eligible: []string{"Andre", "John", "Mary"}},
+				{Amount: 200, Eligible: []string{"Andre", "Mary"}},
+				{Amount: 100, Eligible: []string{"Mary"}},
+			},
+		},
+		{
+			name:          "all ins for the same amount share a pot",
+			contributions: []poker.Contribution{in("Andre", 100), in("Chris", 100), in("John", 250), in("Mary", 250)},
+			want: []poker.Pot{
+				{Amount: 400, Eligible: []string{"Andre", "Chris", "John", "Mary"}},
+				{Amount: 300, Eligible: []string{"John", "Mary"}},
+			},
+		},
+		{
+			name:          "folded players leave their chips but can't win",
+			contributions: []poker.Contribution{folded("Andre", 100), in("Chris", 100), in("John", 100)},
+			want:          []poker.Pot{{Amount: 300, Eligible: []string{"Chris", "John"}}},
+		},
+		{
+			name:          "a fold smaller than the short all in goes to the main pot",
+			contributions: []poker.Contribution{folded("Andre", 20), in("Chris", 50), in("John", 200), in("Mary", 200)},
+			want: []poker.Pot{
+				{Amount: 170, Eligible: []string{"Chris", "John", "Mary"}},
+				{Amount: 300, Eligible: []string{"John", "Mary"}},
+			},
+		},
+		{
+			name:          "a fold between two all ins is split between their pots",
+			contributions: []poker.Contribution{folded("Andre", 120), in("Chris", 50), in("John", 200), in("Mary", 200)},
+			want: []poker.Pot{
+				{Amount: 200, Eligible: []string{"Chris", "John", "Mary"}},
+				{Amount: 370, Eligible: []string{"John", "Mary"}},
+			},
+		},
+		{
+			name:          "a fold bigger than anyone still in goes to the last pot",
+			contributions: []poker.Contribution{folded("Andre", 300), in("Chris", 50), in("John", 200)},
+			want: []poker.Pot{
+				{Amount: 150, Eligible: []string{"Chris", "John"}},
+				{Amount: 400, Eligible: []string{"John"}},
+			},
+		},
+		{
+			name:          "players that put nothing in aren't in any pot",
+			contributions: []poker.Contribution{in("Andre", 0), in("Chris", 100), in("John", 100)},
+			want:          []poker.Pot{{Amount: 200, Eligible: []string{"Chris", "John"}}},
+		},
+		{
+			name:          "the chips of players that all folded are a pot for the players still in",
+			contributions: []poker.Contribution{folded("Andre", 100), in("Chris", 0), folded("John", 50)},
+			want:          []poker.Pot{{Amount: 150, Eligible: []string{"Chris"}}},
+		},
+		{
+			name:          "the chips of players that all folded stay in a pot with no one to win it",
+			contributions: []poker.Contribution{folded("Andre", 100), folded("John", 50)},
+			want:          []poker.Pot{{Amount: 150}},
+		},
+		{
+			name:          "no contributions make no pots",
+			contributions: nil,
+			want:          nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := poker.BuildPots(tt.contributions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got pots %+v want %+v", got, tt.want)
+			}
+
+			total, contributed := 0, 0
+			for _, pot := range got {
+				total += pot.Amount
+			}
+			for _, contribution := range tt.contributions {
+				contributed += contribution.Amount
+			}
+			if len(tt.want) > 0 && total != contributed {
+				t.Errorf("got %d chips in the pots want all %d contributed", total, contributed)
+			}
+		})
+	}
+}
+
+func TestAwardPots(t *testing.T) {
+	rank := func(hand string) cards.HandRank {
+		t.Helper()
+		rank, err := cards.Evaluate(cards.MustParseCards(hand)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rank
+	}
+	var (
+		quads     = rank("As Ac Ad Ah Kd")
+		flush     = rank("2h 5h 9h Jh Kh")
+		straight  = rank("5c 6d 7h 8s 9c")
+		pair      = rank("Qs Qd 4c 7h 9s")
+		otherPair = rank("Qc Qh 4d 7s 9d")
+		highCard  = rank("2c 5d 9h Js Kc")
+	)
+	pot := func(amount int, eligible ...string) poker.Pot {
+		return poker.Pot{Amount: amount, Eligible: eligible}
+	}
+
+	tests := []struct {
+		name  string
+		pots  []poker.Pot
+		ranks map[string]cards.HandRank
+		want  []poker.PotShare
+	}{
+		{
+			name:  "the best hand takes the pot",
+			pots:  []poker.Pot{pot(300, "Andre", "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": pair, "Chris": flush, "John": highCard},
+			want:  []poker.PotShare{{Pot: 0, Player: "Chris", Amount: 300}},
+		},
+		{
+			name:  "a tie splits the pot evenly",
+			pots:  []poker.Pot{pot(300, "Andre", "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": pair, "Chris": otherPair, "John": highCard},
+			want:  []poker.PotShare{{Pot: 0, Player: "Andre", Amount: 150}, {Pot: 0, Player: "Chris", Amount: 150}},
+		},
+		{
+			name:  "the odd chip goes to the first of the tied players",
+			pots:  []poker.Pot{pot(25, "Andre", "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": highCard, "Chris": pair, "John": otherPair},
+			want:  []poker.PotShare{{Pot: 0, Player: "Chris", Amount: 13}, {Pot: 0, Player: "John", Amount: 12}},
+		},
+		{
+			name:  "odd chips go one each in order on a three way split",
+			pots:  []poker.Pot{pot(302, "Andre", "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": flush, "Chris": flush, "John": flush},
+			want: []poker.PotShare{
+				{Pot: 0, Player: "Andre", Amount: 101},
+				{Pot: 0, Player: "Chris", Amount: 101},
+				{Pot: 0, Player: "John", Amount: 100},
+			},
+		},
+		{
+			name:  "the short stack wins the main pot and the next best the side pot",
+			pots:  []poker.Pot{pot(150, "Andre", "Chris", "John"), pot(300, "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": quads, "Chris": pair, "John": straight},
+			want:  []poker.PotShare{{Pot: 0, Player: "Andre", Amount: 150}, {Pot: 1, Player: "John", Amount: 300}},
+		},
+		{
+			name:  "the best hand overall wins every pot it is in",
+			pots:  []poker.Pot{pot(150, "Andre", "Chris", "John"), pot(300, "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": pair, "Chris": quads, "John": straight},
+			want:  []poker.PotShare{{Pot: 0, Player: "Chris", Amount: 150}, {Pot: 1, Player: "Chris", Amount: 300}},
+		},
+		{
+			name:  "a side pot can be split while the main pot isn't",
+			pots:  []poker.Pot{pot(150, "Andre", "Chris", "John"), pot(301, "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": quads, "Chris": pair, "John": otherPair},
+			want: []poker.PotShare{
+				{Pot: 0, Player: "Andre", Amount: 150},
+				{Pot: 1, Player: "Chris", Amount: 151},
+				{Pot: 1, Player: "John", Amount: 150},
+			},
+		},
+		{
+			name:  "players that aren't eligible can't win a pot even with the best hand",
+			pots:  []poker.Pot{pot(300, "Chris", "John")},
+			ranks: map[string]cards.HandRank{"Andre": quads, "Chris": pair, "John": highCard},
+			want:  []poker.PotShare{{Pot: 0, Player: "Chris", Amount: 300}},
+		},
+		{
+			name:  "a pot left to one player goes back to them",
+			pots:  []poker.Pot{pot(150, "Andre", "Chris"), pot(400, "Chris")},
+			ranks: map[string]cards.HandRank{"Andre": flush, "Chris": highCard},
+			want:  []poker.PotShare{{Pot: 0, Player: "Andre", Amount: 150}, {Pot: 1, Player: "Chris", Amount: 400}},
+		},
+		{
+			name:  "no pots win nothing",
+			pots:  nil,
+			ranks: map[string]cards.HandRank{"Andre": flush},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := poker.AwardPots(tt.pots, tt.ranks)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got shares %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package poker
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PlayerProfile is what the results say about a player
+type PlayerProfile struct {
+	Name          string
+	Games         int
+	Wins          int
+	WinRate       float64 // share of the games won, from 0 to 1
+	AverageFinish float64
+	// wins in a row up to the last game, or games without a win in a row as a negative number
+	CurrentStreak int
+	LongestStreak int // most wins in a row
+	// payouts minus buy-ins
+	ProfitLoss int
+	LastPlayed time.Time
+	// sorted by opponent
+	HeadToHead []HeadToHead
+}
+
+// HeadToHead is how a player did against an opponent in the games they both finished
+type HeadToHead struct {
+	Opponent string
+	Games    int
+	Wins     int // games the player finished ahead of the opponent
+	Losses   int
+}
+
+// NewPlayerProfile builds the profile of the player from the results, the games they didn't finish are ignored
+func NewPlayerProfile(playerName string, results []GameResult) PlayerProfile {
+	played := ResultsForPlayer(results, playerName)
+	sort.SliceStable(played, func(i, j int) bool {
+		return played[i].Finished.Before(played[j].Finished)
+	})
+
+	profile := PlayerProfile{Name: playerName, HeadToHead: []HeadToHead{}}
+	opponents := map[string]*HeadToHead{}
+	finishes, winStreak := 0, 0
+	for _, result := range played {
+		finisher := result.Finisher(playerName)
+
+		profile.Games++
+		finishes += finisher.Position
+		profile.ProfitLoss += finisher.Payout - result.PaidIn(*finisher)
+		profile.LastPlayed = result.Finished
+
+		if finisher.Position == 1 {
+			profile.Wins++
+			winStreak++
+			if profile.CurrentStreak < 0 {
+				profile.CurrentStreak = 0
+			}
+			profile.CurrentStreak++
+		} else {
+			winStreak = 0
+			if profile.CurrentStreak > 0 {
+				profile.CurrentStreak = 0
+			}
+			profile.CurrentStreak--
+		}
+		if winStreak > profile.LongestStreak {
+			profile.LongestStreak = winStreak
+		}
+
+		for _, opponent := range result.Finishers {
+			if opponent.Name == playerName {
+				continue
+			}
+			record, ok := opponents[opponent.Name]
+			if !ok {
+				record = &HeadToHead{Opponent: opponent.Name}
+				opponents[opponent.Name] = record
+			}
+			record.Games++
+			if finisher.Position < opponent.Position {
+				record.Wins++
+			} else {
+				record.Losses++
+			}
+		}
+	}
+
+	if profile.Games > 0 {
+		profile.WinRate = math.Round(float64(profile.Wins)/float64(profile.Games)*1000) / 1000
+		profile.AverageFinish = math.Round(float64(finishes)/float64(profile.Games)*100) / 100
+	}
+
+	for _, record := range opponents {
+		profile.HeadToHead = append(profile.HeadToHead, *record)
+	}
+	sort.Slice(profile.HeadToHead, func(i, j int) bool {
+		return profile.HeadToHead[i].Opponent < profile.HeadToHead[j].Opponent
+	})
+	return profile
+}
@@ -0,0 +1,75 @@
+package poker_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestNewPlayerProfile(t *testing.T) {
+	start := time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC)
+	game := func(hours int, buyIn int, finishers ...poker.Finisher) poker.GameResult {
+		return poker.GameResult{Finished: start.Add(time.Duration(hours) * time.Hour), BuyIn: buyIn, Finishers: finishers}
+	}
+
+	results := []poker.GameResult{
+		// out of order, the streaks follow the finish times
+		game(3, 10, poker.Finisher{Name: "Andre", Position: 1, Payout: 30}, poker.Finisher{Name: "Chris", Position: 2}, poker.Finisher{Name: "John", Position: 3}),
+		game(1, 10, poker.Finisher{Name: "Andre", Position: 1, Payout: 20}, poker.Finisher{Name: "Chris", Position: 2}),
+		game(2, 10, poker.Finisher{Name: "Andre", Position: 1, Payout: 20}, poker.Finisher{Name: "John", Position: 2}),
+		game(4, 10, poker.Finisher{Name: "Chris", Position: 1, Payout: 20}, poker.Finisher{Name: "Andre", Position: 2}),
+		game(5, 0, poker.Finisher{Name: "John", Position: 1}, poker.Finisher{Name: "Andre", Position: 3}, poker.Finisher{Name: "Chris", Position: 2}),
+		game(6, 0, poker.Finisher{Name: "Chris", Position: 1}),
+	}
+
+	t.Run("profile of a player", func(t *testing.T) {
+		got := poker.NewPlayerProfile("Andre", results)
+		want := poker.PlayerProfile{
+			Name:          "Andre",
+			Games:         5,
+			Wins:          3,
+			WinRate:       0.6,
+			AverageFinish: 1.6,
+			CurrentStreak: -2,
+			LongestStreak: 3,
+			ProfitLoss:    70 - 40,
+			LastPlayed:    start.Add(5 * time.Hour),
+			HeadToHead: []poker.HeadToHead{
+				{Opponent: "Chris", Games: 4, Wins: 2, Losses: 2},
+				{Opponent: "John", Games: 3, Wins: 2, Losses: 1},
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got profile %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("current win streak", func(t *testing.T) {
+		got := poker.NewPlayerProfile("Chris", results)
+		if got.CurrentStreak != 1 || got.LongestStreak != 1 {
+			t.Errorf("got current streak %d and longest %d, want 1 and 1", got.CurrentStreak, got.LongestStreak)
+		}
+	})
+
+	t.Run("profit takes off every rebuy and add-on", func(t *testing.T) {
+		result := game(1, 10, poker.Finisher{Name: "Andre", Position: 1, Payout: 60, Rebuys: 2, AddOn: true}, poker.Finisher{Name: "Chris", Position: 2, AddOn: true})
+		result.AddOnCost = 5
+
+		if got := poker.NewPlayerProfile("Andre", []poker.GameResult{result}).ProfitLoss; got != 60-35 {
+			t.Errorf("got a profit of %d want %d", got, 60-35)
+		}
+		if got := poker.NewPlayerProfile("Chris", []poker.GameResult{result}).ProfitLoss; got != -15 {
+			t.Errorf("got a profit of %d want -15", got)
+		}
+	})
+
+	t.Run("player without games", func(t *testing.T) {
+		got := poker.NewPlayerProfile("Bob", results)
+		want := poker.PlayerProfile{Name: "Bob", HeadToHead: []poker.HeadToHead{}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got profile %+v, want %+v", got, want)
+		}
+	})
+}
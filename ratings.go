@@ -0,0 +1,114 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	DefaultInitialRating = 1500
+	DefaultRatingK       = 32
+)
+
+// RatingParams tune the elo ratings, changing them applies to every game played since ratings are rebuilt from the results
+type RatingParams struct {
+	// the rating of a player before their first game
+	Initial float64
+	// how far a single game moves a rating
+	K float64
+}
+
+func DefaultRatingParams() RatingParams {
+	return RatingParams{Initial: DefaultInitialRating, K: DefaultRatingK}
+}
+
+func (p RatingParams) Validate() error {
+	if p.K <= 0 {
+		return fmt.Errorf("rating k factor must be positive, got %v", p.K)
+	}
+	if p.Initial <= 0 {
+		return fmt.Errorf("initial rating must be positive, got %v", p.Initial)
+	}
+	return nil
+}
+
+// Rating is the skill of a player worked out from the games they finished
+type Rating struct {
+	Name   string
+	Rating float64
+	Games  int
+}
+
+// Ratings are sorted by the highest rating then name
+type Ratings []Rating
+
+func (r Ratings) Find(playerName string) *Rating {
+	for i, rating := range r {
+		if rating.Name == playerName {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// NewRatingsFromResults replays the games in the order they finished. Every game counts as a match between each pair
+// of its finishers, the one that finished ahead winning, and a player's change is split between their opponents so a
+// big field moves ratings as much as a heads up game. Games with a single finisher, like wins recorded on their own,
+// say nothing about skill and are left out.
+func NewRatingsFromResults(results []GameResult, params RatingParams) Ratings {
+	played := make([]GameResult, len(results))
+	copy(played, results)
+	sort.SliceStable(played, func(i, j int) bool {
+		return played[i].Finished.Before(played[j].Finished)
+	})
+
+	ratings := map[string]float64{}
+	games := map[string]int{}
+	for _, result := range played {
+		finishers := result.Finishers
+		if len(finishers) < 2 {
+			continue
+		}
+
+		// ratings before the game, so the order of the finishers doesn't matter
+		before := make([]float64, len(finishers))
+		for i, finisher := range finishers {
+			rating, ok := ratings[finisher.Name]
+			if !ok {
+				rating = params.Initial
+			}
+			before[i] = rating
+		}
+
+		opponents := float64(len(finishers) - 1)
+		for i, finisher := range finishers {
+			var change float64
+			for j, opponent := range finishers {
+				if i == j {
+					continue
+				}
+				expected := 1 / (1 + math.Pow(10, (before[j]-before[i])/400))
+				actual := 0.0
+				if finisher.Position < opponent.Position {
+					actual = 1
+				}
+				change += actual - expected
+			}
+			ratings[finisher.Name] = before[i] + params.K*change/opponents
+			games[finisher.Name]++
+		}
+	}
+
+	leaderboard := make(Ratings, 0, len(ratings))
+	for name, rating := range ratings {
+		leaderboard = append(leaderboard, Rating{Name: name, Rating: math.Round(rating*10) / 10, Games: games[name]})
+	}
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Rating != leaderboard[j].Rating {
+			return leaderboard[i].Rating > leaderboard[j].Rating
+		}
+		return leaderboard[i].Name < leaderboard[j].Name
+	})
+	return leaderboard
+}
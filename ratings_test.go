@@ -0,0 +1,86 @@
+package poker_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestNewRatingsFromResults(t *testing.T) {
+	start := time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC)
+	game := func(finished time.Time, players ...string) poker.GameResult {
+		result := poker.GameResult{Finished: finished}
+		for i, name := range players {
+			result.Finishers = append(result.Finishers, poker.Finisher{Name: name, Position: i + 1})
+		}
+		return result
+	}
+
+	t.Run("even players move by half the k factor", func(t *testing.T) {
+		got := poker.NewRatingsFromResults([]poker.GameResult{game(start, "Andre", "Chris", "John")}, poker.DefaultRatingParams())
+
+		want := poker.Ratings{
+			{Name: "Andre", Rating: 1516, Games: 1},
+			{Name: "Chris", Rating: 1500, Games: 1},
+			{Name: "John", Rating: 1484, Games: 1},
+		}
+		assertRatings(t, got, want)
+	})
+
+	t.Run("beating a stronger player is worth more", func(t *testing.T) {
+		got := poker.NewRatingsFromResults([]poker.GameResult{
+			game(start, "Andre", "Chris"),
+			game(start.Add(time.Hour), "John", "Andre"),
+		}, poker.DefaultRatingParams())
+
+		andre, john := got.Find("Andre"), got.Find("John")
+		if john.Rating-1500 <= 16 {
+			t.Errorf("got John on %v, want more than the 16 points for beating an even player", john.Rating)
+		}
+		if andre.Games != 2 || andre.Rating >= 1500 {
+			t.Errorf("got Andre on %+v, want below 1500 after 2 games", andre)
+		}
+	})
+
+	t.Run("games are replayed in the order they finished", func(t *testing.T) {
+		first := game(start, "Andre", "Chris")
+		second := game(start.Add(time.Hour), "Chris", "John")
+
+		assertRatings(t,
+			poker.NewRatingsFromResults([]poker.GameResult{second, first}, poker.DefaultRatingParams()),
+			poker.NewRatingsFromResults([]poker.GameResult{first, second}, poker.DefaultRatingParams()),
+		)
+	})
+
+	t.Run("params apply to every game", func(t *testing.T) {
+		results := []poker.GameResult{game(start, "Andre", "Chris")}
+		got := poker.NewRatingsFromResults(results, poker.RatingParams{Initial: 1000, K: 10})
+
+		assertRatings(t, got, poker.Ratings{
+			{Name: "Andre", Rating: 1005, Games: 1},
+			{Name: "Chris", Rating: 995, Games: 1},
+		})
+	})
+
+	t.Run("games with a single finisher are not rated", func(t *testing.T) {
+		got := poker.NewRatingsFromResults([]poker.GameResult{game(start, "Andre")}, poker.DefaultRatingParams())
+		assertRatings(t, got, poker.Ratings{})
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		for _, params := range []poker.RatingParams{{Initial: 1500}, {K: 32}, {Initial: 1500, K: -1}} {
+			if err := params.Validate(); err == nil {
+				t.Errorf("expected an error for %+v but didn't get one", params)
+			}
+		}
+	})
+}
+
+func assertRatings(t testing.TB, got, want poker.Ratings) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got ratings %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// APIVersionPrefix is where the versioned api is served, the same routes are served without it for older clients
+const APIVersionPrefix = "/api/v1"
+
+// ErrorResponse is the json body of every error answered by the api
+type ErrorResponse struct {
+	Status int
+	Error  string
+}
+
+// pathParams are the {name} segments of a route, url decoded
+type pathParams map[string]string
+
+type pathParamsKey struct{}
+
+// routeParams are the path params of the route the request was sent to
+func routeParams(r *http.Request) pathParams {
+	params, _ := r.Context().Value(pathParamsKey{}).(pathParams)
+	return params
+}
+
+type route struct {
+	segments []string
+	methods  map[string]http.HandlerFunc
+}
+
+// router sends requests to the handler of their method and path. Paths it knows without a handler for the method
+// are answered with 405 and the methods that are allowed.
+type router struct {
+	routes []*route
+}
+
+func (rt *router) handle(method, pattern string, handler http.HandlerFunc) {
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	for _, existing := range rt.routes {
+		if strings.Join(existing.segments, "/") == strings.Join(segments, "/") {
+			existing.methods[method] = handler
+			return
+		}
+	}
+	rt.routes = append(rt.routes, &route{segments: segments, methods: map[string]http.HandlerFunc{method: handler}})
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// split the escaped path so an encoded / stays inside its segment
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("could not decode path %q, %v", r.URL.EscapedPath(), err))
+			return
+		}
+		segments = append(segments, decoded)
+	}
+
+	for _, route := range rt.routes {
+		params, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+
+		handler, ok := route.methods[r.Method]
+		if !ok {
+			w.Header().Set("allow", strings.Join(route.allowed(), ", "))
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed on %s", r.Method, r.URL.Path))
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(r.Context(), pathParamsKey{}, params)))
+		return
+	}
+
+	writeError(w, http.StatusNotFound, fmt.Errorf("%s not found", r.URL.Path))
+}
+
+func (rt *route) match(segments []string) (pathParams, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	params := pathParams{}
+	for i, segment := range rt.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[strings.Trim(segment, "{}")] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (rt *route) allowed() []string {
+	methods := make([]string, 0, len(rt.methods))
+	for method := range rt.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// writeJSON sets the headers before the body, once the body is written the status can't change
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("content-type", JsonContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Status: status, Error: err.Error()})
+}
@@ -0,0 +1,140 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// names of the scoring rules a league can be created with
+const (
+	WinsScoring       = "wins"
+	FieldSizeScoring  = "field-size"
+	FormulaOneScoring = "formula-one"
+	BountyScoring     = "bounty"
+)
+
+// DefaultScoring ranks players by wins like leagues always did
+const DefaultScoring = WinsScoring
+
+// ScoringRule gives the points a finisher earned in a game
+type ScoringRule interface {
+	Points(result GameResult, finisher Finisher) int
+}
+
+// ScoringRuleFunc allows a function to be used as a ScoringRule
+type ScoringRuleFunc func(result GameResult, finisher Finisher) int
+
+func (f ScoringRuleFunc) Points(result GameResult, finisher Finisher) int {
+	return f(result, finisher)
+}
+
+// ScoringTable gives points by finishing position, the first entry for the winner, nothing for finishing outside the table
+type ScoringTable []int
+
+func (t ScoringTable) Points(result GameResult, finisher Finisher) int {
+	if finisher.Position < 1 || finisher.Position > len(t) {
+		return 0
+	}
+	return t[finisher.Position-1]
+}
+
+// a point for every win
+func winPoints(result GameResult, finisher Finisher) int {
+	if finisher.Position == 1 {
+		return 1
+	}
+	return 0
+}
+
+// a point for every player finished ahead of, plus one for playing, so beating a bigger field is worth more.
+// results that don't know how many played are scored by their finishers
+func fieldSizePoints(result GameResult, finisher Finisher) int {
+	field := result.NumPlayers
+	if field < len(result.Finishers) {
+		field = len(result.Finishers)
+	}
+	if finisher.Position > field {
+		return 0
+	}
+	return field - finisher.Position + 1
+}
+
+// field size points and a point for every player knocked out
+func bountyPoints(result GameResult, finisher Finisher) int {
+	return fieldSizePoints(result, finisher) + finisher.Knockouts
+}
+
+var scoringRules = map[string]ScoringRule{
+	WinsScoring:       ScoringRuleFunc(winPoints),
+	FieldSizeScoring:  ScoringRuleFunc(fieldSizePoints),
+	FormulaOneScoring: ScoringTable{25, 18, 15, 12, 10, 8, 6, 4, 2, 1},
+	BountyScoring:     ScoringRuleFunc(bountyPoints),
+}
+
+// GetScoringRule finds a scoring rule by name, the default rule when the name is empty
+func GetScoringRule(name string) (ScoringRule, error) {
+	if name == "" {
+		name = DefaultScoring
+	}
+	rule, ok := scoringRules[name]
+	if !ok {
+		return nil, fmt.Errorf("%w, unknown scoring rule %q, choose one of %s", ErrInvalidLeague, name, strings.Join(ScoringRuleNames(), ", "))
+	}
+	return rule, nil
+}
+
+func ScoringRuleNames() []string {
+	names := make([]string, 0, len(scoringRules))
+	for name := range scoringRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewLeagueFromResults scores every player that finished a game with the rule. Ties on points are broken by wins,
+// then the better average finish, then more games played and finally by name.
+func NewLeagueFromResults(results []GameResult, rule ScoringRule) League {
+	players := map[string]*Player{}
+	finishes := map[string]int{}
+	for _, result := range results {
+		for _, finisher := range result.Finishers {
+			player, ok := players[finisher.Name]
+			if !ok {
+				player = &Player{Name: finisher.Name}
+				players[finisher.Name] = player
+			}
+			player.Points += rule.Points(result, finisher)
+			player.Played++
+			if finisher.Position == 1 {
+				player.Wins++
+			}
+			finishes[finisher.Name] += finisher.Position
+		}
+	}
+
+	league := make(League, 0, len(players))
+	for name, player := range players {
+		average := float64(finishes[name]) / float64(player.Played)
+		player.AverageFinish = math.Round(average*100) / 100
+		league = append(league, *player)
+	}
+
+	sort.Slice(league, func(i, j int) bool {
+		a, b := league[i], league[j]
+		switch {
+		case a.Points != b.Points:
+			return a.Points > b.Points
+		case a.Wins != b.Wins:
+			return a.Wins > b.Wins
+		case a.AverageFinish != b.AverageFinish:
+			return a.AverageFinish < b.AverageFinish
+		case a.Played != b.Played:
+			return a.Played > b.Played
+		}
+		return a.Name < b.Name
+	})
+	return league
+}
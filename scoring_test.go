@@ -0,0 +1,108 @@
+package poker_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestScoringRules(t *testing.T) {
+	// 10 players, only the top 3 and a player that busted early were recorded
+	result := poker.GameResult{NumPlayers: 10, Finishers: []poker.Finisher{
+		{Name: "Andre", Position: 1, Knockouts: 4},
+		{Name: "Chris", Position: 2, Knockouts: 2},
+		{Name: "John", Position: 3},
+		{Name: "Bob", Position: 9, Knockouts: 1},
+	}}
+
+	tests := []struct {
+		rule string
+		want []int
+	}{
+		{poker.WinsScoring, []int{1, 0, 0, 0}},
+		{poker.FieldSizeScoring, []int{10, 9, 8, 2}},
+		{poker.FormulaOneScoring, []int{25, 18, 15, 2}},
+		{poker.BountyScoring, []int{14, 11, 8, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := poker.GetScoringRule(tt.rule)
+			assertNoError(t, err)
+
+			var got []int
+			for _, finisher := range result.Finishers {
+				got = append(got, rule.Points(result, finisher))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got points %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("field size falls back to the finishers", func(t *testing.T) {
+		rule, err := poker.GetScoringRule(poker.FieldSizeScoring)
+		assertNoError(t, err)
+
+		unknownField := poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Chris", Position: 2}}}
+		if got := rule.Points(unknownField, unknownField.Finishers[0]); got != 2 {
+			t.Errorf("got %d points, want 2", got)
+		}
+	})
+
+	t.Run("empty is the default rule", func(t *testing.T) {
+		rule, err := poker.GetScoringRule("")
+		assertNoError(t, err)
+		if got := rule.Points(result, result.Finishers[0]); got != 1 {
+			t.Errorf("got %d points for a win, want 1", got)
+		}
+	})
+
+	t.Run("unknown rules are invalid leagues", func(t *testing.T) {
+		if _, err := poker.GetScoringRule("strokes"); !errors.Is(err, poker.ErrInvalidLeague) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidLeague)
+		}
+	})
+
+	t.Run("custom rules", func(t *testing.T) {
+		podium := poker.ScoringTable{3, 2, 1}
+		league := poker.NewLeagueFromResults([]poker.GameResult{result}, podium)
+
+		want := poker.League{
+			{Name: "Andre", Wins: 1, Points: 3, Played: 1, AverageFinish: 1},
+			{Name: "Chris", Wins: 0, Points: 2, Played: 1, AverageFinish: 2},
+			{Name: "John", Wins: 0, Points: 1, Played: 1, AverageFinish: 3},
+			{Name: "Bob", Wins: 0, Points: 0, Played: 1, AverageFinish: 9},
+		}
+		assertLeague(t, league, want)
+	})
+}
+
+func TestLeagueTieBreaks(t *testing.T) {
+	fieldSize, err := poker.GetScoringRule(poker.FieldSizeScoring)
+	assertNoError(t, err)
+
+	results := []poker.GameResult{
+		// Andre and Chris tie on 3 points and a win, Andre has the better average finish
+		{NumPlayers: 3, Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Chris", Position: 3}}},
+		{NumPlayers: 2, Finishers: []poker.Finisher{{Name: "Chris", Position: 1}}},
+		// Eve, Bob and Fay have 3 points without a win, Eve played more and Bob and Fay tie on everything
+		{NumPlayers: 2, Finishers: []poker.Finisher{{Name: "Eve", Position: 2}}},
+		{NumPlayers: 3, Finishers: []poker.Finisher{{Name: "Eve", Position: 2}}},
+		{NumPlayers: 4, Finishers: []poker.Finisher{{Name: "Fay", Position: 2}}},
+		{NumPlayers: 4, Finishers: []poker.Finisher{{Name: "Bob", Position: 2}}},
+	}
+
+	got := poker.NewLeagueFromResults(results, fieldSize)
+	var names []string
+	for _, player := range got {
+		names = append(names, player.Name)
+	}
+
+	want := []string{"Andre", "Chris", "Eve", "Bob", "Fay"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got standings %+v, want the order %v", got, want)
+	}
+}
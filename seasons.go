@@ -0,0 +1,163 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how long a season lasts, a new one starts as soon as the previous one ends
+type SeasonLength string
+
+const (
+	Monthly   SeasonLength = "monthly"
+	Quarterly SeasonLength = "quarterly"
+	Yearly    SeasonLength = "yearly"
+)
+
+const DefaultSeasonLength = Quarterly
+
+// AllSeasons asks for the standings of every game ever played
+const AllSeasons = "all"
+
+var ErrUnknownSeason = errors.New("unknown season")
+
+// Season is a period with its own standings. Results count in the season they finished in,
+// so past seasons keep their standings while a new table starts.
+type Season struct {
+	ID    string
+	Start time.Time
+	// the start of the next season, a season without bounds covers all time
+	End time.Time
+}
+
+// AllTime is the season with every result in it
+func AllTime() Season {
+	return Season{ID: AllSeasons}
+}
+
+func (s Season) Contains(t time.Time) bool {
+	return (s.Start.IsZero() || !t.Before(s.Start)) && (s.End.IsZero() || t.Before(s.End))
+}
+
+func (s Season) String() string {
+	if s.Start.IsZero() {
+		return s.ID
+	}
+	return fmt.Sprintf("%s (%s to %s)", s.ID, s.Start.Format(time.DateOnly), s.End.AddDate(0, 0, -1).Format(time.DateOnly))
+}
+
+// SeasonCalendar splits time in UTC into back to back seasons of the same length
+type SeasonCalendar struct {
+	Length SeasonLength
+}
+
+func NewSeasonCalendar(length string) (SeasonCalendar, error) {
+	switch SeasonLength(length) {
+	case Monthly, Quarterly, Yearly:
+		return SeasonCalendar{SeasonLength(length)}, nil
+	case "":
+		return SeasonCalendar{DefaultSeasonLength}, nil
+	}
+	return SeasonCalendar{}, fmt.Errorf("unknown season length %q, choose one of %s, %s, %s", length, Monthly, Quarterly, Yearly)
+}
+
+// SeasonAt returns the season running at t
+func (c SeasonCalendar) SeasonAt(t time.Time) Season {
+	t = t.UTC()
+	year, month := t.Year(), t.Month()
+
+	switch c.Length {
+	case Monthly:
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return Season{ID: start.Format("2006-01"), Start: start, End: start.AddDate(0, 1, 0)}
+	case Yearly:
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return Season{ID: strconv.Itoa(year), Start: start, End: start.AddDate(1, 0, 0)}
+	default:
+		quarter := (int(month)-1)/3 + 1
+		start := time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, time.UTC)
+		return Season{ID: fmt.Sprintf("%d-Q%d", year, quarter), Start: start, End: start.AddDate(0, 3, 0)}
+	}
+}
+
+// Current is the season running now
+func (c SeasonCalendar) Current() Season {
+	return c.SeasonAt(time.Now())
+}
+
+// Season finds a season by id, e.g. 2024-03 for monthly seasons, 2024-Q1 for quarterly and 2024 for yearly,
+// or all of them with AllSeasons
+func (c SeasonCalendar) Season(id string) (Season, error) {
+	if id == AllSeasons {
+		return AllTime(), nil
+	}
+
+	var start time.Time
+	var err error
+	switch c.Length {
+	case Monthly:
+		start, err = time.Parse("2006-01", id)
+	case Yearly:
+		start, err = time.Parse("2006", id)
+	default:
+		year, quarter, found := strings.Cut(id, "-Q")
+		q, qErr := strconv.Atoi(quarter)
+		if !found || qErr != nil || q < 1 || q > 4 {
+			err = fmt.Errorf("quarters look like 2024-Q1")
+			break
+		}
+		start, err = time.Parse("2006", year)
+		start = start.AddDate(0, 3*(q-1), 0)
+	}
+	if err != nil {
+		return Season{}, fmt.Errorf("%w %q for %s seasons, %v", ErrUnknownSeason, id, c.Length, err)
+	}
+
+	return c.SeasonAt(start), nil
+}
+
+// Seasons lists the current season and every season with results, newest first
+func (c SeasonCalendar) Seasons(results []GameResult) []Season {
+	current := c.Current()
+	seen := map[string]bool{current.ID: true}
+	seasons := []Season{current}
+
+	for _, result := range results {
+		season := c.SeasonAt(result.Finished)
+		if !seen[season.ID] {
+			seen[season.ID] = true
+			seasons = append(seasons, season)
+		}
+	}
+
+	sort.Slice(seasons, func(i, j int) bool {
+		return seasons[i].Start.After(seasons[j].Start)
+	})
+	return seasons
+}
+
+// resultsInSeason returns the results of the games of the league that finished during the season
+func resultsInSeason(results []GameResult, leagueID string, season Season) []GameResult {
+	var played []GameResult
+	for _, result := range results {
+		if result.League == leagueID && season.Contains(result.Finished) {
+			played = append(played, result)
+		}
+	}
+	return played
+}
+
+// ResultsForSeason returns the results of the games that finished during the season
+func ResultsForSeason(results []GameResult, season Season) []GameResult {
+	var played []GameResult
+	for _, result := range results {
+		if season.Contains(result.Finished) {
+			played = append(played, result)
+		}
+	}
+	return played
+}
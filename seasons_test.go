@@ -0,0 +1,115 @@
+package poker_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestSeasonCalendar(t *testing.T) {
+	at := time.Date(2024, 5, 31, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		length    poker.SeasonLength
+		wantID    string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{poker.Monthly, "2024-05", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{poker.Quarterly, "2024-Q2", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{poker.Yearly, "2024", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.length), func(t *testing.T) {
+			calendar, err := poker.NewSeasonCalendar(string(tt.length))
+			assertNoError(t, err)
+
+			season := calendar.SeasonAt(at)
+			want := poker.Season{ID: tt.wantID, Start: tt.wantStart, End: tt.wantEnd}
+			if season != want {
+				t.Errorf("got season %+v, want %+v", season, want)
+			}
+
+			found, err := calendar.Season(tt.wantID)
+			assertNoError(t, err)
+			if found != want {
+				t.Errorf("got season %+v for %q, want %+v", found, tt.wantID, want)
+			}
+
+			// the next season starts where this one ends
+			if next := calendar.SeasonAt(season.End); next.Start != season.End || next.ID == season.ID {
+				t.Errorf("got next season %+v after %+v", next, season)
+			}
+		})
+	}
+
+	t.Run("seasons run in utc", func(t *testing.T) {
+		ahead := time.FixedZone("UTC+1", 60*60)
+		got := poker.SeasonCalendar{Length: poker.Monthly}.SeasonAt(time.Date(2024, 6, 1, 0, 30, 0, 0, ahead))
+		if got.ID != "2024-05" {
+			t.Errorf("got season %s, want 2024-05", got.ID)
+		}
+	})
+
+	t.Run("unknown seasons", func(t *testing.T) {
+		calendar := poker.SeasonCalendar{Length: poker.Quarterly}
+		for _, id := range []string{"2024-Q5", "2024-Q0", "2024-05", "Q1", ""} {
+			if _, err := calendar.Season(id); !errors.Is(err, poker.ErrUnknownSeason) {
+				t.Errorf("got error %v for %q, want %v", err, id, poker.ErrUnknownSeason)
+			}
+		}
+	})
+
+	t.Run("all seasons", func(t *testing.T) {
+		season, err := poker.SeasonCalendar{Length: poker.Yearly}.Season(poker.AllSeasons)
+		assertNoError(t, err)
+		if !season.Contains(time.Time{}) || !season.Contains(at) {
+			t.Errorf("%+v should contain every result", season)
+		}
+	})
+
+	t.Run("unknown season length", func(t *testing.T) {
+		if _, err := poker.NewSeasonCalendar("weekly"); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestSeasons(t *testing.T) {
+	calendar := poker.SeasonCalendar{Length: poker.Yearly}
+	results := []poker.GameResult{
+		{ID: "game-1", Finished: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "game-2", Finished: time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC)},
+		{ID: "game-3", Finished: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	t.Run("newest first with the current season", func(t *testing.T) {
+		seasons := calendar.Seasons(results)
+
+		var got []string
+		for _, season := range seasons {
+			got = append(got, season.ID)
+		}
+		want := []string{calendar.Current().ID, "2023", "2022"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got seasons %v, want %v", got, want)
+		}
+	})
+
+	t.Run("results of a season", func(t *testing.T) {
+		season, err := calendar.Season("2022")
+		assertNoError(t, err)
+
+		var got []string
+		for _, result := range poker.ResultsForSeason(results, season) {
+			got = append(got, result.ID)
+		}
+		if want := []string{"game-1", "game-3"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got results %v, want %v", got, want)
+		}
+	})
+}
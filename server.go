@@ -1,12 +1,18 @@
 package poker
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
-	"text/template"
+	"sync/atomic"
 
 	"github.com/gorilla/websocket"
 )
@@ -18,14 +24,30 @@ var wsUpgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 }
 
+// Player is a row of the league standings
 type Player struct {
-	Name string
-	Wins int
+	Name          string
+	Wins          int
+	Points        int
+	Played        int
+	AverageFinish float64
 }
 type PlayerStore interface {
-	GetPlayerScore(playerName string) int
-	RecordWin(playerName string)
-	GetLeague() League
+	GetPlayerScore(ctx context.Context, leagueID, playerName string) (int, error)
+	RecordWin(ctx context.Context, leagueID, playerName string) error
+	GetLeague(ctx context.Context, leagueID string) (League, error)
+	// GetSeasonLeague is the standings of the league from its games that finished in the season
+	GetSeasonLeague(ctx context.Context, leagueID string, season Season) (League, error)
+	// GetLeagueResults are the results of the games of the league that finished in the season
+	GetLeagueResults(ctx context.Context, leagueID string, season Season) ([]GameResult, error)
+	RecordResult(ctx context.Context, result GameResult) error
+	GetResults(ctx context.Context) ([]GameResult, error)
+	GetPlayerResults(ctx context.Context, playerName string) ([]GameResult, error)
+
+	// CreateLeague creates a league with the name and scoring rule of league, the store picks the rest
+	CreateLeague(ctx context.Context, league LeagueInfo) (LeagueInfo, error)
+	GetLeagues(ctx context.Context) ([]LeagueInfo, error)
+	ArchiveLeague(ctx context.Context, leagueID string) error
 }
 
 type PlayerServer struct {
@@ -33,13 +55,26 @@ type PlayerServer struct {
 	http.Handler
 	template *template.Template
 	game     Game
+	seasons  SeasonCalendar
+	ratings  RatingParams
+	// nil when there are no users, then anyone can do anything
+	auth *Authenticator
+	hub  *GameHub
+	// DefaultWebSocketTimeouts unless changed before serving
+	WebSocketTimeouts WebSocketTimeouts
 }
 
-func NewPlayerServer(store PlayerStore, game Game) (*PlayerServer, error) {
+// NewPlayerServer serves the store and game. With an authenticator every request needs a user with the role of the
+// route, players can read, organisers can also start games and record results and admins can also manage leagues.
+func NewPlayerServer(store PlayerStore, game Game, seasons SeasonCalendar, ratings RatingParams, auth *Authenticator) (*PlayerServer, error) {
+	if err := ratings.Validate(); err != nil {
+		return nil, err
+	}
+
 	p := new(PlayerServer)
 
 	// process and parse html template
-	tmpl, err := template.ParseFiles("game.html")
+	tmpl, err := template.ParseFiles("game.html", "login.html", "watch.html")
 	if err != nil {
 		return nil, fmt.Errorf("problem loading template %s", err.Error())
 	}
@@ -47,64 +82,769 @@ func NewPlayerServer(store PlayerStore, game Game) (*PlayerServer, error) {
 	p.game = game
 	p.template = tmpl
 	p.store = store
+	p.seasons = seasons
+	p.ratings = ratings
+	p.auth = auth
+	p.hub = NewGameHub(DefaultTickInterval)
+	p.WebSocketTimeouts = DefaultWebSocketTimeouts()
 
-	router := http.NewServeMux()
-	router.Handle("/league", http.HandlerFunc(p.leagueHandler))
-	router.Handle("/players/", http.HandlerFunc(p.playersHandler))
-	router.Handle("/game", http.HandlerFunc(p.gameHandler))
-	router.Handle("/ws", http.HandlerFunc(p.webSocketHandler))
+	api := &router{}
+	for _, prefix := range []string{"", APIVersionPrefix} {
+		api.handle(http.MethodGet, prefix+"/league", p.allow(RolePlayer, p.inLeague(p.showLeague)))
+		api.handle(http.MethodGet, prefix+"/players/{name}", p.allow(RolePlayer, p.withPlayer(p.showScore)))
+		api.handle(http.MethodPost, prefix+"/players/{name}", p.allow(RoleOrganiser, p.withPlayer(p.processWin)))
+		api.handle(http.MethodGet, prefix+"/players/{name}/rating", p.allow(RolePlayer, p.withPlayer(p.showRating)))
+		api.handle(http.MethodGet, prefix+"/leagues", p.allow(RolePlayer, p.showLeagues))
+		api.handle(http.MethodPost, prefix+"/leagues", p.allow(RoleAdmin, p.createLeague))
+		api.handle(http.MethodGet, prefix+"/leagues/{league}", p.allow(RolePlayer, p.inLeague(p.showLeague)))
+		api.handle(http.MethodGet, prefix+"/leagues/{league}/seasons", p.allow(RolePlayer, p.inLeague(p.showSeasons)))
+		api.handle(http.MethodPost, prefix+"/leagues/{league}/archive", p.allow(RoleAdmin, p.inLeague(p.archiveLeague)))
+		api.handle(http.MethodGet, prefix+"/leagues/{league}/players/{name}", p.allow(RolePlayer, p.withPlayer(p.showScore)))
+		api.handle(http.MethodPost, prefix+"/leagues/{league}/players/{name}", p.allow(RoleOrganiser, p.withPlayer(p.processWin)))
+		api.handle(http.MethodGet, prefix+"/results", p.allow(RolePlayer, p.showResults))
+		api.handle(http.MethodPost, prefix+"/results", p.allow(RoleOrganiser, p.recordResult))
+		api.handle(http.MethodGet, prefix+"/ratings", p.allow(RolePlayer, p.showRatings))
+		api.handle(http.MethodGet, prefix+"/games", p.allow(RolePlayer, p.showGames))
+		api.handle(http.MethodGet, prefix+"/login", p.showLogin)
+		api.handle(http.MethodPost, prefix+"/login", p.login)
+		api.handle(http.MethodPost, prefix+"/logout", p.logout)
+	}
+	api.handle(http.MethodGet, APIVersionPrefix+"/openapi.json", p.showOpenAPI)
+	api.handle(http.MethodGet, "/game", p.allowPage(RoleOrganiser, p.gameHandler))
+	api.handle(http.MethodGet, "/watch", p.allowPage(RolePlayer, p.watchHandler))
+	api.handle(http.MethodGet, "/ws", p.allow(RolePlayer, p.webSocketHandler))
 
-	p.Handler = router
+	p.Handler = api
+	if auth != nil {
+		p.Handler = auth.Middleware(api)
+	}
 
 	return p, nil
 }
 
-func (p *PlayerServer) leagueHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("content-type", JsonContentType)
-	json.NewEncoder(w).Encode(p.store.GetLeague())
-	w.WriteHeader(http.StatusOK)
+// allow lets the request through when its user has the role
+func (p *PlayerServer) allow(role Role, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if p.auth == nil {
+			handler(w, r)
+			return
+		}
+
+		user, ok := requestUser(r)
+		if !ok {
+			unauthorized(w, errors.New("log in or send a bearer token"))
+			return
+		}
+		if !user.Role.Allows(role) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("%s is a %s, this needs the %s role", user.Name, user.Role, role))
+			return
+		}
+		handler(w, r)
+	}
 }
 
-func (p *PlayerServer) playersHandler(w http.ResponseWriter, r *http.Request) {
-	playerName := strings.TrimPrefix(r.URL.Path, "/players/")
+// allowPage is allow for the pages people browse to, sending them to the login page instead of answering 401
+func (p *PlayerServer) allowPage(role Role, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := requestUser(r); p.auth != nil && !ok {
+			http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		p.allow(role, handler)(w, r)
+	}
+}
+
+// inLeague passes on the league of the path, the default league for the routes from before there were leagues
+func (p *PlayerServer) inLeague(handler func(w http.ResponseWriter, r *http.Request, leagueID string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, pathLeague(r))
+	}
+}
 
-	switch r.Method {
-	case http.MethodPost:
-		p.processWin(w, r, playerName)
-	case http.MethodGet:
-		p.showScore(w, r, playerName)
+// withPlayer passes on the league and the player of the path once the player name is known to be valid
+func (p *PlayerServer) withPlayer(handler func(w http.ResponseWriter, r *http.Request, leagueID, playerName string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerName := routeParams(r)["name"]
+		if err := ValidatePlayerName(playerName); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		handler(w, r, pathLeague(r), playerName)
 	}
 }
 
+func pathLeague(r *http.Request) string {
+	if leagueID, ok := routeParams(r)["league"]; ok {
+		return leagueID
+	}
+	return DefaultLeagueID
+}
+
+// gamePage is what game.html needs to set up a game
+type gamePage struct {
+	BlindStructures []string
+	Leagues         []LeagueInfo
+	Season          Season
+	// who is logged in, empty when the server has no users
+	User string
+}
+
 func (p *PlayerServer) gameHandler(w http.ResponseWriter, r *http.Request) {
+	leagues, err := p.store.GetLeagues(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+
+	// only open leagues take new games
+	page := gamePage{BlindStructures: p.game.BlindStructures(), Season: p.seasons.Current()}
+	if user, ok := requestUser(r); ok {
+		page.User = user.Name
+	}
+	for _, league := range leagues {
+		if !league.Archived {
+			page.Leagues = append(page.Leagues, league)
+		}
+	}
+
 	// write to w, meaning, display in the client (browser)
-	p.template.Execute(w, nil)
-	w.WriteHeader(http.StatusOK)
+	p.render(w, http.StatusOK, "game.html", page)
+}
+
+// render writes the page once its template executed in full, a page that fails halfway is logged and answered with
+// an error instead of sent cut off
+func (p *PlayerServer) render(w http.ResponseWriter, status int, name string, data any) {
+	var page bytes.Buffer
+	if err := p.template.ExecuteTemplate(&page, name, data); err != nil {
+		log.Printf("could not render %s, %v\n", name, err)
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("could not render %s", name))
+		return
+	}
+	w.Header().Set("content-type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(page.Bytes())
+}
+
+// loginPage is what login.html needs, where to go once logged in and why the last attempt failed
+type loginPage struct {
+	Next  string
+	Error string
+}
+
+// loginRequest is the json body of a login from an api client
+type loginRequest struct {
+	Name     string
+	Password string
+}
+
+var errNoUsers = errors.New("the server has no users to log in as")
+
+func (p *PlayerServer) showLogin(w http.ResponseWriter, r *http.Request) {
+	if p.auth == nil {
+		writeError(w, http.StatusNotFound, errNoUsers)
+		return
+	}
+	p.render(w, http.StatusOK, "login.html", loginPage{Next: loginRedirect(r.URL.Query().Get("next"))})
+}
+
+// login starts a session from the login page form, setting the session cookie and going on to the next page, or from
+// a json loginRequest, answering with the session so its token can be sent as a bearer token
+func (p *PlayerServer) login(w http.ResponseWriter, r *http.Request) {
+	if p.auth == nil {
+		writeError(w, http.StatusNotFound, errNoUsers)
+		return
+	}
+
+	fromForm := strings.HasPrefix(r.Header.Get("content-type"), "application/x-www-form-urlencoded")
+	var request loginRequest
+	if fromForm {
+		request = loginRequest{Name: r.PostFormValue("name"), Password: r.PostFormValue("password")}
+	} else if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("could not parse login, %v", err))
+		return
+	}
+
+	session, err := p.auth.Login(request.Name, request.Password)
+	if err != nil {
+		if fromForm {
+			p.render(w, http.StatusUnauthorized, "login.html", loginPage{Next: loginRedirect(r.PostFormValue("next")), Error: err.Error()})
+			return
+		}
+		unauthorized(w, err)
+		return
+	}
+
+	setSessionCookie(w, session)
+	if fromForm {
+		http.Redirect(w, r, loginRedirect(r.PostFormValue("next")), http.StatusSeeOther)
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// logout ends the session of the cookie or bearer token
+func (p *PlayerServer) logout(w http.ResponseWriter, r *http.Request) {
+	if p.auth == nil {
+		writeError(w, http.StatusNotFound, errNoUsers)
+		return
+	}
+
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		p.auth.Logout(cookie.Value)
+	}
+	if token, ok := bearerToken(r); ok {
+		p.auth.Logout(token)
+	}
+	clearSessionCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loginRedirect only goes back to pages of this server, anything else goes to the game page
+func loginRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/game"
+	}
+	return next
 }
 
+// webSocketHandler hosts a game, which only organisers can do, or watches the one of ?game= from the message after
+// ?after= when given
 func (p *PlayerServer) webSocketHandler(w http.ResponseWriter, r *http.Request) {
+	if id := r.URL.Query().Get("game"); id != "" {
+		p.watchGame(w, r, id)
+		return
+	}
+	p.allow(RoleOrganiser, p.hostGame)(w, r)
+}
 
-	wsServer := NewPlayerServerWS(w, r)
+// hostGame starts a game from the start message of the host, or rejoins it after the host lost their connection, and
+// broadcasts it through the hub. The host gets the same messages as everyone watching and an error message for each
+// of theirs that went wrong.
+func (p *PlayerServer) hostGame(w http.ResponseWriter, r *http.Request) {
+	wsServer, err := NewPlayerServerWS(w, r, p.WebSocketTimeouts)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer wsServer.Hangup(websocket.CloseNormalClosure, "")
+
+	live, unsubscribe, err := p.joinGame(r.Context(), wsServer)
+	if err != nil {
+		return
+	}
+	defer unsubscribe()
 
-	numberOfPlayersMsg := wsServer.WaitForMsg()
-	numberOfPlayers, _ := strconv.Atoi(numberOfPlayersMsg)
-	p.game.Start(numberOfPlayers, wsServer)
+	for {
+		message, err := wsServer.WaitForMessage()
+		if errors.Is(err, ErrInvalidMessage) {
+			wsServer.SendError(err, 0)
+			continue
+		}
+		if err != nil {
+			return
+		}
 
-	winner := wsServer.WaitForMsg()
-	p.game.Finish(winner)
+		over, err := p.playGame(r.Context(), live, message)
+		if err != nil {
+			// the game goes on, a winner that couldn't be recorded can be sent again
+			wsServer.SendError(err, message.Seq)
+			continue
+		}
+		if over {
+			return
+		}
+	}
 }
 
-func (p *PlayerServer) processWin(w http.ResponseWriter, r *http.Request, playerName string) {
-	p.store.RecordWin(playerName)
+// joinGame waits for a start message the game starts with or a rejoin message of a game being played, the error
+// means the connection is gone
+func (p *PlayerServer) joinGame(ctx context.Context, wsServer *playerServerWS) (*LiveGame, func(), error) {
+	for {
+		message, err := wsServer.WaitForMessage()
+		if errors.Is(err, ErrInvalidMessage) {
+			wsServer.SendError(err, 0)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var live *LiveGame
+		var unsubscribe func()
+		switch message.Type {
+		case StartMessage:
+			live, unsubscribe, err = p.startGame(ctx, wsServer, message)
+		case RejoinMessage:
+			live, unsubscribe, err = p.rejoinGame(wsServer, message)
+		default:
+			err = fmt.Errorf("%w, the game has to start before it can %s", ErrInvalidMessage, message.Type)
+		}
+		if err != nil {
+			wsServer.SendError(err, message.Seq)
+			continue
+		}
+		return live, unsubscribe, nil
+	}
+}
+
+// openGame broadcasts a game that isn't listed until started is called, the game only has a status of its own once it
+// started, before that it would be the one it replaces
+func (p *PlayerServer) openGame() (live *LiveGame, started func()) {
+	var isStarted atomic.Bool
+	live = p.hub.Open(func() (GameStatus, error) {
+		if !isStarted.Load() {
+			return GameStatus{}, ErrGameNotStarted
+		}
+		return p.game.Status()
+	})
+	return live, func() { isStarted.Store(true) }
+}
+
+func (p *PlayerServer) startGame(ctx context.Context, wsServer *playerServerWS, message Message) (*LiveGame, func(), error) {
+	var start StartPayload
+	if err := message.DecodePayload(&start); err != nil {
+		return nil, nil, err
+	}
+	if err := start.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	live, started := p.openGame()
+	_, unsubscribe := live.Subscribe(wsServer)
+
+	if err := p.game.Start(ctx, GameSettings(start), live); err != nil {
+		unsubscribe()
+		p.hub.Close(live.ID, NewMessage(EndedMessage, nil))
+		return nil, nil, err
+	}
+	started()
+	// one game is played at a time, starting this one stopped the clock of any other
+	p.hub.CloseOthers(live.ID)
+
+	status, err := live.Status()
+	if err != nil {
+		status = LiveGameStatus{ID: live.ID}
+	}
+	live.Announce(NewMessage(StartedMessage, status))
+	return live, unsubscribe, nil
+}
+
+// RestoreGame carries on with the game that was being played when the server stopped, the host finds it in the games
+// being played and rejoins it. Restored is false when there was no game to carry on with.
+func (p *PlayerServer) RestoreGame(ctx context.Context) (restored bool, err error) {
+	live, started := p.openGame()
+	restored, err = p.game.Restore(ctx, live)
+	if err != nil || !restored {
+		p.hub.Close(live.ID, NewMessage(EndedMessage, nil))
+		return false, err
+	}
+	started()
+	p.hub.CloseOthers(live.ID)
+	return true, nil
+}
+
+// rejoinGame sends the host the messages they missed and carries on with the game
+func (p *PlayerServer) rejoinGame(wsServer *playerServerWS, message Message) (*LiveGame, func(), error) {
+	var rejoin RejoinPayload
+	if err := message.DecodePayload(&rejoin); err != nil {
+		return nil, nil, err
+	}
+	live, err := p.hub.Get(rejoin.Game)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, unsubscribe := live.Resume(wsServer, rejoin.After)
+	return live, unsubscribe, nil
+}
+
+// playGame acts on a message of the host, over is true once the result was recorded
+func (p *PlayerServer) playGame(ctx context.Context, live *LiveGame, message Message) (over bool, err error) {
+	switch message.Type {
+	case PauseMessage:
+		return false, p.game.Pause()
+	case ResumeMessage:
+		return false, p.game.Resume()
+	case SkipMessage:
+		return false, p.game.SkipLevel()
+
+	case EliminateMessage:
+		var player PlayerPayload
+		if err := message.DecodePayload(&player); err != nil {
+			return false, err
+		}
+		finisher, winner, err := p.game.Eliminate(ctx, player.Player)
+		if err != nil {
+			return false, err
+		}
+		eliminated := EliminatedPayload{Player: finisher.Name, Position: finisher.Position}
+		if winner != "" {
+			// the last player left won and the result is recorded
+			eliminated.PlayersRemaining = 1
+			live.Announce(NewMessage(EliminatedMessage, eliminated))
+			p.hub.Close(live.ID, NewMessage(ResultMessage, PlayerPayload{Player: winner}))
+			return true, nil
+		}
+		if status, err := p.game.Status(); err == nil {
+			eliminated.PlayersRemaining = status.PlayersRemaining
+		}
+		live.Announce(NewMessage(EliminatedMessage, eliminated))
+		return false, nil
+
+	case WinnerMessage:
+		var winner PlayerPayload
+		if err := message.DecodePayload(&winner); err != nil {
+			return false, err
+		}
+		if err := ValidatePlayerName(winner.Player); err != nil {
+			return false, fmt.Errorf("%w, %v", ErrInvalidMessage, err)
+		}
+		if err := p.game.Finish(ctx, winner.Player); err != nil {
+			return false, fmt.Errorf("could not record the win of %s, %w", winner.Player, err)
+		}
+		p.hub.Close(live.ID, NewMessage(ResultMessage, winner))
+		return true, nil
+
+	case RebuyMessage, AddOnMessage, RegisterMessage:
+		var player Entrant
+		if message.Type == RegisterMessage {
+			if err := message.DecodePayload(&player); err != nil {
+				return false, err
+			}
+		} else {
+			var payload PlayerPayload
+			if err := message.DecodePayload(&payload); err != nil {
+				return false, err
+			}
+			player.Name = payload.Player
+		}
+		var err error
+		switch message.Type {
+		case RebuyMessage:
+			err = p.game.Rebuy(ctx, player.Name)
+		case AddOnMessage:
+			err = p.game.AddOn(ctx, player.Name)
+		default:
+			err = p.game.Register(ctx, player)
+		}
+		if err != nil {
+			return false, err
+		}
+		entry := EntryPayload{Player: player.Name, Entry: message.Type}
+		if status, err := p.game.Status(); err == nil {
+			entry.PrizePool, entry.PlayersRemaining = status.PrizePool, status.PlayersRemaining
+		}
+		live.Announce(NewMessage(EntryMessage, entry))
+		return false, nil
+
+	case StartMessage, RejoinMessage:
+		return false, fmt.Errorf("%w, the game already started", ErrInvalidMessage)
+	}
+	return false, fmt.Errorf("%w, unknown message type %q", ErrInvalidMessage, message.Type)
+}
+
+// watchGame sends everything that happens in the game until it's over or the spectator leaves
+func (p *PlayerServer) watchGame(w http.ResponseWriter, r *http.Request, id string) {
+	after := int64(-1)
+	if value := r.URL.Query().Get("after"); value != "" {
+		var err error
+		if after, err = strconv.ParseInt(value, 10, 64); err != nil || after < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("after has to be the number of a message, got %q", value))
+			return
+		}
+	}
+
+	live, err := p.hub.Get(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	wsServer, err := NewPlayerServerWS(w, r, p.WebSocketTimeouts)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	done, unsubscribe := live.Resume(wsServer, after)
+	defer unsubscribe()
+
+	// spectators have nothing to say, reading only notices when they leave or go quiet
+	go func() {
+		for {
+			if _, err := wsServer.WaitForMessage(); errors.Is(err, ErrInvalidMessage) {
+				continue
+			} else if err != nil {
+				unsubscribe()
+				return
+			}
+		}
+	}()
+	<-done
+	wsServer.Hangup(websocket.CloseNormalClosure, "")
+}
+
+func (p *PlayerServer) showGames(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, p.hub.Games())
+}
+
+// watchHandler is the page for the screens that follow a game, like the tv on the wall
+func (p *PlayerServer) watchHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("game")
+	if _, err := p.hub.Get(id); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	p.render(w, http.StatusOK, "watch.html", id)
+}
+
+func (p *PlayerServer) showLeague(w http.ResponseWriter, r *http.Request, leagueID string) {
+	season, err := p.requestSeason(r)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	league, err := p.standings(r.Context(), leagueID, season)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, league)
+}
+
+// standings of the league in the season, past seasons are worked out from the results so they keep their standings
+// after the rollover
+func (p *PlayerServer) standings(ctx context.Context, leagueID string, season Season) (League, error) {
+	if season.ID == AllSeasons {
+		return p.store.GetLeague(ctx, leagueID)
+	}
+	return p.store.GetSeasonLeague(ctx, leagueID, season)
+}
+
+// requestSeason is the season asked for with ?season=, the current one when there's none
+func (p *PlayerServer) requestSeason(r *http.Request) (Season, error) {
+	id := r.URL.Query().Get("season")
+	if id == "" {
+		return p.seasons.Current(), nil
+	}
+	return p.seasons.Season(id)
+}
+
+// playerSeason is the season asked for with ?season=, all time when there's none so the score of a player is still
+// every win they have
+func (p *PlayerServer) playerSeason(r *http.Request) (Season, error) {
+	if r.URL.Query().Get("season") == "" {
+		return AllTime(), nil
+	}
+	return p.requestSeason(r)
+}
+
+func (p *PlayerServer) showSeasons(w http.ResponseWriter, r *http.Request, leagueID string) {
+	results, err := p.store.GetLeagueResults(r.Context(), leagueID, AllTime())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p.seasons.Seasons(results))
+}
+
+func (p *PlayerServer) showLeagues(w http.ResponseWriter, r *http.Request) {
+	leagues, err := p.store.GetLeagues(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, leagues)
+}
+
+// createLeague creates a league from a json {"Name": ..., "Scoring": ...}
+func (p *PlayerServer) createLeague(w http.ResponseWriter, r *http.Request) {
+	var request LeagueInfo
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("could not parse league, %v", err))
+		return
+	}
+
+	league, err := p.store.CreateLeague(r.Context(), request)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+
+	w.Header().Set("location", strings.TrimSuffix(r.URL.Path, "/")+"/"+league.ID)
+	writeJSON(w, http.StatusCreated, league)
+}
+
+func (p *PlayerServer) archiveLeague(w http.ResponseWriter, r *http.Request, leagueID string) {
+	if err := p.store.ArchiveLeague(r.Context(), leagueID); err != nil {
+		storeError(w, err)
+		return
+	}
 	w.WriteHeader(http.StatusAccepted)
+}
 
+func (p *PlayerServer) processWin(w http.ResponseWriter, r *http.Request, leagueID, playerName string) {
+	if err := p.store.RecordWin(r.Context(), leagueID, playerName); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
 }
 
-func (p *PlayerServer) showScore(w http.ResponseWriter, r *http.Request, playerName string) {
-	score := p.store.GetPlayerScore(playerName)
+func (p *PlayerServer) recordResult(w http.ResponseWriter, r *http.Request) {
+	var result GameResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("could not parse game result, %v", err))
+		return
+	}
+
+	if err := p.store.RecordResult(r.Context(), result); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// showResults lists the recorded results, only those of the ?player= when there is one
+func (p *PlayerServer) showResults(w http.ResponseWriter, r *http.Request) {
+	var results []GameResult
+	var err error
+	if playerName := r.URL.Query().Get("player"); playerName != "" {
+		results, err = p.store.GetPlayerResults(r.Context(), playerName)
+	} else {
+		results, err = p.store.GetResults(r.Context())
+	}
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// showScore answers with the plain wins of the player, or their profile when json is asked for
+func (p *PlayerServer) showScore(w http.ResponseWriter, r *http.Request, leagueID, playerName string) {
+	w.Header().Add("vary", "accept")
+	if acceptsJSON(r) {
+		p.showProfile(w, r, leagueID, playerName)
+		return
+	}
+
+	score, err := p.playerScore(r, leagueID, playerName)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+
 	if score == 0 {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s has no wins in %s", playerName, leagueID))
+		return
 	}
-	w.WriteHeader(http.StatusOK)
 	fmt.Fprint(w, score)
 }
+
+// ratings are rebuilt from every result so they always include the last game and the current rating params
+func (p *PlayerServer) getRatings(ctx context.Context) (Ratings, error) {
+	results, err := p.store.GetResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewRatingsFromResults(results, p.ratings), nil
+}
+
+// ratings are the same in every league, the league of the path is not used
+func (p *PlayerServer) showRating(w http.ResponseWriter, r *http.Request, _, playerName string) {
+	ratings, err := p.getRatings(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+
+	rating := ratings.Find(playerName)
+	if rating == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s has no rated games", playerName))
+		return
+	}
+	writeJSON(w, http.StatusOK, rating)
+}
+
+// showRatings has the ratings of every player, best first
+func (p *PlayerServer) showRatings(w http.ResponseWriter, r *http.Request) {
+	ratings, err := p.getRatings(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ratings)
+}
+
+// the profile of the player from the games of the league in the season asked for, all time when there's none
+func (p *PlayerServer) showProfile(w http.ResponseWriter, r *http.Request, leagueID, playerName string) {
+	season, err := p.playerSeason(r)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	results, err := p.store.GetLeagueResults(r.Context(), leagueID, season)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+
+	profile := NewPlayerProfile(playerName, results)
+	if profile.Games == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s has no games in %s", playerName, leagueID))
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// acceptsJSON is true when the accept header lists json before plain text or anything else,
+// so scripts that send no accept header keep getting the plain score
+func acceptsJSON(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("accept"), ",") {
+		mediaType, _, _ := strings.Cut(accepted, ";")
+		switch strings.TrimSpace(mediaType) {
+		case JsonContentType:
+			return true
+		case "text/plain", "text/*", "*/*":
+			return false
+		}
+	}
+	return false
+}
+
+// wins of the player in the season asked for, all time when there's none
+func (p *PlayerServer) playerScore(r *http.Request, leagueID, playerName string) (int, error) {
+	season, err := p.playerSeason(r)
+	if err != nil {
+		return 0, err
+	}
+	if season.ID == AllSeasons {
+		return p.store.GetPlayerScore(r.Context(), leagueID, playerName)
+	}
+
+	league, err := p.standings(r.Context(), leagueID, season)
+	if err != nil {
+		return 0, err
+	}
+	if player := league.Find(playerName); player != nil {
+		return player.Wins, nil
+	}
+	return 0, nil
+}
+
+// storeError answers with what went wrong in the store, invalid input is the client's fault and anything else is ours
+func storeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidGameResult), errors.Is(err, ErrInvalidLeague), errors.Is(err, ErrUnknownSeason),
+		errors.Is(err, ErrInvalidPlayerName):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, ErrLeagueNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrLeagueExists), errors.Is(err, ErrLeagueArchived):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -12,7 +12,7 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 	database, cleanDatabase := createTempFile(t, `[]`)
 	defer cleanDatabase()
 
-	store, err := poker.NewFsPlayerStore(database)
+	store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
 	assertNoError(t, err)
 
 	server := mustMakePlayerServer(t, store, &SpyGame{})
@@ -36,7 +36,7 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 
 		got := getLeagueFromResponse(t, response.Body)
 		want := []poker.Player{
-			{"Andre", 3},
+			{Name: "Andre", Wins: 3, Points: 3, Played: 3, AverageFinish: 1},
 		}
 		assertResponseStatusCode(t, response.Code, http.StatusOK)
 		assertLeague(t, got, want)
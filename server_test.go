@@ -1,10 +1,16 @@
 package poker_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -15,165 +21,1642 @@ import (
 )
 
 type StubPlayerStore struct {
-	Scores   map[string]int
-	WinCalls []string
-	League   []poker.Player
+	Scores     map[string]int
+	WinCalls   []string
+	WinLeagues []string
+	League     []poker.Player
+	Results    []poker.GameResult
+	// the leagues besides the default one
+	Leagues []poker.LeagueInfo
+	// returned by every call when set, like a store that lost its database
+	Err error
 }
 
-func (s *StubPlayerStore) GetPlayerScore(playerName string) int {
-	return s.Scores[playerName]
+func (s *StubPlayerStore) GetPlayerScore(ctx context.Context, leagueID, playerName string) (int, error) {
+	if _, err := s.getLeague(leagueID); err != nil {
+		return 0, err
+	}
+	return s.Scores[playerName], s.Err
+}
+
+func (s *StubPlayerStore) RecordWin(ctx context.Context, leagueID, playerName string) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	league, err := s.getLeague(leagueID)
+	if err != nil {
+		return err
+	}
+	if league.Archived {
+		return fmt.Errorf("%w: %s", poker.ErrLeagueArchived, leagueID)
+	}
+	s.WinCalls = append(s.WinCalls, playerName)
+	s.WinLeagues = append(s.WinLeagues, leagueID)
+	return nil
+}
+
+func (s *StubPlayerStore) GetLeague(ctx context.Context, leagueID string) (poker.League, error) {
+	if _, err := s.getLeague(leagueID); err != nil {
+		return nil, err
+	}
+	return s.League, s.Err
+}
+
+// GetSeasonLeague scores the results of the season, the all time standings are the ones kept in League
+func (s *StubPlayerStore) GetSeasonLeague(ctx context.Context, leagueID string, season poker.Season) (poker.League, error) {
+	if season.ID == poker.AllSeasons {
+		return s.GetLeague(ctx, leagueID)
+	}
+	league, err := s.getLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.GetLeagueResults(ctx, leagueID, season)
+	return poker.NewLeagueFromResults(results, league.ScoringRule()), err
+}
+
+func (s *StubPlayerStore) GetLeagueResults(ctx context.Context, leagueID string, season poker.Season) ([]poker.GameResult, error) {
+	if _, err := s.getLeague(leagueID); err != nil {
+		return nil, err
+	}
+	return poker.ResultsForSeason(poker.ResultsForLeague(s.Results, leagueID), season), s.Err
+}
+
+func (s *StubPlayerStore) RecordResult(ctx context.Context, result poker.GameResult) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	if err := result.Validate(); err != nil {
+		return fmt.Errorf("%w, %v", poker.ErrInvalidGameResult, err)
+	}
+	s.Results = append(s.Results, result)
+	return nil
+}
+
+func (s *StubPlayerStore) GetResults(ctx context.Context) ([]poker.GameResult, error) {
+	return s.Results, s.Err
+}
+
+func (s *StubPlayerStore) GetPlayerResults(ctx context.Context, playerName string) ([]poker.GameResult, error) {
+	return poker.ResultsForPlayer(s.Results, playerName), s.Err
+}
+
+func (s *StubPlayerStore) CreateLeague(ctx context.Context, league poker.LeagueInfo) (poker.LeagueInfo, error) {
+	if s.Err != nil {
+		return poker.LeagueInfo{}, s.Err
+	}
+	league.ID = poker.LeagueID(league.Name)
+	if league.ID == "" {
+		return poker.LeagueInfo{}, poker.ErrInvalidLeague
+	}
+	if _, err := poker.GetScoringRule(league.Scoring); err != nil {
+		return poker.LeagueInfo{}, err
+	}
+	if _, err := s.getLeague(league.ID); err == nil {
+		return poker.LeagueInfo{}, poker.ErrLeagueExists
+	}
+	s.Leagues = append(s.Leagues, league)
+	return league, nil
+}
+
+func (s *StubPlayerStore) GetLeagues(ctx context.Context) ([]poker.LeagueInfo, error) {
+	return append([]poker.LeagueInfo{poker.DefaultLeague()}, s.Leagues...), s.Err
+}
+
+func (s *StubPlayerStore) ArchiveLeague(ctx context.Context, leagueID string) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	for i := range s.Leagues {
+		if s.Leagues[i].ID == leagueID {
+			s.Leagues[i].Archived = true
+			return nil
+		}
+	}
+	return poker.ErrLeagueNotFound
+}
+
+func (s *StubPlayerStore) getLeague(leagueID string) (poker.LeagueInfo, error) {
+	leagues, _ := s.GetLeagues(context.Background())
+	for _, league := range leagues {
+		if league.ID == leagueID {
+			return league, nil
+		}
+	}
+	return poker.LeagueInfo{}, fmt.Errorf("%w: %s", poker.ErrLeagueNotFound, leagueID)
+}
+
+func newGetScoreRequest(name string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/players/%s", name), nil)
+	return req
+}
+
+func newGetRatingRequest(name string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/players/%s/rating", name), nil)
+	return req
+}
+
+func newPostWinRequest(name string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/players/%s", name), nil)
+	return req
+}
+
+func newGetLeagueRequest() *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "/league", nil)
+	return req
+}
+
+func newPostResultRequest(t testing.TB, result poker.GameResult) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("could not encode result %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/results", bytes.NewReader(body))
+	return req
+}
+
+func newGetResultsRequest(player string) *http.Request {
+	path := "/results"
+	if player != "" {
+		path += "?player=" + player
+	}
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	return req
+}
+
+func newPostLeagueRequest(name string) *http.Request {
+	return newPostScoredLeagueRequest(name, "")
+}
+
+func newPostScoredLeagueRequest(name, scoring string) *http.Request {
+	body := fmt.Sprintf(`{"Name": %q, "Scoring": %q}`, name, scoring)
+	req, _ := http.NewRequest(http.MethodPost, "/leagues", strings.NewReader(body))
+	return req
+}
+
+func newLeagueRequest(method, path string) *http.Request {
+	req, _ := http.NewRequest(method, "/leagues/"+path, nil)
+	return req
+}
+
+// allSeasons asks for the all time standings, which the stub keeps in League and Scores
+func allSeasons(request *http.Request) *http.Request {
+	return withSeason(request, poker.AllSeasons)
+}
+
+func withSeason(request *http.Request, season string) *http.Request {
+	query := request.URL.Query()
+	query.Set("season", season)
+	request.URL.RawQuery = query.Encode()
+	return request
+}
+
+func newGetGameRequest() *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "/game", nil)
+	return req
+}
+
+func TestPlayerServer(t *testing.T) {
+	store := StubPlayerStore{
+		Scores: map[string]int{
+			"Andre": 20,
+			"Chris": 40,
+		},
+	}
+	server := mustMakePlayerServer(t, &store, &SpyGame{})
+
+	t.Run("successful player get 1", func(t *testing.T) {
+		request := newGetScoreRequest("Andre")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(), "20")
+	})
+
+	t.Run("successful player get 2", func(t *testing.T) {
+		request := newGetScoreRequest("Chris")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(), "40")
+	})
+
+	t.Run("404 player not found", func(t *testing.T) {
+		request := newGetScoreRequest("John")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func TestStoreWins(t *testing.T) {
+	store := StubPlayerStore{Scores: map[string]int{}}
+	server := mustMakePlayerServer(t, &store, &SpyGame{})
+
+	t.Run("POST accepted", func(t *testing.T) {
+		playerName := "Andre"
+		request := newPostWinRequest(playerName)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+		assertPlayerWin(t, &store, playerName)
+	})
+
+	t.Run("POST fails when the win can't be recorded", func(t *testing.T) {
+		failing := &StubPlayerStore{Err: errors.New("disk full")}
+		server := mustMakePlayerServer(t, failing, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostWinRequest("Andre"))
+
+		assertResponseStatusCode(t, response.Code, http.StatusInternalServerError)
+		assertErrorResponse(t, response, http.StatusInternalServerError, "disk full")
+	})
+}
+
+func TestLeague(t *testing.T) {
+
+	t.Run("/league?season=all returns 200", func(t *testing.T) {
+		wantedLeague := []poker.Player{
+			{Name: "Andre", Wins: 32, Points: 32, Played: 40, AverageFinish: 1.5},
+			{Name: "Chris", Wins: 20, Points: 20, Played: 35, AverageFinish: 2},
+			{Name: "John", Wins: 13, Points: 13, Played: 30, AverageFinish: 2.25},
+		}
+
+		store := StubPlayerStore{League: wantedLeague}
+		server := mustMakePlayerServer(t, &store, &SpyGame{})
+
+		request := allSeasons(newGetLeagueRequest())
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		got := getLeagueFromResponse(t, response.Body)
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertLeague(t, got, wantedLeague)
+		assertContentType(t, response, poker.JsonContentType)
+
+	})
+
+	t.Run("/league returns 500 when the store fails", func(t *testing.T) {
+		store := &StubPlayerStore{Err: errors.New("disk full")}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetLeagueRequest())
+
+		assertResponseStatusCode(t, response.Code, http.StatusInternalServerError)
+	})
+}
+
+func TestLeagues(t *testing.T) {
+	t.Run("POST creates a league", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostLeagueRequest("Friday Night"))
+
+		assertResponseStatusCode(t, response.Code, http.StatusCreated)
+		assertContentType(t, response, poker.JsonContentType)
+		if got := response.Header().Get("location"); got != "/leagues/friday-night" {
+			t.Errorf("got location %q, want /leagues/friday-night", got)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newPostLeagueRequest("friday night"))
+		assertResponseStatusCode(t, response.Code, http.StatusConflict)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newPostLeagueRequest("!!"))
+		assertResponseStatusCode(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("leagues are ranked by the scoring rule they were created with", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostScoredLeagueRequest("Grand Prix", poker.FormulaOneScoring))
+		assertResponseStatusCode(t, response.Code, http.StatusCreated)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newPostScoredLeagueRequest("Golf", "strokes"))
+		assertResponseStatusCode(t, response.Code, http.StatusBadRequest)
+
+		store.Results = []poker.GameResult{{
+			ID:        "game-1",
+			League:    "grand-prix",
+			Finished:  time.Now(),
+			Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Chris", Position: 2}},
+		}}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodGet, "grand-prix"))
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertLeague(t, getLeagueFromResponse(t, response.Body), []poker.Player{
+			{Name: "Andre", Wins: 1, Points: 25, Played: 1, AverageFinish: 1},
+			{Name: "Chris", Wins: 0, Points: 18, Played: 1, AverageFinish: 2},
+		})
+	})
+
+	t.Run("GET lists the leagues", func(t *testing.T) {
+		store := &StubPlayerStore{Leagues: []poker.LeagueInfo{{ID: "online", Name: "Online"}}}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		request, _ := http.NewRequest(http.MethodGet, "/leagues", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var got []poker.LeagueInfo
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse leagues from response, %v", err)
+		}
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertContentType(t, response, poker.JsonContentType)
+		want := []poker.LeagueInfo{poker.DefaultLeague(), {ID: "online", Name: "Online"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got leagues %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("GET /leagues/{id} returns the standings of the league", func(t *testing.T) {
+		wantedLeague := []poker.Player{{Name: "Andre", Wins: 3, Points: 3}, {Name: "Chris", Wins: 1, Points: 1}}
+		store := &StubPlayerStore{League: wantedLeague, Leagues: []poker.LeagueInfo{{ID: "online", Name: "Online"}}}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, allSeasons(newLeagueRequest(http.MethodGet, "online")))
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertContentType(t, response, poker.JsonContentType)
+		assertLeague(t, getLeagueFromResponse(t, response.Body), wantedLeague)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodGet, "nowhere"))
+		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("players record wins and get scores inside a league", func(t *testing.T) {
+		store := &StubPlayerStore{Scores: map[string]int{"Andre": 4}, Leagues: []poker.LeagueInfo{{ID: "online", Name: "Online"}}}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodPost, "online/players/Chris"))
+
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+		assertPlayerWin(t, store, "Chris")
+		if !reflect.DeepEqual(store.WinLeagues, []string{"online"}) {
+			t.Errorf("got wins recorded in %v, want [online]", store.WinLeagues)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodGet, "online/players/Andre"))
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(), "4")
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodPost, "nowhere/players/Chris"))
+		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("archived leagues don't take wins", func(t *testing.T) {
+		store := &StubPlayerStore{Leagues: []poker.LeagueInfo{{ID: "online", Name: "Online"}}}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodPost, "online/archive"))
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodPost, "online/players/Chris"))
+		assertResponseStatusCode(t, response.Code, http.StatusConflict)
+	})
+
+	t.Run("unknown league paths are not found", func(t *testing.T) {
+		server := mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		for _, path := range []string{"default/wins", "default/players", "default/players/Andre/wins"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newLeagueRequest(http.MethodGet, path))
+			assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestLeagueSeasons(t *testing.T) {
+	seasons := poker.SeasonCalendar{Length: poker.Quarterly}
+	current := seasons.Current()
+	previous := seasons.SeasonAt(current.Start.Add(-time.Hour))
+
+	won := func(id, winner string, finished time.Time) poker.GameResult {
+		return poker.GameResult{
+			ID:       id,
+			League:   poker.DefaultLeagueID,
+			Finished: finished,
+			Finishers: []poker.Finisher{
+				{Name: winner, Position: 1},
+				{Name: "John", Position: 2},
+			},
+		}
+	}
+	store := &StubPlayerStore{Results: []poker.GameResult{
+		won("game-1", "Chris", previous.Start.Add(time.Hour)),
+		won("game-2", "Chris", previous.End.Add(-time.Hour)),
+		won("game-3", "Andre", current.Start),
+		{ID: "game-4", League: "online", Finished: current.Start, Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}},
+	}}
+	server := mustMakePlayerServer(t, store, &SpyGame{})
+
+	t.Run("the league shows the current season", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetLeagueRequest())
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertLeague(t, getLeagueFromResponse(t, response.Body), []poker.Player{
+			{Name: "Andre", Wins: 1, Points: 1, Played: 1, AverageFinish: 1},
+			{Name: "John", Wins: 0, Points: 0, Played: 1, AverageFinish: 2},
+		})
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withSeason(newGetScoreRequest("Chris"), current.ID))
+		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("the score of a player is every win they have unless a season is asked for", func(t *testing.T) {
+		store := &StubPlayerStore{Scores: map[string]int{"Chris": 5}, Results: store.Results}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetScoreRequest("Chris"))
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(), "5")
+	})
+
+	t.Run("past seasons keep their standings", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, withSeason(newGetLeagueRequest(), previous.ID))
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertLeague(t, getLeagueFromResponse(t, response.Body), []poker.Player{
+			{Name: "Chris", Wins: 2, Points: 2, Played: 2, AverageFinish: 1},
+			{Name: "John", Wins: 0, Points: 0, Played: 2, AverageFinish: 2},
+		})
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withSeason(newGetScoreRequest("Chris"), previous.ID))
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(), "2")
+	})
+
+	t.Run("unknown seasons are bad requests", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, withSeason(newGetLeagueRequest(), "2024-13"))
+		assertResponseStatusCode(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("GET /leagues/{id}/seasons lists the seasons with standings", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodGet, "default/seasons"))
+
+		var got []poker.Season
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse seasons from response, %v", err)
+		}
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertContentType(t, response, poker.JsonContentType)
+		want := []poker.Season{current, previous}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got seasons %+v, want %+v", got, want)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(http.MethodGet, "nowhere/seasons"))
+		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func TestPlayerProfiles(t *testing.T) {
+	finished := time.Now().UTC().Truncate(time.Second)
+	store := &StubPlayerStore{
+		Scores: map[string]int{"Andre": 1},
+		Results: []poker.GameResult{{
+			ID:        "game-1",
+			League:    poker.DefaultLeagueID,
+			Finished:  finished,
+			BuyIn:     10,
+			Finishers: []poker.Finisher{{Name: "Andre", Position: 1, Payout: 20}, {Name: "Chris", Position: 2}},
+		}},
+	}
+	server := mustMakePlayerServer(t, store, &SpyGame{})
+
+	t.Run("json is the profile of the player", func(t *testing.T) {
+		request := newGetScoreRequest("Andre")
+		request.Header.Set("accept", "application/json, text/plain;q=0.5")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var got poker.PlayerProfile
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse profile from response, %v", err)
+		}
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertContentType(t, response, poker.JsonContentType)
+		want := poker.NewPlayerProfile("Andre", store.Results)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got profile %+v, want %+v", got, want)
+		}
+		if got := response.Header().Get("vary"); got != "accept" {
+			t.Errorf("got vary header %q, want accept", got)
+		}
+	})
+
+	t.Run("anything else is the plain score", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "text/plain", "text/plain, application/json"} {
+			request := newGetScoreRequest("Andre")
+			request.Header.Set("accept", accept)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertResponseStatusCode(t, response.Code, http.StatusOK)
+			assertResponseBody(t, response.Body.String(), "1")
+		}
+	})
+
+	t.Run("players without games have no profile", func(t *testing.T) {
+		request := newLeagueRequest(http.MethodGet, "default/players/Bob")
+		request.Header.Set("accept", poker.JsonContentType)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+		assertContentType(t, response, poker.JsonContentType)
+	})
+}
+
+func TestRatings(t *testing.T) {
+	store := &StubPlayerStore{Results: []poker.GameResult{
+		{ID: "game-1", Finishers: []poker.Finisher{{Name: "Andre", Position: 1}, {Name: "Chris", Position: 2}}},
+		{ID: "game-2", Finishers: []poker.Finisher{{Name: "John", Position: 1}}},
+	}}
+	server := mustMakePlayerServer(t, store, &SpyGame{})
+
+	t.Run("GET /ratings returns the leaderboard", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/ratings", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var got poker.Ratings
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse ratings from response, %v", err)
+		}
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertContentType(t, response, poker.JsonContentType)
+		assertRatings(t, got, poker.NewRatingsFromResults(store.Results, poker.DefaultRatingParams()))
+	})
+
+	t.Run("GET /players/{name}/rating returns the rating of the player", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetRatingRequest("Andre"))
+
+		var got poker.Rating
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse rating from response, %v", err)
+		}
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertContentType(t, response, poker.JsonContentType)
+		if want := (poker.Rating{Name: "Andre", Rating: 1516, Games: 1}); got != want {
+			t.Errorf("got rating %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("players without rated games are not found", func(t *testing.T) {
+		for _, name := range []string{"John", "Bob"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newGetRatingRequest(name))
+			assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/players/Andre/wins", nil))
+		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("store failures are server errors", func(t *testing.T) {
+		server := mustMakePlayerServer(t, &StubPlayerStore{Err: errors.New("disk full")}, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetRatingRequest("Andre"))
+		assertResponseStatusCode(t, response.Code, http.StatusInternalServerError)
+	})
+
+	t.Run("the server needs valid rating params", func(t *testing.T) {
+		_, err := poker.NewPlayerServer(store, &SpyGame{}, poker.SeasonCalendar{Length: poker.Quarterly}, poker.RatingParams{}, nil)
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestRESTSemantics(t *testing.T) {
+	store := &StubPlayerStore{Scores: map[string]int{"Andre Silva": 3}}
+	server := mustMakePlayerServer(t, store, &SpyGame{})
+
+	t.Run("unsupported methods are not allowed", func(t *testing.T) {
+		tests := []struct {
+			method, path, allow string
+		}{
+			{http.MethodDelete, "/players/Andre", "GET, POST"},
+			{http.MethodPut, "/league", "GET"},
+			{http.MethodGet, "/leagues/default/archive", "POST"},
+			{http.MethodPost, "/api/v1/ratings", "GET"},
+		}
+
+		for _, tt := range tests {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assertResponseStatusCode(t, response.Code, http.StatusMethodNotAllowed)
+			assertErrorResponse(t, response, http.StatusMethodNotAllowed, tt.method)
+			if got := response.Header().Get("allow"); got != tt.allow {
+				t.Errorf("got allow %q for %s %s, want %q", got, tt.method, tt.path, tt.allow)
+			}
+		}
+	})
+
+	t.Run("the api is versioned", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/players/Andre%20Silva", nil))
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(), "3")
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v2/league", nil))
+		assertErrorResponse(t, response, http.StatusNotFound, "/api/v2/league")
+	})
+
+	t.Run("player names are url decoded and validated", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/players/Chris%20Jones", nil))
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+		assertPlayerWin(t, store, "Chris Jones")
+
+		for _, path := range []string{
+			"/players/%20Andre",
+			"/players/Andre%2FChris",
+			"/players/" + strings.Repeat("a", poker.MaxPlayerNameLength+1),
+			"/players/Andre%0A",
+		} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, path, nil))
+			assertErrorResponse(t, response, http.StatusBadRequest, poker.ErrInvalidPlayerName.Error())
+		}
+	})
+
+	t.Run("unknown players are json errors", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetScoreRequest("Bob"))
+		assertErrorResponse(t, response, http.StatusNotFound, "Bob")
+	})
+}
+
+func TestOpenAPI(t *testing.T) {
+	server := mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{})
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	assertResponseStatusCode(t, response.Code, http.StatusOK)
+	assertContentType(t, response, poker.JsonContentType)
+
+	var document struct {
+		Paths map[string]map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(response.Body).Decode(&document); err != nil {
+		t.Fatalf("could not parse openapi document, %v", err)
+	}
+
+	// every documented operation is routed
+	for path, operations := range document.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			path := strings.NewReplacer("{name}", "Andre", "{league}", "default").Replace(path)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(strings.ToUpper(method), "/api/v1"+path, strings.NewReader("{}")))
+
+			// the router answers paths it doesn't know with the path not found
+			unrouted := response.Code == http.StatusNotFound && strings.Contains(response.Body.String(), path+" not found")
+			if response.Code == http.StatusMethodNotAllowed || unrouted {
+				t.Errorf("%s %s is documented but not routed, got %d", method, path, response.Code)
+			}
+		}
+	}
+}
+
+func TestResults(t *testing.T) {
+	result := poker.GameResult{
+		ID:         "game-1",
+		NumPlayers: 3,
+		BuyIn:      10,
+		Finishers: []poker.Finisher{
+			{Name: "Andre", Position: 1, Payout: 20},
+			{Name: "Chris", Position: 2, Payout: 10},
+			{Name: "John", Position: 3},
+		},
+	}
+
+	t.Run("POST records the result", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostResultRequest(t, result))
+
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+		assertResults(t, store.Results, []poker.GameResult{result})
+	})
+
+	t.Run("POST with an invalid result is a bad request", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostResultRequest(t, poker.GameResult{ID: "no finishers"}))
+
+		assertResponseStatusCode(t, response.Code, http.StatusBadRequest)
+		assertResults(t, store.Results, nil)
+	})
+
+	t.Run("GET returns the results as json", func(t *testing.T) {
+		other := poker.GameResult{ID: "game-2", Finishers: []poker.Finisher{{Name: "Chris", Position: 1}}}
+		store := &StubPlayerStore{Results: []poker.GameResult{result, other}}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetResultsRequest(""))
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertContentType(t, response, poker.JsonContentType)
+		assertResults(t, getResultsFromResponse(t, response.Body), []poker.GameResult{result, other})
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGetResultsRequest("John"))
+
+		assertResults(t, getResultsFromResponse(t, response.Body), []poker.GameResult{result})
+	})
+
+	t.Run("store failures are server errors", func(t *testing.T) {
+		store := &StubPlayerStore{Err: errors.New("disk full")}
+		server := mustMakePlayerServer(t, store, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostResultRequest(t, result))
+		assertResponseStatusCode(t, response.Code, http.StatusInternalServerError)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGetResultsRequest(""))
+		assertResponseStatusCode(t, response.Code, http.StatusInternalServerError)
+	})
+}
+
+func TestGame(t *testing.T) {
+
+	t.Run("get /game returns 200", func(t *testing.T) {
+		server := mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		request := newGetGameRequest()
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		season := poker.SeasonCalendar{Length: poker.Quarterly}.Current()
+		if !strings.Contains(response.Body.String(), "Season "+season.String()) {
+			t.Errorf("the game page doesn't show the current season %s", season)
+		}
+		// the page looks for the game the server carried on with after a restart when rejoining fails with the code
+		if !strings.Contains(response.Body.String(), "'"+poker.GameNotFoundCode+"'") {
+			t.Errorf("the game page doesn't act on the %s error code", poker.GameNotFoundCode)
+		}
+	})
+
+	t.Run("start a game with 3 players and declare Andre the winner", func(t *testing.T) {
+		game := &SpyGame{BlindAlert: []byte("Blind is 100")}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		// need a persistent connection to a server to test, hence the test server
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		sendWSMessage(t, ws, 1, poker.StartMessage, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris"), BlindStructure: "turbo", League: "friday"})
+
+		var text poker.TextPayload
+		assertWSMessage(t, ws, poker.TextMessage, &text)
+		if text.Text != "Blind is 100" {
+			t.Errorf("got text %q want the blind alert", text.Text)
+		}
+		var started poker.LiveGameStatus
+		assertWSMessage(t, ws, poker.StartedMessage, &started)
+		if started.ID == "" || started.PlayersRemaining != 3 {
+			t.Errorf("got started %+v", started)
+		}
+
+		sendWSMessage(t, ws, 2, poker.WinnerMessage, poker.PlayerPayload{Player: "Andre"})
+		var result poker.PlayerPayload
+		assertWSMessage(t, ws, poker.ResultMessage, &result)
+		if result.Player != "Andre" {
+			t.Errorf("got result %+v want Andre to win", result)
+		}
+
+		assertStartCalledWith(t, game, 3)
+		assertStartCalledWithBlinds(t, game, "turbo")
+		assertStartCalledInLeague(t, game, "friday")
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("messages are numbered in the order they were sent", func(t *testing.T) {
+		game := &SpyGame{BlindAlert: []byte("Blind is 100")}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		sendWSMessage(t, ws, 1, poker.StartMessage, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+		sendWSMessage(t, ws, 2, poker.PauseMessage, nil)
+		sendWSMessage(t, ws, 3, poker.WinnerMessage, poker.PlayerPayload{Player: "Andre"})
+
+		for i, want := range []poker.MessageType{poker.TextMessage, poker.StartedMessage, poker.ResultMessage} {
+			got := assertWSMessage(t, ws, want, nil)
+			if got.Seq != int64(i+1) {
+				t.Errorf("got %s numbered %d want %d", got.Type, got.Seq, i+1)
+			}
+		}
+		assertClockCommands(t, game, "pause")
+	})
+
+	t.Run("tell the host when the win can't be recorded and take the winner again", func(t *testing.T) {
+		game := &SpyGame{FinishError: errors.New("disk full")}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+		startWSGame(t, ws, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+
+		sendWSMessage(t, ws, 2, poker.WinnerMessage, poker.PlayerPayload{Player: "Andre"})
+		assertWSError(t, ws, 2, "could not record the win of Andre, disk full")
+
+		sendWSMessage(t, ws, 3, poker.WinnerMessage, poker.PlayerPayload{Player: "Andre"})
+		assertWSMessage(t, ws, poker.ResultMessage, nil)
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("messages that can't be acted on are answered with errors", func(t *testing.T) {
+		game := &SpyGame{ClockError: poker.ErrClockNotPaused}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		writeWSMessage(t, ws, "3")
+		assertWSError(t, ws, 0, poker.ErrInvalidMessage.Error())
+
+		sendWSMessage(t, ws, 1, poker.PauseMessage, nil)
+		assertWSError(t, ws, 1, "the game has to start before it can pause")
+
+		sendWSMessage(t, ws, 2, poker.StartMessage, poker.StartPayload{})
+		assertWSError(t, ws, 2, "a game needs at least 2 players, got 0")
+
+		sendWSMessage(t, ws, 3, poker.StartMessage, nil)
+		assertWSError(t, ws, 3, "start has no payload")
+		assertGameNotStarted(t, game)
+
+		sendWSMessage(t, ws, 4, poker.StartMessage, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+		assertWSMessage(t, ws, poker.StartedMessage, nil)
+
+		sendWSMessage(t, ws, 5, poker.StartMessage, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+		assertWSError(t, ws, 5, "the game already started")
+
+		sendWSMessage(t, ws, 6, "shuffle", nil)
+		assertWSError(t, ws, 6, `unknown message type "shuffle"`)
+
+		sendWSMessage(t, ws, 7, poker.ResumeMessage, nil)
+		assertWSError(t, ws, 7, poker.ErrClockNotPaused.Error())
+
+		sendWSMessage(t, ws, 8, poker.WinnerMessage, poker.PlayerPayload{Player: ""})
+		assertWSError(t, ws, 8, poker.ErrInvalidPlayerName.Error())
+	})
+
+	t.Run("a game that can't start is sent back and the host can try again", func(t *testing.T) {
+		game := &SpyGame{StartError: poker.ErrUnknownBlindStructure}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		sendWSMessage(t, ws, 1, poker.StartMessage, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris"), BlindStructure: "glacial"})
+		assertWSError(t, ws, 1, poker.ErrUnknownBlindStructure.Error())
+
+		game.StartError = nil
+		sendWSMessage(t, ws, 2, poker.StartMessage, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+		assertWSMessage(t, ws, poker.StartedMessage, nil)
+	})
+
+	t.Run("eliminations are announced with the players remaining", func(t *testing.T) {
+		game := &SpyGame{}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+		startWSGame(t, ws, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+
+		sendWSMessage(t, ws, 2, poker.EliminateMessage, poker.PlayerPayload{Player: "Chris"})
+
+		var eliminated poker.EliminatedPayload
+		assertWSMessage(t, ws, poker.EliminatedMessage, &eliminated)
+		want := poker.EliminatedPayload{Player: "Chris", Position: 3, PlayersRemaining: 2}
+		if eliminated != want {
+			t.Errorf("got %+v want %+v", eliminated, want)
+		}
+	})
+
+	t.Run("knocking out the second to last player ends the game with the last one the winner", func(t *testing.T) {
+		game := &SpyGame{}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+		startWSGame(t, ws, poker.StartPayload{Players: poker.Roster("Andre", "Chris")})
+
+		sendWSMessage(t, ws, 2, poker.EliminateMessage, poker.PlayerPayload{Player: "Chris"})
+
+		var eliminated poker.EliminatedPayload
+		assertWSMessage(t, ws, poker.EliminatedMessage, &eliminated)
+		if want := (poker.EliminatedPayload{Player: "Chris", Position: 2, PlayersRemaining: 1}); eliminated != want {
+			t.Errorf("got %+v want %+v", eliminated, want)
+		}
+		var result poker.PlayerPayload
+		assertWSMessage(t, ws, poker.ResultMessage, &result)
+		if result.Player != "Andre" {
+			t.Errorf("got result %+v want Andre to win", result)
+		}
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("entries are announced with the prize pool", func(t *testing.T) {
+		game := &SpyGame{GameStatus: poker.GameStatus{PrizePool: 50}}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+		startWSGame(t, ws, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris"), BuyIn: 10, Entries: poker.EntryRules{RebuyLevels: 2}})
+		if game.StartedWithBuyIn != 10 || game.StartedWithRules.RebuyLevels != 2 {
+			t.Errorf("got game started with a buy-in of %d and rules %+v", game.StartedWithBuyIn, game.StartedWithRules)
+		}
+
+		sendWSMessage(t, ws, 2, poker.RebuyMessage, poker.PlayerPayload{Player: "Chris"})
+		sendWSMessage(t, ws, 3, poker.AddOnMessage, poker.PlayerPayload{Player: "Bob"})
+		sendWSMessage(t, ws, 4, poker.RegisterMessage, poker.Entrant{Name: "Mary"})
+
+		for _, want := range []poker.EntryPayload{
+			{Player: "Chris", Entry: poker.RebuyMessage, PrizePool: 50, PlayersRemaining: 3},
+			{Player: "Bob", Entry: poker.AddOnMessage, PrizePool: 50, PlayersRemaining: 3},
+			{Player: "Mary", Entry: poker.RegisterMessage, PrizePool: 50, PlayersRemaining: 4},
+		} {
+			var entry poker.EntryPayload
+			assertWSMessage(t, ws, poker.EntryMessage, &entry)
+			if entry != want {
+				t.Errorf("got %+v want %+v", entry, want)
+			}
+		}
+		if !reflect.DeepEqual(game.Rebuys, []string{"Chris"}) || !reflect.DeepEqual(game.AddOns, []string{"Bob"}) || !reflect.DeepEqual(game.Registered, []string{"Mary"}) {
+			t.Errorf("got rebuys %v, add-ons %v and registered %v", game.Rebuys, game.AddOns, game.Registered)
+		}
+
+		game.EntryError = poker.ErrEntriesClosed
+		sendWSMessage(t, ws, 5, poker.RebuyMessage, poker.PlayerPayload{Player: "Chris"})
+		assertWSError(t, ws, 5, poker.ErrEntriesClosed.Error())
+	})
+
+	t.Run("the seats of a tournament are announced to the host", func(t *testing.T) {
+		tournament, err := poker.NewTournament(&SpyGame{}, 2, rand.New(rand.NewSource(1)))
+		assertNoError(t, err)
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, tournament))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+		sendWSMessage(t, ws, 1, poker.StartMessage, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+
+		tables := map[int]int{}
+		for i := 0; i < 3; i++ {
+			var seat poker.SeatPayload
+			assertWSMessage(t, ws, poker.SeatMessage, &seat)
+			tables[seat.Table]++
+		}
+		if tables[1]+tables[2] != 3 || tables[1] < 1 || tables[2] < 1 {
+			t.Errorf("got players seated at tables %v want all 3 over tables 1 and 2", tables)
+		}
+		assertWSMessage(t, ws, poker.StartedMessage, nil)
+	})
 }
 
-func (s *StubPlayerStore) RecordWin(playerName string) {
-	s.WinCalls = append(s.WinCalls, playerName)
-}
+func TestAuthorization(t *testing.T) {
+	users := []poker.User{
+		{Name: "Andre", Role: poker.RoleOrganiser, PasswordHash: mustHashPassword(t, "s3cret"), TokenHashes: []string{poker.HashToken("andre-token")}},
+		{Name: "Chris", Role: poker.RolePlayer, TokenHashes: []string{poker.HashToken("chris-token")}},
+		{Name: "Mary", Role: poker.RoleAdmin, TokenHashes: []string{poker.HashToken("mary-token")}},
+	}
+	newServer := func(t *testing.T, store *StubPlayerStore, game *SpyGame) *poker.PlayerServer {
+		t.Helper()
+		server, err := poker.NewPlayerServer(store, game, poker.SeasonCalendar{Length: poker.Quarterly}, poker.DefaultRatingParams(),
+			mustMakeAuthenticator(t, users, time.Hour))
+		if err != nil {
+			t.Fatal("problem creating player server", err)
+		}
+		return server
+	}
+	withToken := func(request *http.Request, token string) *http.Request {
+		request.Header.Set("authorization", "Bearer "+token)
+		return request
+	}
+
+	t.Run("anonymous requests are unauthorized", func(t *testing.T) {
+		server := newServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetLeagueRequest())
+
+		assertErrorResponse(t, response, http.StatusUnauthorized, "log in")
+		if got := response.Header().Get("www-authenticate"); !strings.HasPrefix(got, "Bearer") {
+			t.Errorf("got www-authenticate %q want a bearer challenge", got)
+		}
+	})
+
+	t.Run("a bad token is unauthorized", func(t *testing.T) {
+		server := newServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newGetLeagueRequest(), "made-up"))
+
+		assertErrorResponse(t, response, http.StatusUnauthorized, poker.ErrInvalidToken.Error())
+	})
+
+	t.Run("players can read but not record wins", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		server := newServer(t, store, &SpyGame{})
 
-func (s *StubPlayerStore) GetLeague() poker.League {
-	return s.League
-}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newGetLeagueRequest(), "chris-token"))
+		assertResponseStatusCode(t, response.Code, http.StatusOK)
 
-func newGetScoreRequest(name string) *http.Request {
-	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/players/%s", name), nil)
-	return req
-}
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newPostWinRequest("Chris"), "chris-token"))
+		assertErrorResponse(t, response, http.StatusForbidden, "needs the organiser role")
+		if len(store.WinCalls) != 0 {
+			t.Errorf("a player recorded wins %v", store.WinCalls)
+		}
+	})
 
-func newPostWinRequest(name string) *http.Request {
-	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/players/%s", name), nil)
-	return req
-}
+	t.Run("organisers record wins", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		server := newServer(t, store, &SpyGame{})
 
-func newGetLeagueRequest() *http.Request {
-	req, _ := http.NewRequest(http.MethodGet, "/league", nil)
-	return req
-}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newPostWinRequest("Andre"), "andre-token"))
 
-func newGetGameRequest() *http.Request {
-	req, _ := http.NewRequest(http.MethodGet, "/game", nil)
-	return req
-}
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+		assertPlayerWin(t, store, "Andre")
+	})
 
-func TestPlayerServer(t *testing.T) {
-	store := StubPlayerStore{
-		map[string]int{
-			"Andre": 20,
-			"Chris": 40,
-		},
-		[]string{},
-		nil,
-	}
-	server := mustMakePlayerServer(t, &store, &SpyGame{})
+	t.Run("only admins manage leagues", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		server := newServer(t, store, &SpyGame{})
 
-	t.Run("successful player get 1", func(t *testing.T) {
-		request := newGetScoreRequest("Andre")
 		response := httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newPostLeagueRequest("Online"), "andre-token"))
+		assertErrorResponse(t, response, http.StatusForbidden, "needs the admin role")
 
-		server.ServeHTTP(response, request)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newPostLeagueRequest("Online"), "mary-token"))
+		assertResponseStatusCode(t, response.Code, http.StatusCreated)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newLeagueRequest(http.MethodPost, "online/archive"), "andre-token"))
+		assertErrorResponse(t, response, http.StatusForbidden, "needs the admin role")
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newLeagueRequest(http.MethodPost, "online/archive"), "mary-token"))
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+		if len(store.Leagues) != 1 || !store.Leagues[0].Archived {
+			t.Errorf("got leagues %+v, want online archived", store.Leagues)
+		}
+
+		// admins can do what organisers do
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newPostWinRequest("Mary"), "mary-token"))
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+	})
 
+	t.Run("a json login hands out a bearer token until logout", func(t *testing.T) {
+		server := newServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(`{"Name": "Andre", "Password": "s3cret"}`)))
 		assertResponseStatusCode(t, response.Code, http.StatusOK)
-		assertResponseBody(t, response.Body.String(), "20")
+
+		var session poker.Session
+		if err := json.NewDecoder(response.Body).Decode(&session); err != nil {
+			t.Fatalf("could not parse session, %v", err)
+		}
+		if session.Role != poker.RoleOrganiser {
+			t.Errorf("got role %q want %q", session.Role, poker.RoleOrganiser)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newPostWinRequest("Andre"), session.Token))
+		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(httptest.NewRequest(http.MethodPost, "/logout", nil), session.Token))
+		assertResponseStatusCode(t, response.Code, http.StatusNoContent)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newGetLeagueRequest(), session.Token))
+		assertResponseStatusCode(t, response.Code, http.StatusUnauthorized)
 	})
 
-	t.Run("successful player get 2", func(t *testing.T) {
-		request := newGetScoreRequest("Chris")
+	t.Run("a wrong password is unauthorized", func(t *testing.T) {
+		server := newServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(`{"Name": "Andre", "Password": "guess"}`)))
+
+		assertErrorResponse(t, response, http.StatusUnauthorized, poker.ErrInvalidCredentials.Error())
+	})
+
+	t.Run("the game page sends anonymous visitors to log in and back", func(t *testing.T) {
+		server := newServer(t, &StubPlayerStore{}, &SpyGame{})
+
 		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetGameRequest())
+		assertResponseStatusCode(t, response.Code, http.StatusSeeOther)
+		if got := response.Header().Get("location"); got != "/login?next=%2Fgame" {
+			t.Errorf("got location %q want the login page", got)
+		}
 
+		form := url.Values{"name": {"Andre"}, "password": {"s3cret"}, "next": {"/game"}}
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		request.Header.Set("content-type", "application/x-www-form-urlencoded")
+		response = httptest.NewRecorder()
 		server.ServeHTTP(response, request)
+		assertResponseStatusCode(t, response.Code, http.StatusSeeOther)
+		if got := response.Header().Get("location"); got != "/game" {
+			t.Errorf("got location %q want /game", got)
+		}
 
+		cookies := response.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != poker.SessionCookieName || !cookies[0].HttpOnly {
+			t.Fatalf("got cookies %v want an http only session cookie", cookies)
+		}
+
+		request = newGetGameRequest()
+		request.AddCookie(cookies[0])
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
 		assertResponseStatusCode(t, response.Code, http.StatusOK)
-		assertResponseBody(t, response.Body.String(), "40")
+		if !strings.Contains(response.Body.String(), "Logged in as Andre") {
+			t.Error("the game page doesn't show who is logged in")
+		}
 	})
 
-	t.Run("404 player not found", func(t *testing.T) {
-		request := newGetScoreRequest("John")
-		response := httptest.NewRecorder()
+	t.Run("login only sends people back to this server", func(t *testing.T) {
+		server := newServer(t, &StubPlayerStore{}, &SpyGame{})
 
+		form := url.Values{"name": {"Andre"}, "password": {"s3cret"}, "next": {"//example.com"}}
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		request.Header.Set("content-type", "application/x-www-form-urlencoded")
+		response := httptest.NewRecorder()
 		server.ServeHTTP(response, request)
 
-		assertResponseStatusCode(t, response.Code, http.StatusNotFound)
+		if got := response.Header().Get("location"); got != "/game" {
+			t.Errorf("got location %q want /game", got)
+		}
 	})
-}
 
-func TestStoreWins(t *testing.T) {
-	store := StubPlayerStore{
-		map[string]int{},
-		[]string{},
-		nil,
-	}
-	server := mustMakePlayerServer(t, &store, &SpyGame{})
+	t.Run("players can't see the game page", func(t *testing.T) {
+		server := newServer(t, &StubPlayerStore{}, &SpyGame{})
 
-	t.Run("POST accepted", func(t *testing.T) {
-		playerName := "Andre"
-		request := newPostWinRequest(playerName)
 		response := httptest.NewRecorder()
+		server.ServeHTTP(response, withToken(newGetGameRequest(), "chris-token"))
 
-		server.ServeHTTP(response, request)
+		assertResponseStatusCode(t, response.Code, http.StatusForbidden)
+	})
 
-		assertResponseStatusCode(t, response.Code, http.StatusAccepted)
-		assertPlayerWin(t, &store, playerName)
+	t.Run("only organisers start games over the websocket", func(t *testing.T) {
+		game := &SpyGame{BlindAlert: []byte("Blind is 100")}
+		server := httptest.NewServer(newServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+		for token, want := range map[string]int{"": http.StatusUnauthorized, "chris-token": http.StatusForbidden} {
+			header := http.Header{}
+			if token != "" {
+				header.Set("authorization", "Bearer "+token)
+			}
+			_, response, err := websocket.DefaultDialer.Dial(wsURL, header)
+			if err == nil || response == nil || response.StatusCode != want {
+				t.Errorf("dial with token %q got %v want %d", token, err, want)
+			}
+		}
+
+		ws, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Authorization": {"Bearer andre-token"}})
+		if err != nil {
+			t.Fatalf("an organiser could not open the websocket, %v", err)
+		}
+		defer ws.Close()
+
+		startWSGame(t, ws, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+		assertStartCalledWith(t, game, 3)
+	})
+
+	t.Run("there's no login without users", func(t *testing.T) {
+		server := mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{}`)))
+
+		assertErrorResponse(t, response, http.StatusNotFound, "no users")
 	})
 }
 
-func TestLeague(t *testing.T) {
+func TestGameSpectators(t *testing.T) {
+	status := poker.GameStatus{
+		League:      "friday",
+		Level:       poker.BlindLevel{SmallBlind: 100, BigBlind: 200},
+		NextLevelIn: 5 * time.Minute,
+	}
+	wsURL := func(serverURL string) string {
+		return "ws" + strings.TrimPrefix(serverURL, "http") + "/ws"
+	}
 
-	t.Run("/league returns 200", func(t *testing.T) {
-		wantedLeague := []poker.Player{
-			{"Andre", 32},
-			{"Chris", 20},
-			{"John", 13},
+	t.Run("spectators catch up and get the result", func(t *testing.T) {
+		game := &SpyGame{GameStatus: status}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		host := mustDialWS(t, wsURL(server.URL))
+		defer host.Close()
+		id := startWSGame(t, host, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris"), League: "friday"})
+
+		tv := mustDialWS(t, wsURL(server.URL)+"?game="+id)
+		defer tv.Close()
+		phone := mustDialWS(t, wsURL(server.URL)+"?game="+id)
+		defer phone.Close()
+
+		want := poker.LiveGameStatus{ID: id, GameStatus: status}
+		want.PlayersRemaining = 3
+		for _, spectator := range []*websocket.Conn{tv, phone} {
+			var got poker.LiveGameStatus
+			assertWSMessage(t, spectator, poker.StatusMessage, &got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got status %+v want %+v", got, want)
+			}
 		}
 
-		store := StubPlayerStore{nil, nil, wantedLeague}
-		server := mustMakePlayerServer(t, &store, &SpyGame{})
+		sendWSMessage(t, host, 2, poker.EliminateMessage, poker.PlayerPayload{Player: "Chris"})
+		sendWSMessage(t, host, 3, poker.WinnerMessage, poker.PlayerPayload{Player: "Andre"})
+		for _, ws := range []*websocket.Conn{host, tv, phone} {
+			assertWSMessage(t, ws, poker.EliminatedMessage, nil)
+			assertWSMessage(t, ws, poker.ResultMessage, nil)
+		}
 
-		request := newGetLeagueRequest()
-		response := httptest.NewRecorder()
+		// the game is over so the spectators are hung up on
+		if _, _, err := tv.ReadMessage(); err == nil {
+			t.Error("the spectator is still connected after the game ended")
+		}
+	})
 
-		server.ServeHTTP(response, request)
+	t.Run("the running games are listed", func(t *testing.T) {
+		game := &SpyGame{GameStatus: status}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
 
-		got := getLeagueFromResponse(t, response.Body)
+		host := mustDialWS(t, wsURL(server.URL))
+		defer host.Close()
+		id := startWSGame(t, host, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris"), League: "friday"})
 
-		assertResponseStatusCode(t, response.Code, http.StatusOK)
-		assertLeague(t, got, wantedLeague)
-		assertContentType(t, response, poker.JsonContentType)
+		response, err := http.Get(server.URL + "/api/v1/games")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+
+		var got []poker.LiveGameStatus
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse games, %v", err)
+		}
+		if len(got) != 1 || got[0].ID != id || got[0].League != "friday" {
+			t.Errorf("got games %+v want game %s", got, id)
+		}
+	})
+
+	t.Run("starting a new game ends the one being watched", func(t *testing.T) {
+		game := &SpyGame{GameStatus: status}
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		defer server.Close()
+
+		first := mustDialWS(t, wsURL(server.URL))
+		defer first.Close()
+		id := startWSGame(t, first, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+
+		tv := mustDialWS(t, wsURL(server.URL)+"?game="+id)
+		defer tv.Close()
+		assertWSMessage(t, tv, poker.StatusMessage, nil)
+
+		second := mustDialWS(t, wsURL(server.URL))
+		defer second.Close()
+		startWSGame(t, second, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+
+		assertWSMessage(t, tv, poker.EndedMessage, nil)
+	})
+
+	t.Run("an unknown game can't be watched", func(t *testing.T) {
+		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{}))
+		defer server.Close()
+
+		_, response, err := websocket.DefaultDialer.Dial(wsURL(server.URL)+"?game=nope", nil)
+		if err == nil || response == nil || response.StatusCode != http.StatusNotFound {
+			t.Errorf("got %v want a 404", err)
+		}
+	})
+
+	t.Run("players can watch but not host", func(t *testing.T) {
+		users := []poker.User{
+			{Name: "Andre", Role: poker.RoleOrganiser, TokenHashes: []string{poker.HashToken("andre-token")}},
+			{Name: "Chris", Role: poker.RolePlayer, TokenHashes: []string{poker.HashToken("chris-token")}},
+		}
+		game := &SpyGame{GameStatus: status}
+		playerServer, err := poker.NewPlayerServer(&StubPlayerStore{}, game, poker.SeasonCalendar{Length: poker.Quarterly},
+			poker.DefaultRatingParams(), mustMakeAuthenticator(t, users, time.Hour))
+		if err != nil {
+			t.Fatal("problem creating player server", err)
+		}
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
 
+		host, _, err := websocket.DefaultDialer.Dial(wsURL(server.URL), http.Header{"Authorization": {"Bearer andre-token"}})
+		if err != nil {
+			t.Fatalf("the organiser could not host, %v", err)
+		}
+		defer host.Close()
+		id := startWSGame(t, host, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+
+		spectator, _, err := websocket.DefaultDialer.Dial(wsURL(server.URL)+"?game="+id, http.Header{"Authorization": {"Bearer chris-token"}})
+		if err != nil {
+			t.Fatalf("a player could not watch, %v", err)
+		}
+		defer spectator.Close()
+		assertWSMessage(t, spectator, poker.StatusMessage, nil)
 	})
 }
 
-func TestGame(t *testing.T) {
+func TestWebSocketConnections(t *testing.T) {
+	wsURL := func(serverURL string) string {
+		return "ws" + strings.TrimPrefix(serverURL, "http") + "/ws"
+	}
+	newServer := func(t *testing.T, game *SpyGame, timeouts poker.WebSocketTimeouts) *httptest.Server {
+		t.Helper()
+		playerServer := mustMakePlayerServer(t, &StubPlayerStore{}, game)
+		playerServer.WebSocketTimeouts = timeouts
+		return httptest.NewServer(playerServer)
+	}
 
-	t.Run("get /game returns 200", func(t *testing.T) {
+	t.Run("requests that aren't websockets are refused", func(t *testing.T) {
 		server := mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{})
-
-		request := newGetGameRequest()
 		response := httptest.NewRecorder()
 
-		server.ServeHTTP(response, request)
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/ws", nil))
 
-		assertResponseStatusCode(t, response.Code, http.StatusOK)
+		assertResponseStatusCode(t, response.Code, http.StatusBadRequest)
 	})
 
-	t.Run("start a game with 3 players and declare Andre the winner", func(t *testing.T) {
-		wantedBlindAlert := "Blind is 100"
-		winner := "Andre"
+	t.Run("the server pings to keep the connection alive", func(t *testing.T) {
+		server := newServer(t, &SpyGame{}, poker.WebSocketTimeouts{Ping: 5 * time.Millisecond, Idle: time.Second, Write: time.Second})
+		defer server.Close()
 
-		game := &SpyGame{BlindAlert: []byte(wantedBlindAlert)}
-		server := httptest.NewServer(mustMakePlayerServer(t, &StubPlayerStore{}, game))
+		ws := mustDialWS(t, wsURL(server.URL))
+		defer ws.Close()
+
+		pinged := make(chan struct{}, 1)
+		ws.SetPingHandler(func(string) error {
+			select {
+			case pinged <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		// control frames are only handled while reading
+		go ws.ReadMessage()
+
+		select {
+		case <-pinged:
+		case <-time.After(100 * time.Millisecond):
+			t.Error("got no ping from the server")
+		}
+	})
+
+	t.Run("quiet connections are closed", func(t *testing.T) {
+		server := newServer(t, &SpyGame{}, poker.WebSocketTimeouts{Ping: time.Hour, Idle: 10 * time.Millisecond, Write: time.Second})
 		defer server.Close()
 
-		// setup and test websocket connection
-		// need a persistent connection to a server to test, hence the test server
-		// calls /ws on the webserver to establish connection
-		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		ws := mustDialWS(t, wsURL(server.URL))
 		defer ws.Close()
 
-		writeWSMessage(t, ws, "3")
-		writeWSMessage(t, ws, winner)
+		ws.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err := ws.ReadMessage()
+		assertWSClosed(t, err, "idle timeout")
+	})
 
-		time.Sleep(10 * time.Millisecond)
-		assertStartCalledWith(t, game, 3)
-		assertFinishCalledWith(t, game, winner)
-		within(t, 10*time.Millisecond, func() { assertWebsocketGotMsg(t, ws, wantedBlindAlert) })
+	t.Run("the host can rejoin the game after losing the connection", func(t *testing.T) {
+		game := &SpyGame{}
+		server := newServer(t, game, poker.DefaultWebSocketTimeouts())
+		defer server.Close()
+
+		first := mustDialWS(t, wsURL(server.URL))
+		id := startWSGame(t, first, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+		first.Close()
+
+		tv := mustDialWS(t, wsURL(server.URL)+"?game="+id)
+		defer tv.Close()
+		assertWSMessage(t, tv, poker.StatusMessage, nil)
+
+		host := mustDialWS(t, wsURL(server.URL))
+		defer host.Close()
+		sendWSMessage(t, host, 1, poker.RejoinMessage, poker.RejoinPayload{Game: id, After: 0})
+		message := assertWSMessage(t, host, poker.StartedMessage, nil)
+		if message.Seq != 1 {
+			t.Errorf("got the started message numbered %d want 1", message.Seq)
+		}
+
+		sendWSMessage(t, host, 2, poker.WinnerMessage, poker.PlayerPayload{Player: "Andre"})
+		for _, ws := range []*websocket.Conn{host, tv} {
+			assertWSMessage(t, ws, poker.ResultMessage, nil)
+			_, _, err := ws.ReadMessage()
+			assertWSClosed(t, err, "")
+		}
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("a game that isn't being played can't be rejoined", func(t *testing.T) {
+		server := newServer(t, &SpyGame{}, poker.DefaultWebSocketTimeouts())
+		defer server.Close()
+
+		host := mustDialWS(t, wsURL(server.URL))
+		defer host.Close()
+
+		sendWSMessage(t, host, 1, poker.RejoinMessage, poker.RejoinPayload{Game: "nope"})
+		var got poker.ErrorPayload
+		assertWSMessage(t, host, poker.ErrorMessage, &got)
+		if got.Code != poker.GameNotFoundCode || got.ReplyTo != 1 {
+			t.Errorf("got error %+v want a reply to 1 with code %q so the page looks for the game", got, poker.GameNotFoundCode)
+		}
+	})
+
+	t.Run("spectators carry on from the last message they got", func(t *testing.T) {
+		game := &SpyGame{}
+		server := newServer(t, game, poker.DefaultWebSocketTimeouts())
+		defer server.Close()
+
+		host := mustDialWS(t, wsURL(server.URL))
+		defer host.Close()
+		id := startWSGame(t, host, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris", "Dave")})
+		sendWSMessage(t, host, 2, poker.EliminateMessage, poker.PlayerPayload{Player: "Chris"})
+		sendWSMessage(t, host, 3, poker.EliminateMessage, poker.PlayerPayload{Player: "Bob"})
+		assertWSMessage(t, host, poker.EliminatedMessage, nil)
+		assertWSMessage(t, host, poker.EliminatedMessage, nil)
+
+		tv := mustDialWS(t, wsURL(server.URL)+"?game="+id+"&after=2")
+		defer tv.Close()
+
+		var eliminated poker.EliminatedPayload
+		message := assertWSMessage(t, tv, poker.EliminatedMessage, &eliminated)
+		if message.Seq != 3 || eliminated.Player != "Bob" {
+			t.Errorf("got %+v numbered %d want Bob out in message 3", eliminated, message.Seq)
+		}
+	})
+
+	t.Run("spectators have to resume from a message number", func(t *testing.T) {
+		server := newServer(t, &SpyGame{}, poker.DefaultWebSocketTimeouts())
+		defer server.Close()
 
+		host := mustDialWS(t, wsURL(server.URL))
+		defer host.Close()
+		id := startWSGame(t, host, poker.StartPayload{Players: poker.Roster("Andre", "Bob", "Chris")})
+
+		_, response, err := websocket.DefaultDialer.Dial(wsURL(server.URL)+"?game="+id+"&after=last", nil)
+		if err == nil || response == nil || response.StatusCode != http.StatusBadRequest {
+			t.Errorf("got %v want a 400", err)
+		}
+	})
+}
+
+func TestRestoreGame(t *testing.T) {
+	wsURL := func(serverURL string) string {
+		return "ws" + strings.TrimPrefix(serverURL, "http") + "/ws"
+	}
+	listGames := func(t *testing.T, serverURL string) []poker.LiveGameStatus {
+		t.Helper()
+		response, err := http.Get(serverURL + "/api/v1/games")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+
+		var games []poker.LiveGameStatus
+		if err := json.NewDecoder(response.Body).Decode(&games); err != nil {
+			t.Fatalf("could not parse games, %v", err)
+		}
+		return games
+	}
+
+	t.Run("the game being played carries on and the host rejoins it", func(t *testing.T) {
+		game := &SpyGame{SavedGame: true, StartedWith: 3, GameStatus: poker.GameStatus{League: "friday"}}
+		playerServer := mustMakePlayerServer(t, &StubPlayerStore{}, game)
+
+		restored, err := playerServer.RestoreGame(context.Background())
+		if err != nil || !restored {
+			t.Fatalf("got restored %v and error %v, want the game restored", restored, err)
+		}
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		games := listGames(t, server.URL)
+		if len(games) != 1 || games[0].League != "friday" {
+			t.Fatalf("got games %+v want the restored game", games)
+		}
+
+		host := mustDialWS(t, wsURL(server.URL))
+		defer host.Close()
+		sendWSMessage(t, host, 1, poker.RejoinMessage, poker.RejoinPayload{Game: games[0].ID, After: -1})
+		var status poker.LiveGameStatus
+		assertWSMessage(t, host, poker.StatusMessage, &status)
+		if status.PlayersRemaining != 3 {
+			t.Errorf("got %d players remaining want 3", status.PlayersRemaining)
+		}
+
+		sendWSMessage(t, host, 2, poker.WinnerMessage, poker.PlayerPayload{Player: "Andre"})
+		assertWSMessage(t, host, poker.ResultMessage, nil)
+		assertFinishCalledWith(t, game, "Andre")
+	})
+
+	t.Run("nothing is listed when there was no game being played", func(t *testing.T) {
+		playerServer := mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{})
+
+		restored, err := playerServer.RestoreGame(context.Background())
+		if err != nil || restored {
+			t.Errorf("got restored %v and error %v, want nothing restored", restored, err)
+		}
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		if games := listGames(t, server.URL); len(games) != 0 {
+			t.Errorf("got games %+v want none", games)
+		}
+	})
+
+	t.Run("a game that can't be restored is an error", func(t *testing.T) {
+		playerServer := mustMakePlayerServer(t, &StubPlayerStore{}, &SpyGame{RestoreError: poker.ErrInvalidActiveGame})
+
+		if _, err := playerServer.RestoreGame(context.Background()); !errors.Is(err, poker.ErrInvalidActiveGame) {
+			t.Errorf("got error %v want %v", err, poker.ErrInvalidActiveGame)
+		}
 	})
 }
 
@@ -183,9 +1666,17 @@ func getLeagueFromResponse(t testing.TB, body io.Reader) (got []poker.Player) {
 	return league
 }
 
-func mustMakePlayerServer(t *testing.T, store poker.PlayerStore, game *SpyGame) *poker.PlayerServer {
+func getResultsFromResponse(t testing.TB, body io.Reader) (results []poker.GameResult) {
+	t.Helper()
+	if err := json.NewDecoder(body).Decode(&results); err != nil {
+		t.Fatalf("could not parse results from response, %v", err)
+	}
+	return results
+}
+
+func mustMakePlayerServer(t *testing.T, store poker.PlayerStore, game poker.Game) *poker.PlayerServer {
 	t.Helper()
-	server, err := poker.NewPlayerServer(store, game)
+	server, err := poker.NewPlayerServer(store, game, poker.SeasonCalendar{Length: poker.Quarterly}, poker.DefaultRatingParams(), nil)
 	if err != nil {
 		t.Fatal("problem creating player server", err)
 	}
@@ -216,6 +1707,13 @@ func assertLeague(t testing.TB, got, want []poker.Player) {
 	}
 }
 
+func assertResults(t testing.TB, got, want []poker.GameResult) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got results %+v, wanted %+v", got, want)
+	}
+}
+
 func assertResponseStatusCode(t testing.TB, got, want int) {
 	t.Helper()
 	if got != want {
@@ -235,6 +1733,19 @@ func assertPlayerWin(t testing.TB, store *StubPlayerStore, winner string) {
 	}
 }
 
+func assertErrorResponse(t testing.TB, response *httptest.ResponseRecorder, wantStatus int, wantError string) {
+	t.Helper()
+	assertContentType(t, response, poker.JsonContentType)
+
+	var got poker.ErrorResponse
+	if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+		t.Fatalf("could not parse error from response, %v", err)
+	}
+	if got.Status != wantStatus || !strings.Contains(got.Error, wantError) {
+		t.Errorf("got error %+v, want status %d with %q", got, wantStatus, wantError)
+	}
+}
+
 func assertResponseBody(t testing.TB, got, want string) {
 	t.Helper()
 	if got != want {
@@ -249,27 +1760,74 @@ func assertContentType(t testing.TB, response *httptest.ResponseRecorder, want s
 	}
 }
 
-func assertWebsocketGotMsg(t *testing.T, ws *websocket.Conn, want string) {
-	_, msg, _ := ws.ReadMessage()
+func sendWSMessage(t testing.TB, ws *websocket.Conn, seq int64, messageType poker.MessageType, payload any) {
+	t.Helper()
+	message := poker.NewMessage(messageType, payload)
+	message.Seq = seq
+	if err := ws.WriteJSON(message); err != nil {
+		t.Fatalf("could not send message over ws, %v", err)
+	}
+}
+
+// startWSGame starts the game as its host and reads up to the started message, with the id of the game
+func startWSGame(t testing.TB, ws *websocket.Conn, start poker.StartPayload) string {
+	t.Helper()
+	sendWSMessage(t, ws, 1, poker.StartMessage, start)
 
-	if string(msg) != want {
-		t.Errorf("got %q, want %q", string(msg), want)
+	var started poker.LiveGameStatus
+	for {
+		message := readWSMessage(t, ws)
+		if message.Type == poker.StartedMessage {
+			if err := message.DecodePayload(&started); err != nil {
+				t.Fatal(err)
+			}
+			return started.ID
+		}
 	}
 }
 
-func within(t testing.TB, d time.Duration, assert func()) {
+func readWSMessage(t testing.TB, ws *websocket.Conn) poker.Message {
 	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	var message poker.Message
+	if err := ws.ReadJSON(&message); err != nil {
+		t.Fatalf("could not read message from ws, %v", err)
+	}
+	return message
+}
 
-	done := make(chan struct{}, 1)
+// assertWSMessage reads the next message and its payload into payload, when it isn't nil
+func assertWSMessage(t testing.TB, ws *websocket.Conn, want poker.MessageType, payload any) poker.Message {
+	t.Helper()
+	message := readWSMessage(t, ws)
+	if message.Type != want {
+		t.Fatalf("got message %s %s want %s", message.Type, message.Payload, want)
+	}
+	if payload != nil {
+		if err := message.DecodePayload(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return message
+}
 
-	go func() {
-		assert()
-		done <- struct{}{}
-	}()
+func assertWSError(t testing.TB, ws *websocket.Conn, replyTo int64, wantError string) {
+	t.Helper()
+	var got poker.ErrorPayload
+	assertWSMessage(t, ws, poker.ErrorMessage, &got)
+	if got.ReplyTo != replyTo || !strings.Contains(got.Error, wantError) {
+		t.Errorf("got error %+v want a reply to %d with %q", got, replyTo, wantError)
+	}
+}
 
-	select {
-	case <-time.After(d):
-		t.Error("timed out waiting for message on ws")
-	case <-done:
+// assertWSClosed checks the server hung up with a close frame rather than dropping the connection
+func assertWSClosed(t testing.TB, err error, wantReason string) {
+	t.Helper()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseNormalClosure {
+		t.Fatalf("got %v want the connection closed normally", err)
+	}
+	if closeErr.Text != wantReason {
+		t.Errorf("got closed with %q want %q", closeErr.Text, wantReason)
 	}
 }
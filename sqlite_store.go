@@ -0,0 +1,460 @@
+package poker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	// pure go driver, registers as "sqlite" and builds without cgo
+	_ "modernc.org/sqlite"
+)
+
+// each migration runs once, in order, the applied version is kept in the db user_version
+var sqliteMigrations = []string{
+	`CREATE TABLE results (
+		id              TEXT PRIMARY KEY,
+		started         TEXT NOT NULL,
+		finished        TEXT NOT NULL,
+		num_players     INTEGER NOT NULL,
+		blind_structure TEXT NOT NULL,
+		buy_in          INTEGER NOT NULL
+	);
+	CREATE TABLE finishers (
+		result_id   TEXT NOT NULL REFERENCES results(id) ON DELETE CASCADE,
+		player_name TEXT NOT NULL,
+		position    INTEGER NOT NULL,
+		payout      INTEGER NOT NULL,
+		PRIMARY KEY (result_id, player_name)
+	);
+	CREATE INDEX finishers_player_name ON finishers(player_name);`,
+
+	// results recorded before there were leagues go to the default league
+	`CREATE TABLE leagues (
+		id       TEXT PRIMARY KEY,
+		name     TEXT NOT NULL,
+		created  TEXT NOT NULL,
+		archived INTEGER NOT NULL DEFAULT 0
+	);
+	INSERT INTO leagues (id, name, created) VALUES ('default', 'Default', '0001-01-01T00:00:00Z');
+	ALTER TABLE results ADD COLUMN league_id TEXT NOT NULL DEFAULT 'default';
+	CREATE INDEX results_league_id ON results(league_id);`,
+
+	// leagues rank by their own scoring rule, the existing ones by wins like before
+	`ALTER TABLE leagues ADD COLUMN scoring TEXT NOT NULL DEFAULT 'wins';
+	ALTER TABLE finishers ADD COLUMN knockouts INTEGER NOT NULL DEFAULT 0;`,
+
+	// the game being played, a single row kept as json since it is only ever read whole
+	`CREATE TABLE active_game (
+		id   INTEGER PRIMARY KEY CHECK (id = 1),
+		game TEXT NOT NULL
+	);`,
+
+	// when players were knocked out, null for results recorded before and for winners
+	`ALTER TABLE finishers ADD COLUMN eliminated TEXT;`,
+
+	// entries bought after the buy-in
+	`ALTER TABLE results ADD COLUMN add_on_cost INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE finishers ADD COLUMN rebuys INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE finishers ADD COLUMN add_on INTEGER NOT NULL DEFAULT 0;`,
+}
+
+type SQLitePlayerStore struct {
+	db *sql.DB
+}
+
+// NewSQLitePlayerStore brings the schema of db up to date before returning the store
+func NewSQLitePlayerStore(db *sql.DB) (*SQLitePlayerStore, error) {
+	if err := migrateSQLite(db); err != nil {
+		return nil, fmt.Errorf("could not migrate sqlite player store, %v", err)
+	}
+	return &SQLitePlayerStore{db}, nil
+}
+
+func SQLitePlayerStoreFromFile(path string) (*SQLitePlayerStore, func(), error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
+	}
+	// sqlite allows a single writer, sharing one connection avoids busy errors between our own writes
+	db.SetMaxOpenConns(1)
+
+	closeFunc := func() {
+		db.Close()
+	}
+
+	store, err := NewSQLitePlayerStore(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("problem creating sqlite player store, %v", err)
+	}
+
+	return store, closeFunc, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("could not read schema version, %v", err)
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed, %v", version+1, err)
+		}
+		// PRAGMA doesn't accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("could not set schema version %d, %v", version+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("could not commit migration %d, %v", version+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLitePlayerStore) GetPlayerScore(ctx context.Context, leagueID, playerName string) (int, error) {
+	if _, err := s.getLeagueInfo(ctx, s.db, leagueID); err != nil {
+		return 0, err
+	}
+
+	var wins int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM finishers f
+		JOIN results r ON r.id = f.result_id
+		WHERE r.league_id = ? AND f.player_name = ? AND f.position = 1`, leagueID, playerName,
+	).Scan(&wins)
+	if err != nil {
+		return 0, fmt.Errorf("could not get score of %q, %v", playerName, err)
+	}
+	return wins, nil
+}
+
+func (s *SQLitePlayerStore) RecordWin(ctx context.Context, leagueID, playerName string) error {
+	return s.RecordResult(ctx, winResult(leagueID, playerName))
+}
+
+func (s *SQLitePlayerStore) GetLeague(ctx context.Context, leagueID string) (League, error) {
+	return s.GetSeasonLeague(ctx, leagueID, AllTime())
+}
+
+// GetSeasonLeague scores the results of the league in go, scoring rules don't translate to sql
+func (s *SQLitePlayerStore) GetSeasonLeague(ctx context.Context, leagueID string, season Season) (League, error) {
+	league, err := s.getLeagueInfo(ctx, s.db, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.querySeasonResults(ctx, leagueID, season)
+	if err != nil {
+		return nil, fmt.Errorf("could not get league, %v", err)
+	}
+	return NewLeagueFromResults(results, league.ScoringRule()), nil
+}
+
+func (s *SQLitePlayerStore) GetLeagueResults(ctx context.Context, leagueID string, season Season) ([]GameResult, error) {
+	if _, err := s.getLeagueInfo(ctx, s.db, leagueID); err != nil {
+		return nil, err
+	}
+
+	results, err := s.querySeasonResults(ctx, leagueID, season)
+	if err != nil {
+		return nil, fmt.Errorf("could not get results of league %s, %v", leagueID, err)
+	}
+	return results, nil
+}
+
+// RecordResult saves the result and its finishers in one transaction
+func (s *SQLitePlayerStore) RecordResult(ctx context.Context, result GameResult) error {
+	result, err := newGameResult(result)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start transaction, %v", err)
+	}
+	defer tx.Rollback()
+
+	league, err := s.getLeagueInfo(ctx, tx, result.League)
+	if err != nil {
+		return err
+	}
+	if league.Archived {
+		return fmt.Errorf("%w: %s", ErrLeagueArchived, league.ID)
+	}
+
+	var recorded int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM results WHERE id = ?", result.ID).Scan(&recorded); err != nil {
+		return fmt.Errorf("could not check result %s, %v", result.ID, err)
+	}
+	if recorded > 0 {
+		return fmt.Errorf("%w, result %s was already recorded", ErrInvalidGameResult, result.ID)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO results (id, league_id, started, finished, num_players, blind_structure, buy_in, add_on_cost) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		result.ID, result.League, formatSQLiteTime(result.Started), formatSQLiteTime(result.Finished), result.NumPlayers, result.BlindStructure, result.BuyIn, result.AddOnCost,
+	)
+	if err != nil {
+		return fmt.Errorf("could not save result %s, %v", result.ID, err)
+	}
+
+	for _, finisher := range result.Finishers {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO finishers (result_id, player_name, position, payout, knockouts, eliminated, rebuys, add_on) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			result.ID, finisher.Name, finisher.Position, finisher.Payout, finisher.Knockouts, formatSQLiteNullTime(finisher.Eliminated), finisher.Rebuys, finisher.AddOn,
+		)
+		if err != nil {
+			return fmt.Errorf("could not save finisher %q of result %s, %v", finisher.Name, result.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLitePlayerStore) GetResults(ctx context.Context) ([]GameResult, error) {
+	results, err := s.queryResults(ctx, "1")
+	if err != nil {
+		return nil, fmt.Errorf("could not get results, %v", err)
+	}
+	return results, nil
+}
+
+func (s *SQLitePlayerStore) GetPlayerResults(ctx context.Context, playerName string) ([]GameResult, error) {
+	results, err := s.queryResults(ctx, "r.id IN (SELECT result_id FROM finishers WHERE player_name = ?)", playerName)
+	if err != nil {
+		return nil, fmt.Errorf("could not get results of %q, %v", playerName, err)
+	}
+	return results, nil
+}
+
+func (s *SQLitePlayerStore) CreateLeague(ctx context.Context, league LeagueInfo) (LeagueInfo, error) {
+	league, err := newLeagueInfo(league)
+	if err != nil {
+		return LeagueInfo{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return LeagueInfo{}, fmt.Errorf("could not start transaction, %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := s.getLeagueInfo(ctx, tx, league.ID); err == nil {
+		return LeagueInfo{}, fmt.Errorf("%w: %s", ErrLeagueExists, league.ID)
+	} else if !errors.Is(err, ErrLeagueNotFound) {
+		return LeagueInfo{}, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO leagues (id, name, created, scoring) VALUES (?, ?, ?, ?)",
+		league.ID, league.Name, formatSQLiteTime(league.Created), league.Scoring,
+	)
+	if err != nil {
+		return LeagueInfo{}, fmt.Errorf("could not save league %s, %v", league.ID, err)
+	}
+
+	return league, tx.Commit()
+}
+
+func (s *SQLitePlayerStore) GetLeagues(ctx context.Context) ([]LeagueInfo, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, created, archived, scoring FROM leagues ORDER BY rowid")
+	if err != nil {
+		return nil, fmt.Errorf("could not get leagues, %v", err)
+	}
+	defer rows.Close()
+
+	var leagues []LeagueInfo
+	for rows.Next() {
+		league, err := scanLeagueInfo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not read leagues, %v", err)
+		}
+		leagues = append(leagues, league)
+	}
+	return leagues, rows.Err()
+}
+
+func (s *SQLitePlayerStore) ArchiveLeague(ctx context.Context, leagueID string) error {
+	if leagueID == DefaultLeagueID {
+		return fmt.Errorf("%w, the default league can't be archived", ErrInvalidLeague)
+	}
+
+	res, err := s.db.ExecContext(ctx, "UPDATE leagues SET archived = 1 WHERE id = ?", leagueID)
+	if err != nil {
+		return fmt.Errorf("could not archive league %s, %v", leagueID, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s", ErrLeagueNotFound, leagueID)
+	}
+	return nil
+}
+
+func (s *SQLitePlayerStore) SaveActiveGame(ctx context.Context, game *ActiveGame) error {
+	if game == nil {
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM active_game"); err != nil {
+			return fmt.Errorf("could not clear the game being played, %v", err)
+		}
+		return nil
+	}
+
+	content, err := json.Marshal(game)
+	if err != nil {
+		return fmt.Errorf("could not encode the game being played, %v", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO active_game (id, game) VALUES (1, ?) ON CONFLICT (id) DO UPDATE SET game = excluded.game", string(content))
+	if err != nil {
+		return fmt.Errorf("could not save the game being played, %v", err)
+	}
+	return nil
+}
+
+func (s *SQLitePlayerStore) GetActiveGame(ctx context.Context) (*ActiveGame, error) {
+	var content string
+	err := s.db.QueryRowContext(ctx, "SELECT game FROM active_game WHERE id = 1").Scan(&content)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get the game being played, %v", err)
+	}
+
+	var game ActiveGame
+	if err := json.Unmarshal([]byte(content), &game); err != nil {
+		return nil, fmt.Errorf("could not parse the game being played, %v", err)
+	}
+	return &game, nil
+}
+
+// querier is what reading a league needs from either the db or a transaction
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *SQLitePlayerStore) getLeagueInfo(ctx context.Context, q querier, leagueID string) (LeagueInfo, error) {
+	row := q.QueryRowContext(ctx, "SELECT id, name, created, archived, scoring FROM leagues WHERE id = ?", leagueID)
+	league, err := scanLeagueInfo(row)
+	if err == sql.ErrNoRows {
+		return LeagueInfo{}, fmt.Errorf("%w: %s", ErrLeagueNotFound, leagueID)
+	}
+	if err != nil {
+		return LeagueInfo{}, fmt.Errorf("could not get league %s, %v", leagueID, err)
+	}
+	return league, nil
+}
+
+func scanLeagueInfo(row interface{ Scan(...any) error }) (LeagueInfo, error) {
+	var league LeagueInfo
+	var created string
+	if err := row.Scan(&league.ID, &league.Name, &created, &league.Archived, &league.Scoring); err != nil {
+		return LeagueInfo{}, err
+	}
+
+	var err error
+	league.Created, err = parseSQLiteTime(created)
+	return league, err
+}
+
+// results in the order they were recorded, only those matching the where condition on results r
+func (s *SQLitePlayerStore) queryResults(ctx context.Context, where string, args ...any) ([]GameResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.league_id, r.started, r.finished, r.num_players, r.blind_structure, r.buy_in, r.add_on_cost,
+			f.player_name, f.position, f.payout, f.knockouts, f.eliminated, f.rebuys, f.add_on
+		FROM results r
+		JOIN finishers f ON f.result_id = r.id
+		WHERE `+where+`
+		ORDER BY r.rowid, f.position`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []GameResult
+	for rows.Next() {
+		var result GameResult
+		var started, finished string
+		var finisher Finisher
+		var eliminated sql.NullString
+		err := rows.Scan(
+			&result.ID, &result.League, &started, &finished, &result.NumPlayers, &result.BlindStructure, &result.BuyIn, &result.AddOnCost,
+			&finisher.Name, &finisher.Position, &finisher.Payout, &finisher.Knockouts, &eliminated, &finisher.Rebuys, &finisher.AddOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if eliminated.Valid {
+			at, err := parseSQLiteTime(eliminated.String)
+			if err != nil {
+				return nil, err
+			}
+			finisher.Eliminated = &at
+		}
+
+		// rows of the same result come one after the other
+		if len(results) > 0 && results[len(results)-1].ID == result.ID {
+			last := &results[len(results)-1]
+			last.Finishers = append(last.Finishers, finisher)
+			continue
+		}
+
+		if result.Started, err = parseSQLiteTime(started); err != nil {
+			return nil, err
+		}
+		if result.Finished, err = parseSQLiteTime(finished); err != nil {
+			return nil, err
+		}
+		result.Finishers = []Finisher{finisher}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// querySeasonResults has the results of the league that finished in the season. The bounds are compared as text
+// without the zone, so that a time like 00:00:00.5Z that sorts before 00:00:00Z still counts from the start.
+func (s *SQLitePlayerStore) querySeasonResults(ctx context.Context, leagueID string, season Season) ([]GameResult, error) {
+	where, args := "r.league_id = ?", []any{leagueID}
+	if !season.Start.IsZero() {
+		where += " AND r.finished >= ?"
+		args = append(args, season.Start.UTC().Format("2006-01-02T15:04:05"))
+	}
+	if !season.End.IsZero() {
+		where += " AND r.finished < ?"
+		args = append(args, season.End.UTC().Format("2006-01-02T15:04:05"))
+	}
+	return s.queryResults(ctx, where, args...)
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatSQLiteNullTime is null for times that aren't known
+func formatSQLiteNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatSQLiteTime(*t), Valid: true}
+}
+
+func parseSQLiteTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse time %q, %v", s, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,81 @@
+package poker_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/andremfp/poker-app"
+	"github.com/andremfp/poker-app/playerstoretest"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	playerstoretest.RunConformance(t, func(t *testing.T) (poker.PlayerStore, func() poker.PlayerStore) {
+		path := filepath.Join(t.TempDir(), "game.db")
+		open := func() poker.PlayerStore {
+			return createSQLiteStore(t, path)
+		}
+		return open(), open
+	})
+
+	t.Run("failed results are not partly recorded", func(t *testing.T) {
+		store := createSQLiteStore(t, filepath.Join(t.TempDir(), "game.db"))
+
+		result := poker.GameResult{ID: "game-1", Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}}
+		assertNoError(t, store.RecordResult(context.Background(), result))
+
+		// same id again fails on insert
+		if err := store.RecordResult(context.Background(), result); err == nil {
+			t.Fatal("expected an error but didn't get one")
+		}
+
+		assertPlayerScore(t, store, "Andre", 1)
+	})
+
+	t.Run("migrations only run once", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.db")
+		assertNoError(t, createSQLiteStore(t, path).RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+
+		// reopening would fail creating the tables again
+		reopened := createSQLiteStore(t, path)
+		assertPlayerScore(t, reopened, "Andre", 1)
+	})
+
+	t.Run("results from before leagues move to the default league", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.db")
+
+		// the schema before leagues, at version 1
+		db, err := sql.Open("sqlite", path)
+		assertNoError(t, err)
+		_, err = db.Exec(`
+			CREATE TABLE results (id TEXT PRIMARY KEY, started TEXT NOT NULL, finished TEXT NOT NULL,
+				num_players INTEGER NOT NULL, blind_structure TEXT NOT NULL, buy_in INTEGER NOT NULL);
+			CREATE TABLE finishers (result_id TEXT NOT NULL, player_name TEXT NOT NULL, position INTEGER NOT NULL,
+				payout INTEGER NOT NULL, PRIMARY KEY (result_id, player_name));
+			INSERT INTO results VALUES ('game-1', '2024-01-05T20:00:00Z', '2024-01-05T22:00:00Z', 2, 'standard', 0);
+			INSERT INTO finishers VALUES ('game-1', 'Andre', 1, 0), ('game-1', 'Chris', 2, 0);
+			PRAGMA user_version = 1;`)
+		assertNoError(t, err)
+		db.Close()
+
+		store := createSQLiteStore(t, path)
+
+		assertPlayerScore(t, store, "Andre", 1)
+		results, err := store.GetResults(context.Background())
+		assertNoError(t, err)
+		if len(results) != 1 || results[0].League != poker.DefaultLeagueID {
+			t.Errorf("got results %+v, want game-1 in the default league", results)
+		}
+	})
+}
+
+func createSQLiteStore(t testing.TB, path string) *poker.SQLitePlayerStore {
+	t.Helper()
+
+	store, closeStore, err := poker.SQLitePlayerStoreFromFile(path)
+	assertNoError(t, err)
+	t.Cleanup(closeStore)
+
+	return store
+}
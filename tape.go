@@ -1,15 +1,110 @@
 package poker
 
-import "os"
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
 
+// tape replaces the whole db file on every Write. The content goes to a temp file that is synced
+// and renamed over the db file, so a crash leaves either the old or the new content, never half of it.
+// The previous contents are kept as path.1 (newest) up to path.N.
 type tape struct {
-	file *os.File
+	path    string
+	backups int
 }
 
-// custom write for the fs db file, makes sure the file is clear to write
 func (t *tape) Write(p []byte) (n int, err error) {
-	// clear the file before writing the league to it
-	t.file.Truncate(0)
-	t.file.Seek(0, 0)
-	return t.file.Write(p)
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), filepath.Base(t.path)+".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("could not create temp file for %s, %v", t.path, err)
+	}
+	// no-op once the temp file has been renamed
+	defer os.Remove(tmp.Name())
+
+	if n, err = tmp.Write(p); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("could not write %s, %v", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("could not sync %s, %v", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("could not close %s, %v", tmp.Name(), err)
+	}
+
+	if err := t.rotateBackups(); err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), t.path); err != nil {
+		return 0, fmt.Errorf("could not replace %s, %v", t.path, err)
+	}
+
+	return n, syncDir(filepath.Dir(t.path))
+}
+
+// shifts every backup one place older and copies the current db file to path.1
+func (t *tape) rotateBackups() error {
+	if t.backups <= 0 {
+		return nil
+	}
+	if _, err := os.Stat(t.path); os.IsNotExist(err) {
+		return nil
+	}
+
+	for i := t.backups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(t.path, i), backupPath(t.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not rotate backup of %s, %v", t.path, err)
+		}
+	}
+
+	if err := copyFile(t.path, backupPath(t.path, 1)); err != nil {
+		return fmt.Errorf("could not back up %s, %v", t.path, err)
+	}
+	return nil
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// makes a rename in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("could not open %s, %v", dir, err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("could not sync %s, %v", dir, err)
+	}
+	return nil
 }
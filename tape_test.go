@@ -1,38 +1,63 @@
 package poker_test
 
 import (
-	"io"
+	"context"
 	"os"
+	"path/filepath"
 	"testing"
-)
-
-type SpyTape struct {
-	file *os.File
-}
 
-// custom write for the fs db file, makes sure the file is clear to write
-func (t *SpyTape) Write(p []byte) (n int, err error) {
-	// clear the file before writing the league to it
-	t.file.Truncate(0)
-	t.file.Seek(0, 0)
-	return t.file.Write(p)
-}
+	"github.com/andremfp/poker-app"
+)
 
 func TestTapeWrite(t *testing.T) {
-	file, clean := createTempFile(t, "12345")
-	defer clean()
-
-	tape := &SpyTape{file}
-
-	tape.Write([]byte("abc"))
-
-	file.Seek(0, 0)
-	newFileContents, _ := io.ReadAll(file)
-
-	got := string(newFileContents)
-	want := "abc"
-
-	if got != want {
-		t.Errorf("got %q, want %q", got, want)
-	}
+	t.Run("replaces the whole file", func(t *testing.T) {
+		database, clean := createTempFile(t, `[{"Name": "Andre", "Wins": 12345}]`)
+		defer clean()
+
+		store, err := poker.NewFsPlayerStore(database.Name(), poker.DefaultFsStoreBackups)
+		assertNoError(t, err)
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Chris"))
+
+		reopened, err := poker.NewFsPlayerStore(database.Name(), 0)
+		assertNoError(t, err)
+
+		assertPlayerScore(t, reopened, "Andre", 12345)
+		assertPlayerScore(t, reopened, "Chris", 1)
+	})
+
+	t.Run("leaves no temp files behind", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := poker.NewFsPlayerStore(filepath.Join(dir, "game.db.json"), 0)
+		assertNoError(t, err)
+
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Chris"))
+
+		entries, err := os.ReadDir(dir)
+		assertNoError(t, err)
+		if len(entries) != 1 {
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			t.Errorf("got files %v, want only the db file", names)
+		}
+	})
+
+	t.Run("failed writes are not kept", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "game.db.json")
+		store, err := poker.NewFsPlayerStore(path, 0)
+		assertNoError(t, err)
+		assertNoError(t, store.RecordWin(context.Background(), poker.DefaultLeagueID, "Andre"))
+
+		// the temp file can't be created once the directory is gone
+		assertNoError(t, os.RemoveAll(dir))
+
+		err = store.RecordResult(context.Background(), poker.GameResult{Finishers: []poker.Finisher{{Name: "Andre", Position: 1}}})
+		if err == nil {
+			t.Fatal("expected an error but didn't get one")
+		}
+		assertPlayerScore(t, store, "Andre", 1)
+	})
 }
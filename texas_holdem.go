@@ -1,32 +1,535 @@
 package poker
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log"
+	"sync"
 	"time"
 )
 
+var (
+	ErrGameNotStarted     = errors.New("game has not started")
+	ErrInvalidElimination = errors.New("invalid elimination")
+)
+
+// TexasHoldem runs one game at a time, starting a new game cancels the blind clock of the previous one. Stores that
+// are an ActiveGameStore keep the running game so it can be restored after a restart.
 type TexasHoldem struct {
 	blindAlerter BlindAlerter
 	store        PlayerStore
+	blinds       BlindStructures
+
+	lock  sync.Mutex
+	clock *BlindClock
+	// what is known about the running game, the finishers are the players eliminated so far
+	result GameResult
+	// the roster of the running game, empty for games restored from before there were rosters
+	players  []Entrant
+	adaptive bool
+	entries  EntryRules
 }
 
-func NewTexasHoldem(store PlayerStore, blindAlerter BlindAlerter) *TexasHoldem {
+func NewTexasHoldem(store PlayerStore, blindAlerter BlindAlerter, blinds BlindStructures) *TexasHoldem {
 	return &TexasHoldem{
 		blindAlerter: blindAlerter,
 		store:        store,
+		blinds:       blinds,
+	}
+}
+
+// Start a game with the roster of the settings, players without a stack start with DefaultStartingStack. The blind
+// clock announces when the entries of the settings open and close.
+func (g *TexasHoldem) Start(ctx context.Context, settings GameSettings, alertsDestination io.Writer) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	structure, err := g.blinds.Get(settings.BlindStructure)
+	if err != nil {
+		return err
+	}
+	structure, err = settings.Entries.withNotices(structure)
+	if err != nil {
+		return err
+	}
+	addOnCost := 0
+	if settings.Entries.AddOn {
+		addOnCost = settings.Entries.AddOnCost
+		if addOnCost == 0 {
+			addOnCost = settings.BuyIn
+		}
+	}
+
+	players := make([]Entrant, len(settings.Players))
+	for i, player := range settings.Players {
+		if player.Stack == 0 {
+			player.Stack = DefaultStartingStack
+		}
+		players[i] = player
+	}
+	numPlayers := len(players)
+
+	leagueID := settings.League
+	if leagueID == "" {
+		leagueID = DefaultLeagueID
+	}
+	// fail now rather than when the result can't be recorded at the end of the game
+	leagues, err := g.store.GetLeagues(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get leagues, %w", err)
+	}
+	if err := leagueSet(leagues).checkOpen(leagueID); err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock != nil {
+		g.clock.Cancel()
+	}
+	g.clock = StartBlindClock(g.blindAlerter, structure, numPlayers, alertsDestination)
+	g.result = GameResult{
+		// known from the start so a restored game can tell it was already recorded
+		ID:             newID(),
+		League:         leagueID,
+		Started:        time.Now().UTC(),
+		NumPlayers:     numPlayers,
+		BlindStructure: structure.Name,
+		BuyIn:          settings.BuyIn,
+		AddOnCost:      addOnCost,
+	}
+	g.players = players
+	g.adaptive = settings.AdaptiveLevels
+	g.entries = settings.Entries
+	g.save(ctx)
+
+	return nil
+}
+
+// Restore carries on with the game that was running when the store last saved it, restored is false when there was
+// none or the store doesn't keep them. The blind clock counts the time since as played.
+func (g *TexasHoldem) Restore(ctx context.Context, alertsDestination io.Writer) (restored bool, err error) {
+	store, ok := g.store.(ActiveGameStore)
+	if !ok {
+		return false, nil
+	}
+
+	game, err := store.GetActiveGame(ctx)
+	if err != nil {
+		return false, fmt.Errorf("could not get the game being played, %w", err)
+	}
+	if game == nil {
+		return false, nil
+	}
+	if err := game.Validate(); err != nil {
+		return false, fmt.Errorf("%w, %v", ErrInvalidActiveGame, err)
+	}
+
+	// the result was recorded but the game wasn't cleared, it is over
+	results, err := g.store.GetResults(ctx)
+	if err != nil {
+		return false, fmt.Errorf("could not get results, %w", err)
+	}
+	for _, result := range results {
+		if result.ID == game.Result.ID {
+			return false, store.SaveActiveGame(ctx, nil)
+		}
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock != nil {
+		g.clock.Cancel()
+	}
+	g.clock = RestoreBlindClock(g.blindAlerter, game.Clock, alertsDestination)
+	g.result = game.Result
+	g.players = game.Players
+	g.adaptive = game.AdaptiveLevels
+	g.entries = game.Entries
+
+	return true, nil
+}
+
+func (g *TexasHoldem) Pause() error {
+	return g.controlClock((*BlindClock).Pause)
+}
+
+func (g *TexasHoldem) Resume() error {
+	return g.controlClock((*BlindClock).Resume)
+}
+
+func (g *TexasHoldem) SkipLevel() error {
+	return g.controlClock((*BlindClock).SkipLevel)
+}
+
+func (g *TexasHoldem) controlClock(control func(*BlindClock) error) error {
+	clock, err := g.runningClock()
+	if err != nil {
+		return err
+	}
+	if err := control(clock); err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	// unless another game started or this one finished in the meantime
+	if g.clock == clock {
+		g.save(context.Background())
+	}
+	return nil
+}
+
+// Eliminate knocks the player out in the last position still open at the time they went out. When that leaves one
+// player of the roster the game is finished with them the winner, if the result can't be recorded the game carries on
+// and the error is returned so Finish can be retried.
+func (g *TexasHoldem) Eliminate(ctx context.Context, playerName string) (finisher Finisher, winner string, err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock == nil {
+		return Finisher{}, "", ErrGameNotStarted
+	}
+	if err := ValidatePlayerName(playerName); err != nil {
+		return Finisher{}, "", fmt.Errorf("%w, %v", ErrInvalidElimination, err)
+	}
+	if g.result.Finisher(playerName) != nil {
+		return Finisher{}, "", fmt.Errorf("%w, %s is already out", ErrInvalidElimination, playerName)
+	}
+	if err := g.checkPlaying(playerName); err != nil {
+		return Finisher{}, "", err
+	}
+	remaining := g.playersRemaining()
+	if remaining <= 1 {
+		return Finisher{}, "", fmt.Errorf("%w, %s is the last player left, declare them the winner", ErrInvalidElimination, playerName)
 	}
+
+	eliminated := time.Now().UTC()
+	finisher = g.finisher(playerName, remaining)
+	finisher.Eliminated = &eliminated
+	g.result.Finishers = append(g.result.Finishers, finisher)
+
+	if playing := g.playing(); len(g.players) > 0 && len(playing) == 1 {
+		if err := g.finish(ctx, playing[0]); err != nil {
+			g.save(ctx)
+			return finisher, "", err
+		}
+		return finisher, playing[0], nil
+	}
+
+	g.adaptLevels()
+	g.save(ctx)
+	return finisher, "", nil
+}
+
+// Finish records the result of the game and cancels the pending blind alerts.
+// The game keeps running when the result can't be recorded so Finish can be retried.
+func (g *TexasHoldem) Finish(ctx context.Context, winner string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.result.Finisher(winner) != nil {
+		return fmt.Errorf("%w, %s was eliminated and can't win", ErrInvalidElimination, winner)
+	}
+	if err := g.checkPlaying(winner); err != nil {
+		return err
+	}
+	return g.finish(ctx, winner)
 }
 
-func (g *TexasHoldem) Start(numPlayers int, alertsDestination io.Writer) {
-	blinds := []int{100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000}
-	blindTime := 0 * time.Second
+// finish records the result with winner first and ends the game. Callers hold the lock.
+func (g *TexasHoldem) finish(ctx context.Context, winner string) error {
+	result := g.result
+	result.Finished = time.Now().UTC()
+	result.Finishers = append([]Finisher{g.finisher(winner, 1)}, g.result.Finishers...)
 
-	for _, blind := range blinds {
-		g.blindAlerter.ScheduleAlertAt(blindTime, blind, alertsDestination)
-		blindTime += time.Duration(5+numPlayers) * time.Minute
+	if err := g.store.RecordResult(ctx, result); err != nil {
+		return fmt.Errorf("could not record the result of the game won by %q, %w", winner, err)
 	}
+
+	if g.clock != nil {
+		g.clock.Cancel()
+		g.clock = nil
+	}
+	g.result = GameResult{}
+	g.players = nil
+	g.adaptive = false
+	g.entries = EntryRules{}
+	g.save(ctx)
+
+	return nil
 }
 
-func (g *TexasHoldem) Finish(winner string) {
-	g.store.RecordWin(winner)
+// Rebuy buys the player back in while rebuys are open, up to the rebuys each player can make. A player knocked out is
+// back in the game and the players knocked out after them move up a position.
+func (g *TexasHoldem) Rebuy(ctx context.Context, playerName string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock == nil {
+		return ErrGameNotStarted
+	}
+	if err := g.checkOpen(g.entries.RebuyLevels, "rebuys"); err != nil {
+		return err
+	}
+	player, err := g.entrant(playerName)
+	if err != nil {
+		return err
+	}
+	if g.entries.MaxRebuys > 0 && player.Rebuys >= g.entries.MaxRebuys {
+		return fmt.Errorf("%w, %s already made the %d rebuys allowed", ErrInvalidEntry, playerName, g.entries.MaxRebuys)
+	}
+
+	player.Rebuys++
+	if out := g.result.Finisher(playerName); out != nil {
+		position := out.Position
+		finishers := g.result.Finishers[:0]
+		for _, finisher := range g.result.Finishers {
+			if finisher.Name == playerName {
+				continue
+			}
+			if finisher.Position < position {
+				finisher.Position++
+			}
+			finishers = append(finishers, finisher)
+		}
+		g.result.Finishers = finishers
+		g.adaptLevels()
+	}
+	g.save(ctx)
+	return nil
+}
+
+// AddOn buys the player the add-on during the first break, once and only while they are still in
+func (g *TexasHoldem) AddOn(ctx context.Context, playerName string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock == nil {
+		return ErrGameNotStarted
+	}
+	if !g.entries.AddOn {
+		return fmt.Errorf("%w, this game has no add-ons", ErrEntriesClosed)
+	}
+	if index := g.clock.Status().Index; index != firstBreak(g.clockLevels()) {
+		return fmt.Errorf("%w, add-ons are only open during the first break", ErrEntriesClosed)
+	}
+	player, err := g.entrant(playerName)
+	if err != nil {
+		return err
+	}
+	if g.result.Finisher(playerName) != nil {
+		return fmt.Errorf("%w, %s is out and can't add on", ErrInvalidEntry, playerName)
+	}
+	if player.AddOn {
+		return fmt.Errorf("%w, %s already added on", ErrInvalidEntry, playerName)
+	}
+
+	player.AddOn = true
+	g.save(ctx)
+	return nil
+}
+
+// Register adds the player to the roster while late registration is open, with DefaultStartingStack when they have
+// no stack
+func (g *TexasHoldem) Register(ctx context.Context, player Entrant) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock == nil {
+		return ErrGameNotStarted
+	}
+	if err := g.checkOpen(g.entries.LateRegistrationLevels, "late registration"); err != nil {
+		return err
+	}
+	if err := ValidatePlayerName(player.Name); err != nil {
+		return fmt.Errorf("%w, %v", ErrInvalidEntry, err)
+	}
+	if _, err := g.entrant(player.Name); err == nil {
+		return fmt.Errorf("%w, %s is already playing", ErrInvalidEntry, player.Name)
+	}
+	if player.Stack < 0 {
+		return fmt.Errorf("%w, %s can't start with %d chips", ErrInvalidEntry, player.Name, player.Stack)
+	}
+	if player.Stack == 0 {
+		player.Stack = DefaultStartingStack
+	}
+
+	g.players = append(g.players, Entrant{Name: player.Name, Stack: player.Stack})
+	g.result.NumPlayers++
+	g.adaptLevels()
+	g.save(ctx)
+	return nil
+}
+
+func (g *TexasHoldem) Status() (GameStatus, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock == nil {
+		return GameStatus{}, ErrGameNotStarted
+	}
+	clock := g.clock.Status()
+	return GameStatus{
+		League:           g.result.League,
+		BlindStructure:   g.result.BlindStructure,
+		Started:          g.result.Started,
+		Level:            clock.Level,
+		NextLevelIn:      clock.NextLevelIn,
+		Paused:           clock.Paused,
+		PlayersRemaining: g.playersRemaining(),
+		Playing:          g.playing(),
+		PrizePool:        g.prizePool(),
+	}, nil
+}
+
+// DealHand deals the next hand at the table of dealer with the blinds and antes of the level being played
+func (g *TexasHoldem) DealHand(dealer *Dealer) ([]HandEvent, error) {
+	clock, err := g.runningClock()
+	if err != nil {
+		return nil, err
+	}
+	return dealer.Deal(clock.Status().Level)
+}
+
+func (g *TexasHoldem) BlindStructures() []string {
+	return g.blinds.Names()
+}
+
+func (g *TexasHoldem) playersRemaining() int {
+	return g.result.NumPlayers - len(g.result.Finishers)
+}
+
+// the players of the roster that are still in, in roster order. Callers hold the lock.
+func (g *TexasHoldem) playing() []string {
+	var playing []string
+	for _, player := range g.players {
+		if g.result.Finisher(player.Name) == nil {
+			playing = append(playing, player.Name)
+		}
+	}
+	return playing
+}
+
+// checkPlaying fails for players not on the roster, any player goes for games without one. Callers hold the lock.
+func (g *TexasHoldem) checkPlaying(playerName string) error {
+	if len(g.players) == 0 {
+		return nil
+	}
+	for _, player := range g.players {
+		if player.Name == playerName {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w, %s is not playing in this game", ErrInvalidElimination, playerName)
+}
+
+// entrant is the player on the roster, games without one have no entries. Callers hold the lock.
+func (g *TexasHoldem) entrant(playerName string) (*Entrant, error) {
+	for i := range g.players {
+		if g.players[i].Name == playerName {
+			return &g.players[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w, %s is not playing in this game", ErrInvalidEntry, playerName)
+}
+
+// finisher in position with the entries the player bought. Callers hold the lock.
+func (g *TexasHoldem) finisher(playerName string, position int) Finisher {
+	finisher := Finisher{Name: playerName, Position: position}
+	if player, err := g.entrant(playerName); err == nil {
+		finisher.Rebuys, finisher.AddOn = player.Rebuys, player.AddOn
+	}
+	return finisher
+}
+
+// prizePool is what the roster paid so far, every player bought in once for games without one. Callers hold the lock.
+func (g *TexasHoldem) prizePool() int {
+	if len(g.players) == 0 {
+		return g.result.PrizePool()
+	}
+	pool := 0
+	for _, player := range g.players {
+		pool += g.result.PaidIn(Finisher{Rebuys: player.Rebuys, AddOn: player.AddOn})
+	}
+	return pool
+}
+
+// checkOpen fails once the level the entries are open until is over, the entries are called what for the error.
+// Callers hold the lock.
+func (g *TexasHoldem) checkOpen(level int, what string) error {
+	if level == 0 {
+		return fmt.Errorf("%w, this game has no %s", ErrEntriesClosed, what)
+	}
+	if !entriesOpen(g.clockLevels(), level, g.clock.Status().Index) {
+		return fmt.Errorf("%w, %s closed at the end of level %d", ErrEntriesClosed, what, level)
+	}
+	return nil
+}
+
+// the levels of the blind clock of the running game. Callers hold the lock.
+func (g *TexasHoldem) clockLevels() []BlindLevel {
+	scheduled := g.clock.State().Levels
+	levels := make([]BlindLevel, len(scheduled))
+	for i, s := range scheduled {
+		levels[i] = s.Level
+	}
+	return levels
+}
+
+// adaptLevels shortens the levels not played yet to the length they have with the players remaining, for games with
+// adaptive levels. Callers hold the lock.
+func (g *TexasHoldem) adaptLevels() {
+	if !g.adaptive {
+		return
+	}
+	structure, err := g.blinds.Get(g.result.BlindStructure)
+	if err != nil {
+		log.Printf("could not adapt the blind levels, %v\n", err)
+		return
+	}
+	remaining := g.playersRemaining()
+	err = g.clock.Retime(func(level BlindLevel) time.Duration {
+		return structure.LevelDuration(level, remaining)
+	})
+	if err != nil {
+		log.Printf("could not adapt the blind levels, %v\n", err)
+	}
+}
+
+func (g *TexasHoldem) runningClock() (*BlindClock, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.clock == nil {
+		return nil, ErrGameNotStarted
+	}
+	return g.clock, nil
+}
+
+// save keeps the running game in the store, or clears it when there's none, for stores that can. A game that can't be
+// saved carries on, it just won't survive a restart. Callers hold the lock.
+func (g *TexasHoldem) save(ctx context.Context) {
+	store, ok := g.store.(ActiveGameStore)
+	if !ok {
+		return
+	}
+
+	var game *ActiveGame
+	if g.clock != nil {
+		game = (&ActiveGame{
+			Result:         g.result,
+			Clock:          g.clock.State(),
+			Players:        g.players,
+			AdaptiveLevels: g.adaptive,
+			Entries:        g.entries,
+		}).copy()
+	}
+	if err := store.SaveActiveGame(ctx, game); err != nil {
+		log.Printf("could not save the game being played, %v\n", err)
+	}
 }
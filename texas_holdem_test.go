@@ -1,8 +1,11 @@
 package poker_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"testing"
 	"time"
 
@@ -17,14 +20,39 @@ type ScheduledAlert struct {
 
 type SpyBlindAlerter struct {
 	Alerts []ScheduledAlert
+	Timers []*SpyAlertTimer
+}
+
+type SpyAlertTimer struct {
+	Stopped bool
+}
+
+func (s *SpyAlertTimer) Stop() bool {
+	wasRunning := !s.Stopped
+	s.Stopped = true
+	return wasRunning
 }
 
 func (s ScheduledAlert) String() string {
 	return fmt.Sprintf("%d chips at %v", s.Amount, s.At)
 }
 
-func (s *SpyBlindAlerter) ScheduleAlertAt(at time.Duration, amount int, outputTo io.Writer) {
-	s.Alerts = append(s.Alerts, ScheduledAlert{at, amount})
+func (s *SpyBlindAlerter) ScheduleAlertAt(at time.Duration, level poker.BlindLevel, outputTo io.Writer) poker.AlertTimer {
+	s.Alerts = append(s.Alerts, ScheduledAlert{at, level.SmallBlind})
+	timer := &SpyAlertTimer{}
+	s.Timers = append(s.Timers, timer)
+	return timer
+}
+
+// number of alerts that were scheduled and not stopped
+func (s *SpyBlindAlerter) Pending() int {
+	pending := 0
+	for _, timer := range s.Timers {
+		if !timer.Stopped {
+			pending++
+		}
+	}
+	return pending
 }
 
 func TestGameStart(t *testing.T) {
@@ -32,8 +60,8 @@ func TestGameStart(t *testing.T) {
 		store := &StubPlayerStore{}
 		blindAlerter := &SpyBlindAlerter{}
 
-		game := poker.NewTexasHoldem(store, blindAlerter)
-		game.Start(5, io.Discard)
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+		game.Start(context.Background(), gameSettings(5, "", ""), io.Discard)
 
 		tests := []ScheduledAlert{
 			{At: 0 * time.Second, Amount: 100},
@@ -56,8 +84,8 @@ func TestGameStart(t *testing.T) {
 		store := &StubPlayerStore{}
 		blindAlerter := &SpyBlindAlerter{}
 
-		game := poker.NewTexasHoldem(store, blindAlerter)
-		game.Start(7, io.Discard)
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+		game.Start(context.Background(), gameSettings(7, poker.DefaultBlindStructure, ""), io.Discard)
 
 		// requirement is:
 		// the number of player determines the amount of time before the blind goes up
@@ -73,26 +101,720 @@ func TestGameStart(t *testing.T) {
 
 		assertSchedulingTests(t, tests, blindAlerter)
 	})
+
+	t.Run("schedules alerts for the chosen blind structure, breaks are not scaled by players", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		blindAlerter := &SpyBlindAlerter{}
+
+		blinds := poker.BlindStructures{}
+		assertNoError(t, blinds.Add(poker.BlindStructure{
+			Name: "short",
+			Levels: []poker.BlindLevel{
+				{SmallBlind: 25, BigBlind: 50, Duration: 10 * time.Minute},
+				{Duration: 5 * time.Minute, Break: true},
+				{SmallBlind: 50, BigBlind: 100, Ante: 10, Duration: 10 * time.Minute},
+			},
+			DurationPerPlayer: time.Minute,
+		}))
+
+		game := poker.NewTexasHoldem(store, blindAlerter, blinds)
+		assertNoError(t, game.Start(context.Background(), gameSettings(2, "short", ""), io.Discard))
+
+		tests := []ScheduledAlert{
+			{At: 0 * time.Second, Amount: 25},
+			{At: 12 * time.Minute, Amount: 0},
+			{At: 17 * time.Minute, Amount: 50},
+		}
+
+		assertSchedulingTests(t, tests, blindAlerter)
+	})
+
+	t.Run("unknown blind structure returns an error and schedules nothing", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		blindAlerter := &SpyBlindAlerter{}
+
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+		err := game.Start(context.Background(), gameSettings(5, "hyper", ""), io.Discard)
+
+		if err == nil {
+			t.Fatal("expected an error but didn't get one")
+		}
+		if len(blindAlerter.Alerts) != 0 {
+			t.Errorf("got %d alerts scheduled, wanted none", len(blindAlerter.Alerts))
+		}
+	})
+
+	t.Run("rosters that can't start a game are errors", func(t *testing.T) {
+		tests := map[string][]poker.Entrant{
+			"no players":       nil,
+			"one player":       poker.Roster("Andre"),
+			"a player twice":   poker.Roster("Andre", "Chris", "Andre"),
+			"a player unnamed": poker.Roster("Andre", ""),
+			"a negative stack": {{Name: "Andre"}, {Name: "Chris", Stack: -100}},
+		}
+		for name, players := range tests {
+			blindAlerter := &SpyBlindAlerter{}
+			game := poker.NewTexasHoldem(&StubPlayerStore{}, blindAlerter, poker.BlindPresets())
+
+			err := game.Start(context.Background(), poker.GameSettings{Players: players}, io.Discard)
+			if !errors.Is(err, poker.ErrInvalidRoster) {
+				t.Errorf("%s: got error %v, want %v", name, err, poker.ErrInvalidRoster)
+			}
+			if len(blindAlerter.Alerts) != 0 {
+				t.Errorf("%s: got %d alerts scheduled, wanted none", name, len(blindAlerter.Alerts))
+			}
+		}
+	})
+
+	t.Run("players start with their own stack or the default one", func(t *testing.T) {
+		store := poker.NewInMemoryPlayerStore()
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		settings := poker.GameSettings{Players: []poker.Entrant{{Name: "Andre", Stack: 5000}, {Name: "Chris"}}}
+		assertNoError(t, game.Start(context.Background(), settings, io.Discard))
+
+		saved, err := store.GetActiveGame(context.Background())
+		assertNoError(t, err)
+		want := []poker.Entrant{{Name: "Andre", Stack: 5000}, {Name: "Chris", Stack: poker.DefaultStartingStack}}
+		if !reflect.DeepEqual(saved.Players, want) || saved.Result.NumPlayers != 2 {
+			t.Errorf("got %d players %+v want %+v", saved.Result.NumPlayers, saved.Players, want)
+		}
+		if settings.Players[1].Stack != 0 {
+			t.Errorf("got the roster of the settings changed to %+v", settings.Players)
+		}
+	})
 }
 
 func TestGameFinish(t *testing.T) {
 	t.Run("Andre wins", func(t *testing.T) {
 		store := &StubPlayerStore{}
 		blindAlerter := &SpyBlindAlerter{}
-		game := poker.NewTexasHoldem(store, blindAlerter)
-		game.Finish("Andre")
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+		assertNoError(t, game.Finish(context.Background(), "Andre"))
 
-		assertPlayerWin(t, store, "Andre")
+		assertGameWonBy(t, store, "Andre")
 	})
 
 	t.Run("Chris wins", func(t *testing.T) {
 		store := &StubPlayerStore{}
 		blindAlerter := &SpyBlindAlerter{}
-		game := poker.NewTexasHoldem(store, blindAlerter)
-		game.Finish("Chris")
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+		assertNoError(t, game.Finish(context.Background(), "Chris"))
+
+		assertGameWonBy(t, store, "Chris")
+	})
+
+	t.Run("records the details of the game", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(6, "turbo", ""), io.Discard))
+		assertNoError(t, game.Finish(context.Background(), "Andre"))
+
+		assertGameWonBy(t, store, "Andre")
+		got := store.Results[0]
+		if got.NumPlayers != 6 {
+			t.Errorf("got %d players recorded, want 6", got.NumPlayers)
+		}
+		if got.BlindStructure != "turbo" {
+			t.Errorf("got blind structure %q recorded, want %q", got.BlindStructure, "turbo")
+		}
+		if got.Started.IsZero() || got.Finished.Before(got.Started) {
+			t.Errorf("got game from %v to %v, want a start time before the finish", got.Started, got.Finished)
+		}
+	})
+
+	t.Run("records the game in the chosen league", func(t *testing.T) {
+		store := &StubPlayerStore{Leagues: []poker.LeagueInfo{{ID: "friday", Name: "Friday"}}}
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(4, "", "friday"), io.Discard))
+		assertNoError(t, game.Finish(context.Background(), "Andre"))
+
+		assertGameWonBy(t, store, "Andre")
+		if got := store.Results[0].League; got != "friday" {
+			t.Errorf("got result recorded in league %q, want %q", got, "friday")
+		}
+	})
+
+	t.Run("finishing cancels the pending blind alerts", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
 
-		assertPlayerWin(t, store, "Chris")
+		assertNoError(t, game.Start(context.Background(), gameSettings(5, "", ""), io.Discard))
+		assertNoError(t, game.Finish(context.Background(), "Andre"))
+
+		if blindAlerter.Pending() != 0 {
+			t.Errorf("got %d alerts still pending after finish, want none", blindAlerter.Pending())
+		}
 	})
+
+	t.Run("the game keeps running when the result can't be recorded", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(5, "turbo", ""), io.Discard))
+		pending := blindAlerter.Pending()
+
+		store.Err = errors.New("disk full")
+		err := game.Finish(context.Background(), "Andre")
+		if !errors.Is(err, store.Err) {
+			t.Fatalf("got error %v, want %v", err, store.Err)
+		}
+		if blindAlerter.Pending() != pending {
+			t.Errorf("got %d alerts pending after a failed finish, want %d", blindAlerter.Pending(), pending)
+		}
+
+		store.Err = nil
+		assertNoError(t, game.Finish(context.Background(), "Andre"))
+
+		assertGameWonBy(t, store, "Andre")
+		if got := store.Results[0].BlindStructure; got != "turbo" {
+			t.Errorf("got blind structure %q recorded, want %q", got, "turbo")
+		}
+	})
+}
+
+func TestGameEliminate(t *testing.T) {
+	t.Run("players finish in the order they are knocked out", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+		assertNoError(t, game.Start(context.Background(), gameSettings(4, "", ""), io.Discard))
+
+		before := time.Now()
+		finisher, winner, err := game.Eliminate(context.Background(), "Chris")
+		assertNoError(t, err)
+		if finisher.Position != 4 || winner != "" {
+			t.Errorf("got Chris out in position %d with winner %q want 4 and no winner", finisher.Position, winner)
+		}
+		if finisher.Eliminated == nil || finisher.Eliminated.Before(before) {
+			t.Errorf("got Chris out at %v want the time of the elimination", finisher.Eliminated)
+		}
+		finisher, _, err = game.Eliminate(context.Background(), "Bob")
+		assertNoError(t, err)
+		if finisher.Position != 3 {
+			t.Errorf("got Bob out in position %d want 3", finisher.Position)
+		}
+
+		status, err := game.Status()
+		assertNoError(t, err)
+		if status.PlayersRemaining != 2 || !reflect.DeepEqual(status.Playing, []string{"Andre", "Dave"}) {
+			t.Errorf("got %d players remaining, %v, want Andre and Dave", status.PlayersRemaining, status.Playing)
+		}
+
+		assertNoError(t, game.Finish(context.Background(), "Andre"))
+		assertGameWonBy(t, store, "Andre")
+		for name, want := range map[string]int{"Andre": 1, "Bob": 3, "Chris": 4} {
+			if got := store.Results[0].Finisher(name); got == nil || got.Position != want {
+				t.Errorf("got %s recorded as %+v want position %d", name, got, want)
+			}
+		}
+		if got := store.Results[0].Finisher("Chris"); got.Eliminated == nil {
+			t.Error("got Chris recorded without the time they were knocked out")
+		}
+	})
+
+	t.Run("the game is over when one player is left", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+		assertNoError(t, game.Start(context.Background(), gameSettings(3, "", ""), io.Discard))
+
+		_, winner, err := game.Eliminate(context.Background(), "Andre")
+		assertNoError(t, err)
+		if winner != "" {
+			t.Errorf("got winner %q with 2 players left", winner)
+		}
+		finisher, winner, err := game.Eliminate(context.Background(), "Chris")
+		assertNoError(t, err)
+		if finisher.Position != 2 || winner != "Bob" {
+			t.Errorf("got Chris out in position %d with winner %q want 2 and Bob", finisher.Position, winner)
+		}
+
+		assertGameWonBy(t, store, "Bob")
+		if len(store.Results[0].Finishers) != 3 {
+			t.Errorf("got finishers %+v want all 3 players", store.Results[0].Finishers)
+		}
+		if blindAlerter.Pending() != 0 {
+			t.Errorf("got %d alerts still pending after the game was won, want none", blindAlerter.Pending())
+		}
+		if _, err := game.Status(); err != poker.ErrGameNotStarted {
+			t.Errorf("got error %v, want %v", err, poker.ErrGameNotStarted)
+		}
+	})
+
+	t.Run("the last elimination can be won again when the result can't be recorded", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+		assertNoError(t, game.Start(context.Background(), gameSettings(2, "", ""), io.Discard))
+
+		store.Err = errors.New("disk full")
+		_, winner, err := game.Eliminate(context.Background(), "Bob")
+		if !errors.Is(err, store.Err) || winner != "" {
+			t.Fatalf("got winner %q and error %v, want %v", winner, err, store.Err)
+		}
+
+		store.Err = nil
+		assertNoError(t, game.Finish(context.Background(), "Andre"))
+		assertGameWonBy(t, store, "Andre")
+	})
+
+	t.Run("eliminations that can't happen", func(t *testing.T) {
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		if _, _, err := game.Eliminate(context.Background(), "Chris"); err != poker.ErrGameNotStarted {
+			t.Errorf("got error %v, want %v", err, poker.ErrGameNotStarted)
+		}
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(3, "", ""), io.Discard))
+		_, _, err := game.Eliminate(context.Background(), "")
+		assertInvalidElimination(t, err, poker.ErrInvalidPlayerName.Error())
+		_, _, err = game.Eliminate(context.Background(), "Mary")
+		assertInvalidElimination(t, err, "not playing")
+
+		_, _, err = game.Eliminate(context.Background(), "Chris")
+		assertNoError(t, err)
+		_, _, err = game.Eliminate(context.Background(), "Chris")
+		assertInvalidElimination(t, err, "already out")
+
+		err = game.Finish(context.Background(), "Chris")
+		assertInvalidElimination(t, err, "can't win")
+		err = game.Finish(context.Background(), "Mary")
+		assertInvalidElimination(t, err, "not playing")
+	})
+
+	t.Run("levels get shorter as players are knocked out with adaptive levels", func(t *testing.T) {
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, blindAlerter, poker.BlindPresets())
+		settings := gameSettings(5, "", "")
+		settings.AdaptiveLevels = true
+		assertNoError(t, game.Start(context.Background(), settings, io.Discard))
+		scheduled := len(blindAlerter.Alerts)
+
+		_, _, err := game.Eliminate(context.Background(), "Eve")
+		assertNoError(t, err)
+
+		// the levels were 10 minutes with 5 players and are 9 with 4, the one being played included
+		if blindAlerter.Pending() != scheduled-1 {
+			t.Errorf("got %d alerts pending want the %d levels after the first", blindAlerter.Pending(), scheduled-1)
+		}
+		assertSchedulingAround(t, []ScheduledAlert{
+			{At: 9 * time.Minute, Amount: 200},
+			{At: 18 * time.Minute, Amount: 300},
+			{At: 27 * time.Minute, Amount: 400},
+			{At: 36 * time.Minute, Amount: 500},
+			{At: 45 * time.Minute, Amount: 600},
+			{At: 54 * time.Minute, Amount: 800},
+			{At: 63 * time.Minute, Amount: 1000},
+			{At: 72 * time.Minute, Amount: 2000},
+			{At: 81 * time.Minute, Amount: 4000},
+			{At: 90 * time.Minute, Amount: 8000},
+		}, &SpyBlindAlerter{Alerts: blindAlerter.Alerts[scheduled:]})
+	})
+
+	t.Run("levels keep their length without adaptive levels", func(t *testing.T) {
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, blindAlerter, poker.BlindPresets())
+		assertNoError(t, game.Start(context.Background(), gameSettings(5, "", ""), io.Discard))
+		scheduled := len(blindAlerter.Alerts)
+
+		_, _, err := game.Eliminate(context.Background(), "Eve")
+		assertNoError(t, err)
+
+		if len(blindAlerter.Alerts) != scheduled || blindAlerter.Pending() != scheduled {
+			t.Errorf("got %d alerts scheduled and %d pending, want the %d of the start", len(blindAlerter.Alerts), blindAlerter.Pending(), scheduled)
+		}
+	})
+}
+
+func TestGameEntries(t *testing.T) {
+	ctx := context.Background()
+	start := func(t *testing.T, store *StubPlayerStore, numPlayers, buyIn int, rules poker.EntryRules) *poker.TexasHoldem {
+		t.Helper()
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+		settings := gameSettings(numPlayers, "deepstack", "")
+		settings.BuyIn, settings.Entries = buyIn, rules
+		assertNoError(t, game.Start(ctx, settings, io.Discard))
+		return game
+	}
+	skipTo := func(t *testing.T, game *poker.TexasHoldem, levels int) {
+		t.Helper()
+		for i := 0; i < levels; i++ {
+			assertNoError(t, game.SkipLevel())
+		}
+	}
+
+	t.Run("rebuys put players back in until the rebuy levels are over", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		game := start(t, store, 4, 10, poker.EntryRules{RebuyLevels: 1})
+
+		for _, player := range []string{"Dave", "Chris"} {
+			_, _, err := game.Eliminate(ctx, player)
+			assertNoError(t, err)
+		}
+		assertNoError(t, game.Rebuy(ctx, "Dave"))
+		assertNoError(t, game.Rebuy(ctx, "Andre"))
+
+		status, err := game.Status()
+		assertNoError(t, err)
+		if !reflect.DeepEqual(status.Playing, []string{"Andre", "Bob", "Dave"}) || status.PrizePool != 60 {
+			t.Errorf("got %v playing and a prize pool of %d, want Dave back in and 60", status.Playing, status.PrizePool)
+		}
+
+		skipTo(t, game, 1)
+		status, _ = game.Status()
+		if status.Level.Notice != poker.RebuysClosedNotice+"." {
+			t.Errorf("got notice %q at level 2 want rebuys closed", status.Level.Notice)
+		}
+		if err := game.Rebuy(ctx, "Chris"); !errors.Is(err, poker.ErrEntriesClosed) {
+			t.Errorf("got error %v, want %v", err, poker.ErrEntriesClosed)
+		}
+
+		for _, player := range []string{"Dave", "Bob"} {
+			_, _, err := game.Eliminate(ctx, player)
+			assertNoError(t, err)
+		}
+		assertGameWonBy(t, store, "Andre")
+		result := store.Results[0]
+		for name, want := range map[string]poker.Finisher{
+			"Andre": {Position: 1, Rebuys: 1},
+			"Bob":   {Position: 2},
+			"Dave":  {Position: 3, Rebuys: 1},
+			"Chris": {Position: 4},
+		} {
+			if got := result.Finisher(name); got == nil || got.Position != want.Position || got.Rebuys != want.Rebuys {
+				t.Errorf("got %s recorded as %+v want position %d with %d rebuys", name, got, want.Position, want.Rebuys)
+			}
+		}
+		if result.PrizePool() != 60 {
+			t.Errorf("got a prize pool of %d recorded want 60", result.PrizePool())
+		}
+	})
+
+	t.Run("players can't rebuy more than the max", func(t *testing.T) {
+		game := start(t, &StubPlayerStore{}, 3, 10, poker.EntryRules{RebuyLevels: 2, MaxRebuys: 1})
+
+		assertNoError(t, game.Rebuy(ctx, "Andre"))
+		if err := game.Rebuy(ctx, "Andre"); !errors.Is(err, poker.ErrInvalidEntry) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidEntry)
+		}
+		if err := game.Rebuy(ctx, "Mary"); !errors.Is(err, poker.ErrInvalidEntry) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidEntry)
+		}
+	})
+
+	t.Run("players still in can add on once during the first break", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		game := start(t, store, 3, 10, poker.EntryRules{AddOn: true, AddOnCost: 5})
+
+		if err := game.AddOn(ctx, "Andre"); !errors.Is(err, poker.ErrEntriesClosed) {
+			t.Errorf("got error %v before the break, want %v", err, poker.ErrEntriesClosed)
+		}
+		_, _, err := game.Eliminate(ctx, "Chris")
+		assertNoError(t, err)
+
+		skipTo(t, game, 4)
+		status, _ := game.Status()
+		if !status.Level.Break || status.Level.Notice != poker.AddOnsOpenNotice+"." {
+			t.Errorf("got level %+v want the first break opening add-ons", status.Level)
+		}
+		assertNoError(t, game.AddOn(ctx, "Andre"))
+		for _, player := range []string{"Andre", "Chris"} {
+			if err := game.AddOn(ctx, player); !errors.Is(err, poker.ErrInvalidEntry) {
+				t.Errorf("got error %v for %s, want %v", err, player, poker.ErrInvalidEntry)
+			}
+		}
+
+		skipTo(t, game, 1)
+		if err := game.AddOn(ctx, "Bob"); !errors.Is(err, poker.ErrEntriesClosed) {
+			t.Errorf("got error %v after the break, want %v", err, poker.ErrEntriesClosed)
+		}
+
+		assertNoError(t, game.Finish(ctx, "Andre"))
+		result := store.Results[0]
+		if !result.Finisher("Andre").AddOn || result.AddOnCost != 5 || result.PaidIn(*result.Finisher("Andre")) != 15 {
+			t.Errorf("got result %+v want Andre's add-on of 5 recorded", result)
+		}
+	})
+
+	t.Run("players join until late registration is over", func(t *testing.T) {
+		game := start(t, &StubPlayerStore{}, 3, 10, poker.EntryRules{LateRegistrationLevels: 4, AddOn: true})
+
+		assertNoError(t, game.Register(ctx, poker.Entrant{Name: "Mary"}))
+		if err := game.Register(ctx, poker.Entrant{Name: "Andre"}); !errors.Is(err, poker.ErrInvalidEntry) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidEntry)
+		}
+		status, _ := game.Status()
+		if status.PlayersRemaining != 4 || !reflect.DeepEqual(status.Playing, []string{"Andre", "Bob", "Chris", "Mary"}) || status.PrizePool != 40 {
+			t.Errorf("got status %+v want Mary in and a prize pool of 40", status)
+		}
+
+		skipTo(t, game, 4)
+		status, _ = game.Status()
+		if want := poker.LateRegistrationClosedNotice + ". " + poker.AddOnsOpenNotice + "."; status.Level.Notice != want {
+			t.Errorf("got notice %q want %q", status.Level.Notice, want)
+		}
+		if err := game.Register(ctx, poker.Entrant{Name: "John"}); !errors.Is(err, poker.ErrEntriesClosed) {
+			t.Errorf("got error %v, want %v", err, poker.ErrEntriesClosed)
+		}
+	})
+
+	t.Run("entries the game doesn't take", func(t *testing.T) {
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, &SpyBlindAlerter{}, poker.BlindPresets())
+		if err := game.Rebuy(ctx, "Andre"); err != poker.ErrGameNotStarted {
+			t.Errorf("got error %v, want %v", err, poker.ErrGameNotStarted)
+		}
+
+		game = start(t, &StubPlayerStore{}, 3, 10, poker.EntryRules{})
+		for _, err := range []error{
+			game.Rebuy(ctx, "Andre"),
+			game.AddOn(ctx, "Andre"),
+			game.Register(ctx, poker.Entrant{Name: "Mary"}),
+		} {
+			if !errors.Is(err, poker.ErrEntriesClosed) {
+				t.Errorf("got error %v, want %v", err, poker.ErrEntriesClosed)
+			}
+		}
+	})
+
+	t.Run("entry rules that can't start a game", func(t *testing.T) {
+		tests := map[string]poker.GameSettings{
+			"add-ons without a break": {BlindStructure: "standard", Entries: poker.EntryRules{AddOn: true}},
+			"negative rebuy levels":   {Entries: poker.EntryRules{RebuyLevels: -1}},
+			"max rebuys alone":        {Entries: poker.EntryRules{MaxRebuys: 2}},
+			"add-on cost alone":       {Entries: poker.EntryRules{AddOnCost: 5}},
+			"negative buy-in":         {BuyIn: -10},
+		}
+		for name, settings := range tests {
+			t.Run(name, func(t *testing.T) {
+				game := poker.NewTexasHoldem(&StubPlayerStore{}, &SpyBlindAlerter{}, poker.BlindPresets())
+				settings.Players = poker.Roster("Andre", "Bob")
+				if err := game.Start(ctx, settings, io.Discard); !errors.Is(err, poker.ErrInvalidEntry) {
+					t.Errorf("got error %v, want %v", err, poker.ErrInvalidEntry)
+				}
+			})
+		}
+	})
+}
+
+func TestGameClockControls(t *testing.T) {
+	t.Run("games can only start in open leagues", func(t *testing.T) {
+		store := &StubPlayerStore{Leagues: []poker.LeagueInfo{{ID: "old", Name: "Old", Archived: true}}}
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+
+		err := game.Start(context.Background(), gameSettings(5, "", "nowhere"), io.Discard)
+		if !errors.Is(err, poker.ErrLeagueNotFound) {
+			t.Errorf("got error %v, want %v", err, poker.ErrLeagueNotFound)
+		}
+
+		err = game.Start(context.Background(), gameSettings(5, "", "old"), io.Discard)
+		if !errors.Is(err, poker.ErrLeagueArchived) {
+			t.Errorf("got error %v, want %v", err, poker.ErrLeagueArchived)
+		}
+
+		if len(blindAlerter.Alerts) != 0 {
+			t.Errorf("got %d alerts scheduled, wanted none", len(blindAlerter.Alerts))
+		}
+	})
+
+	t.Run("can't control the clock before the game starts", func(t *testing.T) {
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		for name, control := range map[string]func() error{"pause": game.Pause, "resume": game.Resume, "skip": game.SkipLevel} {
+			if err := control(); err != poker.ErrGameNotStarted {
+				t.Errorf("%s: got error %v, want %v", name, err, poker.ErrGameNotStarted)
+			}
+		}
+	})
+
+	t.Run("starting a new game cancels the previous clock", func(t *testing.T) {
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, blindAlerter, poker.BlindPresets())
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(5, "", ""), io.Discard))
+		firstGameAlerts := len(blindAlerter.Alerts)
+		assertNoError(t, game.Start(context.Background(), gameSettings(5, "", ""), io.Discard))
+
+		if blindAlerter.Pending() != firstGameAlerts {
+			t.Errorf("got %d alerts pending, want only the %d of the new game", blindAlerter.Pending(), firstGameAlerts)
+		}
+	})
+
+	t.Run("status of the running game", func(t *testing.T) {
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		if _, err := game.Status(); err != poker.ErrGameNotStarted {
+			t.Errorf("got error %v, want %v", err, poker.ErrGameNotStarted)
+		}
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(5, "turbo", ""), io.Discard))
+		assertNoError(t, game.Pause())
+
+		status, err := game.Status()
+		assertNoError(t, err)
+		if status.League != poker.DefaultLeagueID || status.BlindStructure != "turbo" || status.PlayersRemaining != 5 || !status.Paused {
+			t.Errorf("got status %+v", status)
+		}
+		if status.Level.SmallBlind == 0 || status.NextLevelIn <= 0 {
+			t.Errorf("got status %+v want the first level with time left", status)
+		}
+	})
+
+	t.Run("pause and resume go through to the clock", func(t *testing.T) {
+		blindAlerter := &SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(&StubPlayerStore{}, blindAlerter, poker.BlindPresets())
+
+		assertNoError(t, game.Start(context.Background(), gameSettings(5, "", ""), io.Discard))
+		assertNoError(t, game.Pause())
+		if blindAlerter.Pending() != 0 {
+			t.Errorf("got %d alerts pending while paused, want none", blindAlerter.Pending())
+		}
+		assertNoError(t, game.Resume())
+	})
+}
+
+func TestGameRestore(t *testing.T) {
+	ctx := context.Background()
+
+	activeGame := func(t *testing.T, store poker.ActiveGameStore) *poker.ActiveGame {
+		t.Helper()
+		game, err := store.GetActiveGame(ctx)
+		assertNoError(t, err)
+		return game
+	}
+
+	t.Run("the game being played is saved until it is finished", func(t *testing.T) {
+		store := poker.NewInMemoryPlayerStore()
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		assertNoError(t, game.Start(ctx, gameSettings(5, "turbo", ""), io.Discard))
+		saved := activeGame(t, store)
+		if saved == nil || saved.Result.ID == "" || saved.Result.NumPlayers != 5 || saved.Result.BlindStructure != "turbo" {
+			t.Fatalf("got saved game %+v want the game started", saved)
+		}
+
+		_, _, err := game.Eliminate(ctx, "Chris")
+		assertNoError(t, err)
+		assertNoError(t, game.Pause())
+		saved = activeGame(t, store)
+		if len(saved.Result.Finishers) != 1 || !saved.Clock.Paused {
+			t.Errorf("got saved game %+v want Chris eliminated and the clock paused", saved)
+		}
+
+		assertNoError(t, game.Finish(ctx, "Andre"))
+		if saved := activeGame(t, store); saved != nil {
+			t.Errorf("got saved game %+v after the finish, want none", saved)
+		}
+	})
+
+	t.Run("a restarted game carries on where it was", func(t *testing.T) {
+		store := poker.NewInMemoryPlayerStore()
+		before := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+		assertNoError(t, before.Start(ctx, gameSettings(5, "turbo", ""), io.Discard))
+		_, _, err := before.Eliminate(ctx, "Chris")
+		assertNoError(t, err)
+		assertNoError(t, before.Pause())
+
+		blindAlerter := &SpyBlindAlerter{}
+		after := poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets())
+		restored, err := after.Restore(ctx, io.Discard)
+		assertNoError(t, err)
+		if !restored {
+			t.Fatal("the game being played wasn't restored")
+		}
+
+		status, err := after.Status()
+		assertNoError(t, err)
+		if status.BlindStructure != "turbo" || status.PlayersRemaining != 4 || !status.Paused {
+			t.Errorf("got status %+v want the turbo game paused with 4 players", status)
+		}
+		if want := []string{"Andre", "Bob", "Dave", "Eve"}; !reflect.DeepEqual(status.Playing, want) {
+			t.Errorf("got %v playing want %v", status.Playing, want)
+		}
+
+		assertNoError(t, after.Resume())
+		if blindAlerter.Pending() == 0 {
+			t.Error("got no alerts pending after resuming the restored game")
+		}
+		assertNoError(t, after.Finish(ctx, "Andre"))
+		results, err := store.GetResults(ctx)
+		assertNoError(t, err)
+		if len(results) != 1 || len(results[0].Finishers) != 2 || results[0].Finishers[1].Name != "Chris" {
+			t.Errorf("got results %+v want Andre winning with Chris eliminated", results)
+		}
+	})
+
+	t.Run("nothing to restore", func(t *testing.T) {
+		for name, store := range map[string]poker.PlayerStore{"no game": poker.NewInMemoryPlayerStore(), "not kept": &StubPlayerStore{}} {
+			game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+
+			restored, err := game.Restore(ctx, io.Discard)
+			if restored || err != nil {
+				t.Errorf("%s: got restored %v and error %v, want nothing restored", name, restored, err)
+			}
+			if _, err := game.Status(); err != poker.ErrGameNotStarted {
+				t.Errorf("%s: got error %v, want %v", name, err, poker.ErrGameNotStarted)
+			}
+		}
+	})
+
+	t.Run("a saved game that makes no sense is an error", func(t *testing.T) {
+		store := poker.NewInMemoryPlayerStore()
+		assertNoError(t, store.SaveActiveGame(ctx, &poker.ActiveGame{Result: poker.GameResult{ID: "broken", NumPlayers: 5}}))
+		game := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+
+		if _, err := game.Restore(ctx, io.Discard); !errors.Is(err, poker.ErrInvalidActiveGame) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidActiveGame)
+		}
+	})
+
+	t.Run("a game that was already recorded is cleared", func(t *testing.T) {
+		store := poker.NewInMemoryPlayerStore()
+		before := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+		assertNoError(t, before.Start(ctx, gameSettings(5, "", ""), io.Discard))
+		saved := activeGame(t, store)
+
+		// the result was recorded but the server stopped before the game was cleared
+		assertNoError(t, before.Finish(ctx, "Andre"))
+		assertNoError(t, store.SaveActiveGame(ctx, saved))
+
+		after := poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets())
+		restored, err := after.Restore(ctx, io.Discard)
+		if restored || err != nil {
+			t.Errorf("got restored %v and error %v, want nothing restored", restored, err)
+		}
+		if saved := activeGame(t, store); saved != nil {
+			t.Errorf("got saved game %+v want it cleared", saved)
+		}
+	})
+}
+
+// a game of the first numPlayers of Andre, Bob, Chris, Dave and so on
+func gameSettings(numPlayers int, blindStructure, league string) poker.GameSettings {
+	names := []string{"Andre", "Bob", "Chris", "Dave", "Eve", "Fred", "Gina", "Hugo"}
+	return poker.GameSettings{Players: poker.Roster(names[:numPlayers]...), BlindStructure: blindStructure, League: league}
+}
+
+func assertGameWonBy(t testing.TB, store *StubPlayerStore, winner string) {
+	t.Helper()
+
+	if len(store.Results) != 1 {
+		t.Fatalf("got %d results recorded, want 1", len(store.Results))
+	}
+	if got := store.Results[0].Winner(); got != winner {
+		t.Errorf("did not get correct winner. got %q want %q", got, winner)
+	}
+}
+
+func assertInvalidElimination(t testing.TB, err error, want string) {
+	t.Helper()
+	if !errors.Is(err, poker.ErrInvalidElimination) {
+		t.Fatalf("got error %v, want %v", err, poker.ErrInvalidElimination)
+	}
+	assertErrorContains(t, err, want)
 }
 
 func assertSchedulingTests(t testing.TB, tests []ScheduledAlert, blindAlerter *SpyBlindAlerter) {
@@ -0,0 +1,336 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+
+	"github.com/andremfp/poker-app/cards"
+)
+
+var ErrInvalidTableSize = errors.New("invalid table size")
+
+// Tournament plays a game across as many tables as its players need. Players are drawn a seat at random, tables are
+// balanced and broken as players are knocked out and every seat taken is announced where the blind alerts go. All the
+// tables play to the blind clock of the game.
+type Tournament struct {
+	Game
+	tableSize int
+	rng       *rand.Rand
+
+	lock sync.Mutex
+	out  io.Writer
+	// the seats of every table numbered from 1, "" for an empty seat, broken tables have none
+	tables [][]string
+}
+
+// Table is a table still in play and the players in each of its seats, "" for an empty seat
+type Table struct {
+	Number int
+	Seats  []string
+}
+
+// NewTournament seats at most tableSize players at a table. Without a rng the seats are drawn with one seeded from
+// crypto/rand.
+func NewTournament(game Game, tableSize int, rng *rand.Rand) (*Tournament, error) {
+	if tableSize < 2 {
+		return nil, fmt.Errorf("%w, a table needs at least 2 seats, got %d", ErrInvalidTableSize, tableSize)
+	}
+	if rng == nil {
+		var err error
+		if rng, err = cards.NewCryptoRand(); err != nil {
+			return nil, err
+		}
+	}
+	return &Tournament{Game: game, tableSize: tableSize, rng: rng}, nil
+}
+
+// Start starts the game and draws the seats of its players, spread as evenly as they go over the fewest tables
+func (t *Tournament) Start(ctx context.Context, settings GameSettings, alertsDestination io.Writer) error {
+	if err := t.Game.Start(ctx, settings, alertsDestination); err != nil {
+		return err
+	}
+
+	players := make([]string, len(settings.Players))
+	for i, player := range settings.Players {
+		players[i] = player.Name
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.out = alertsDestination
+	t.drawSeats(players)
+	return nil
+}
+
+// Restore carries on with the game that was running before a restart. The seats aren't saved with it so the players
+// still in draw new ones.
+func (t *Tournament) Restore(ctx context.Context, alertsDestination io.Writer) (restored bool, err error) {
+	restored, err = t.Game.Restore(ctx, alertsDestination)
+	if err != nil || !restored {
+		return restored, err
+	}
+	status, err := t.Game.Status()
+	if err != nil {
+		return true, fmt.Errorf("could not seat the players of the restored game, %w", err)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.out = alertsDestination
+	t.drawSeats(status.Playing)
+	return true, nil
+}
+
+// Eliminate knocks the player out of the game and frees their seat, then breaks the tables no longer needed and
+// evens out the others. A player the game knocked out loses their seat even when the game then failed to record the
+// result.
+func (t *Tournament) Eliminate(ctx context.Context, playerName string) (finisher Finisher, winner string, err error) {
+	finisher, winner, err = t.Game.Eliminate(ctx, playerName)
+	if err != nil && finisher.Name == "" {
+		return finisher, winner, err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if winner != "" {
+		t.tables = nil
+		return finisher, winner, err
+	}
+	t.unseat(playerName)
+	for _, move := range t.balance() {
+		announceSeat(t.out, move)
+	}
+	return finisher, winner, err
+}
+
+// Finish records the result of the game and clears the tables
+func (t *Tournament) Finish(ctx context.Context, winner string) error {
+	if err := t.Game.Finish(ctx, winner); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.tables = nil
+	return nil
+}
+
+// Rebuy buys the player back in and seats a player that was knocked out
+func (t *Tournament) Rebuy(ctx context.Context, playerName string) error {
+	if err := t.Game.Rebuy(ctx, playerName); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.isSeated(playerName) {
+		for _, seat := range t.join(playerName) {
+			announceSeat(t.out, seat)
+		}
+	}
+	return nil
+}
+
+// Register adds the player to the game and seats them
+func (t *Tournament) Register(ctx context.Context, player Entrant) error {
+	if err := t.Game.Register(ctx, player); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, seat := range t.join(player.Name) {
+		announceSeat(t.out, seat)
+	}
+	return nil
+}
+
+// Tables still in play in the order of their numbers
+func (t *Tournament) Tables() []Table {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var tables []Table
+	for i, seats := range t.tables {
+		if seats != nil {
+			tables = append(tables, Table{Number: i + 1, Seats: append([]string(nil), seats...)})
+		}
+	}
+	return tables
+}
+
+// drawSeats deals the players out at random over the fewest tables they fit at and announces where they sit.
+// Callers hold the lock.
+func (t *Tournament) drawSeats(players []string) {
+	t.tables = nil
+	if len(players) == 0 {
+		return
+	}
+
+	numTables := (len(players) + t.tableSize - 1) / t.tableSize
+	shuffled := append([]string(nil), players...)
+	t.rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	t.tables = make([][]string, numTables)
+	for i := range t.tables {
+		t.tables[i] = make([]string, t.tableSize)
+	}
+	// dealt round the tables so no table has more than one player more than another
+	for i := 0; i < len(shuffled); i += numTables {
+		for table := 0; table < numTables && i+table < len(shuffled); table++ {
+			t.seatAt(table, shuffled[i+table])
+		}
+	}
+
+	for i, seats := range t.tables {
+		for j, player := range seats {
+			if player != "" {
+				announceSeat(t.out, SeatPayload{Player: player, Table: i + 1, Seat: j + 1})
+			}
+		}
+	}
+}
+
+// balance breaks the tables that aren't needed for the players left, the one with the fewest players first, and
+// moves players from the fullest table to the emptiest until no table has more than one player more than another.
+// Callers hold the lock.
+func (t *Tournament) balance() []SeatPayload {
+	var moves []SeatPayload
+
+	needed := (t.numPlaying() + t.tableSize - 1) / t.tableSize
+	for t.numTables() > max(needed, 1) {
+		broken := t.emptiestTable()
+		players := t.tables[broken]
+		t.tables[broken] = nil
+		for _, player := range players {
+			if player != "" {
+				moves = append(moves, t.seatAt(t.emptiestTable(), player))
+			}
+		}
+	}
+
+	for {
+		fullest, emptiest := t.fullestTable(), t.emptiestTable()
+		if fullest < 0 || t.seated(fullest)-t.seated(emptiest) <= 1 {
+			return moves
+		}
+		var taken []int
+		for seat, player := range t.tables[fullest] {
+			if player != "" {
+				taken = append(taken, seat)
+			}
+		}
+		seat := taken[t.rng.Intn(len(taken))]
+		player := t.tables[fullest][seat]
+		t.tables[fullest][seat] = ""
+		moves = append(moves, t.seatAt(emptiest, player))
+	}
+}
+
+// seatAt sits the player in a free seat of the table drawn at random. Callers hold the lock.
+func (t *Tournament) seatAt(table int, player string) SeatPayload {
+	var free []int
+	for seat, taken := range t.tables[table] {
+		if taken == "" {
+			free = append(free, seat)
+		}
+	}
+	seat := free[t.rng.Intn(len(free))]
+	t.tables[table][seat] = player
+	return SeatPayload{Player: player, Table: table + 1, Seat: seat + 1, Moved: true}
+}
+
+// join sits a player coming into the game at the emptiest table, a new one when they are all full, and evens out the
+// tables. Callers hold the lock.
+func (t *Tournament) join(player string) []SeatPayload {
+	table := t.emptiestTable()
+	if table < 0 || t.seated(table) == t.tableSize {
+		t.tables = append(t.tables, make([]string, t.tableSize))
+		table = len(t.tables) - 1
+	}
+	seat := t.seatAt(table, player)
+	seat.Moved = false
+	return append([]SeatPayload{seat}, t.balance()...)
+}
+
+// Callers hold the lock.
+func (t *Tournament) isSeated(player string) bool {
+	for _, seats := range t.tables {
+		for _, seated := range seats {
+			if seated == player {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Callers hold the lock.
+func (t *Tournament) unseat(player string) {
+	for _, seats := range t.tables {
+		for seat, seated := range seats {
+			if seated == player {
+				seats[seat] = ""
+			}
+		}
+	}
+}
+
+// index of the open table with the fewest players, the last one on ties so the highest numbered tables break first,
+// -1 with no tables. Callers hold the lock.
+func (t *Tournament) emptiestTable() int {
+	emptiest := -1
+	for i, seats := range t.tables {
+		if seats != nil && (emptiest < 0 || t.seated(i) <= t.seated(emptiest)) {
+			emptiest = i
+		}
+	}
+	return emptiest
+}
+
+// index of the open table with the most players, the first one on ties, -1 with no tables. Callers hold the lock.
+func (t *Tournament) fullestTable() int {
+	fullest := -1
+	for i, seats := range t.tables {
+		if seats != nil && (fullest < 0 || t.seated(i) > t.seated(fullest)) {
+			fullest = i
+		}
+	}
+	return fullest
+}
+
+// Callers hold the lock.
+func (t *Tournament) seated(table int) int {
+	seated := 0
+	for _, player := range t.tables[table] {
+		if player != "" {
+			seated++
+		}
+	}
+	return seated
+}
+
+// Callers hold the lock.
+func (t *Tournament) numTables() int {
+	open := 0
+	for _, seats := range t.tables {
+		if seats != nil {
+			open++
+		}
+	}
+	return open
+}
+
+// Callers hold the lock.
+func (t *Tournament) numPlaying() int {
+	playing := 0
+	for i := range t.tables {
+		playing += t.seated(i)
+	}
+	return playing
+}
@@ -0,0 +1,363 @@
+package poker_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/andremfp/poker-app"
+)
+
+func TestTournament(t *testing.T) {
+	ctx := context.Background()
+
+	players := func(n int) []string {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = fmt.Sprintf("Player%d", i+1)
+		}
+		return names
+	}
+	start := func(t *testing.T, game poker.Game, tableSize, numPlayers int) (*poker.Tournament, *bytes.Buffer) {
+		t.Helper()
+		tournament, err := poker.NewTournament(game, tableSize, rand.New(rand.NewSource(1)))
+		assertNoError(t, err)
+		out := &bytes.Buffer{}
+		assertNoError(t, tournament.Start(ctx, poker.GameSettings{Players: poker.Roster(players(numPlayers)...)}, out))
+		return tournament, out
+	}
+
+	t.Run("players are seated at random over the fewest tables they fit at", func(t *testing.T) {
+		game := &SpyGame{}
+		tournament, out := start(t, game, 9, 20)
+
+		assertTableSizes(t, tournament, 7, 7, 6)
+		assertSeatedOnce(t, tournament, players(20)...)
+		if got := strings.Count(out.String(), " sit at table "); got != 20 {
+			t.Errorf("got %d players told where to sit want 20, %q", got, out.String())
+		}
+		if game.StartedWith != 20 {
+			t.Errorf("got game started with %d players want 20", game.StartedWith)
+		}
+
+		other, _ := start(t, &SpyGame{}, 9, 20)
+		reseeded, err := poker.NewTournament(&SpyGame{}, 9, rand.New(rand.NewSource(2)))
+		assertNoError(t, err)
+		assertNoError(t, reseeded.Start(ctx, poker.GameSettings{Players: poker.Roster(players(20)...)}, &bytes.Buffer{}))
+		if fmt.Sprint(other.Tables()) == fmt.Sprint(reseeded.Tables()) {
+			t.Error("got the same seats with a different draw")
+		}
+	})
+
+	t.Run("players move when knock outs leave the tables uneven", func(t *testing.T) {
+		tournament, out := start(t, &SpyGame{}, 8, 20)
+		out.Reset()
+
+		// tables of 7, 7 and 6 players, two out at the table of 6 leave it 3 short of the others
+		short := tournament.Tables()[2].Seats
+		for _, player := range seatedAt(short)[:2] {
+			_, _, err := tournament.Eliminate(ctx, player)
+			assertNoError(t, err)
+		}
+
+		assertTableSizes(t, tournament, 6, 6, 6)
+		for _, move := range assertMoves(t, out, 2) {
+			if move[2] != "3" {
+				t.Errorf("got %q want a move to table 3", move[0])
+			}
+			if tournament.Tables()[2].Seats[atoi(t, move[3])-1] != move[1] {
+				t.Errorf("got %q but the player isn't in that seat, %v", move[0], tournament.Tables())
+			}
+		}
+	})
+
+	t.Run("tables no longer needed are broken and their players spread over the others", func(t *testing.T) {
+		tournament, out := start(t, &SpyGame{}, 9, 19)
+		out.Reset()
+
+		// tables of 7, 6 and 6, at 18 players left two tables of 9 will do
+		broken := tournament.Tables()[2].Seats
+		_, _, err := tournament.Eliminate(ctx, seatedAt(tournament.Tables()[0].Seats)[0])
+		assertNoError(t, err)
+
+		tables := tournament.Tables()
+		if len(tables) != 2 || tables[0].Number != 1 || tables[1].Number != 2 {
+			t.Fatalf("got tables %v want table 3 broken", tables)
+		}
+		assertTableSizes(t, tournament, 9, 9)
+		assertMoves(t, out, 6)
+		assertSeatedOnce(t, tournament, seatedAt(broken)...)
+	})
+
+	t.Run("eliminations that leave a single table seat everyone at it", func(t *testing.T) {
+		tournament, out := start(t, &SpyGame{}, 6, 8)
+		out.Reset()
+
+		for _, player := range []string{"Player1", "Player2"} {
+			_, _, err := tournament.Eliminate(ctx, player)
+			assertNoError(t, err)
+		}
+
+		assertTableSizes(t, tournament, 6)
+		assertSeatedOnce(t, tournament, players(8)[2:]...)
+	})
+
+	t.Run("the tables are cleared once there is a winner", func(t *testing.T) {
+		game := &SpyGame{}
+		tournament, _ := start(t, game, 2, 3)
+
+		_, _, err := tournament.Eliminate(ctx, "Player1")
+		assertNoError(t, err)
+		_, winner, err := tournament.Eliminate(ctx, "Player2")
+		assertNoError(t, err)
+
+		if winner != "Player3" {
+			t.Errorf("got winner %q want Player3", winner)
+		}
+		if tables := tournament.Tables(); len(tables) != 0 {
+			t.Errorf("got tables %v after the game was won", tables)
+		}
+	})
+
+	t.Run("finishing the game clears the tables", func(t *testing.T) {
+		game := &SpyGame{}
+		tournament, _ := start(t, game, 9, 12)
+
+		assertNoError(t, tournament.Finish(ctx, "Player1"))
+
+		assertFinishCalledWith(t, game, "Player1")
+		if tables := tournament.Tables(); len(tables) != 0 {
+			t.Errorf("got tables %v after the game finished", tables)
+		}
+	})
+
+	t.Run("eliminations the game refuses leave the seats as they are", func(t *testing.T) {
+		game := &SpyGame{}
+		tournament, out := start(t, game, 9, 12)
+		before := fmt.Sprint(tournament.Tables())
+		out.Reset()
+
+		game.EliminateError = poker.ErrInvalidElimination
+		_, _, err := tournament.Eliminate(ctx, "Player1")
+
+		if !errors.Is(err, poker.ErrInvalidElimination) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidElimination)
+		}
+		if after := fmt.Sprint(tournament.Tables()); after != before || out.Len() != 0 {
+			t.Errorf("got tables %v and %q announced, want them unchanged", after, out.String())
+		}
+	})
+
+	t.Run("players knocked out lose their seat when the result can't be recorded", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		tournament, _ := start(t, poker.NewTexasHoldem(store, &SpyBlindAlerter{}, poker.BlindPresets()), 2, 3)
+
+		_, _, err := tournament.Eliminate(ctx, "Player1")
+		assertNoError(t, err)
+
+		store.Err = errors.New("disk full")
+		finisher, winner, err := tournament.Eliminate(ctx, "Player2")
+		if !errors.Is(err, store.Err) || winner != "" || finisher.Name != "Player2" {
+			t.Fatalf("got %+v out, winner %q and error %v, want Player2 out and %v", finisher, winner, err, store.Err)
+		}
+		assertTableSizes(t, tournament, 1)
+		assertSeatedOnce(t, tournament, "Player3")
+
+		store.Err = nil
+		assertNoError(t, tournament.Finish(ctx, "Player3"))
+		assertGameWonBy(t, store, "Player3")
+		if tables := tournament.Tables(); len(tables) != 0 {
+			t.Errorf("got tables %v after the game finished", tables)
+		}
+	})
+
+	t.Run("seats are announced as messages to announcers", func(t *testing.T) {
+		tournament, err := poker.NewTournament(&SpyGame{}, 2, rand.New(rand.NewSource(1)))
+		assertNoError(t, err)
+		out := &SpyAnnouncer{}
+		assertNoError(t, tournament.Start(ctx, poker.GameSettings{Players: poker.Roster("Andre", "Chris", "John")}, out))
+
+		assertAnnounced(t, out, poker.SeatMessage, poker.SeatMessage, poker.SeatMessage)
+		var seat poker.SeatPayload
+		assertNoError(t, out.Messages[0].DecodePayload(&seat))
+		if seat.Table != 1 || seat.Moved {
+			t.Errorf("got seat %+v want a seat at table 1", seat)
+		}
+	})
+
+	t.Run("the players still in a restored game draw new seats", func(t *testing.T) {
+		game := &SpyGame{SavedGame: true, GameStatus: poker.GameStatus{Playing: players(10)}}
+		tournament, err := poker.NewTournament(game, 9, rand.New(rand.NewSource(1)))
+		assertNoError(t, err)
+
+		restored, err := tournament.Restore(ctx, &bytes.Buffer{})
+		assertNoError(t, err)
+		if !restored {
+			t.Fatal("the game being played wasn't restored")
+		}
+		assertTableSizes(t, tournament, 5, 5)
+		assertSeatedOnce(t, tournament, players(10)...)
+	})
+
+	t.Run("games that can't start seat no one", func(t *testing.T) {
+		tournament, err := poker.NewTournament(&SpyGame{StartError: poker.ErrInvalidRoster}, 9, nil)
+		assertNoError(t, err)
+		out := &bytes.Buffer{}
+
+		err = tournament.Start(ctx, poker.GameSettings{Players: poker.Roster("Andre", "Chris")}, out)
+		if !errors.Is(err, poker.ErrInvalidRoster) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidRoster)
+		}
+		if tables := tournament.Tables(); len(tables) != 0 || out.Len() != 0 {
+			t.Errorf("got tables %v and %q announced, want none", tables, out.String())
+		}
+	})
+
+	t.Run("tables stay balanced all the way to the winner of the game", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		blindAlerter := &SpyBlindAlerter{}
+		tournament, _ := start(t, poker.NewTexasHoldem(store, blindAlerter, poker.BlindPresets()), 9, 25)
+		scheduled := len(blindAlerter.Alerts)
+
+		var winner string
+		for i := 1; winner == ""; i++ {
+			var err error
+			_, winner, err = tournament.Eliminate(ctx, fmt.Sprintf("Player%d", i))
+			assertNoError(t, err)
+
+			remaining := 25 - i
+			tables := tournament.Tables()
+			if winner != "" {
+				break
+			}
+			if want := (remaining + 8) / 9; len(tables) != want {
+				t.Fatalf("got %d tables for %d players want %d", len(tables), remaining, want)
+			}
+			fewest, most := 9, 0
+			for _, table := range tables {
+				fewest, most = min(fewest, len(seatedAt(table.Seats))), max(most, len(seatedAt(table.Seats)))
+			}
+			if most-fewest > 1 {
+				t.Fatalf("got tables %v with %d players left", tables, remaining)
+			}
+		}
+
+		assertGameWonBy(t, store, "Player25")
+		if len(blindAlerter.Alerts) != scheduled {
+			t.Errorf("got %d alerts scheduled want the %d of the one blind clock", len(blindAlerter.Alerts), scheduled)
+		}
+	})
+
+	t.Run("players joining late sit at a new table when the others are full", func(t *testing.T) {
+		tournament, out := start(t, &SpyGame{}, 2, 4)
+		out.Reset()
+
+		assertNoError(t, tournament.Register(ctx, poker.Entrant{Name: "Player5"}))
+
+		assertTableSizes(t, tournament, 2, 2, 1)
+		if !strings.HasPrefix(out.String(), "Player5 sit at table 3 seat ") || strings.Count(out.String(), "\n") != 1 {
+			t.Errorf("got %q announced want Player5 seated at table 3", out.String())
+		}
+	})
+
+	t.Run("players knocked out get a seat back when they rebuy", func(t *testing.T) {
+		game := &SpyGame{}
+		tournament, out := start(t, game, 9, 12)
+		player := seatedAt(tournament.Tables()[0].Seats)[0]
+		_, _, err := tournament.Eliminate(ctx, player)
+		assertNoError(t, err)
+		out.Reset()
+
+		assertNoError(t, tournament.Rebuy(ctx, player))
+		assertNoError(t, tournament.Rebuy(ctx, "Player12"))
+
+		assertTableSizes(t, tournament, 6, 6)
+		assertSeatedOnce(t, tournament, players(12)...)
+		if !strings.HasPrefix(out.String(), player+" sit at table ") || strings.Count(out.String(), "\n") != 1 {
+			t.Errorf("got %q announced want %s seated again", out.String(), player)
+		}
+	})
+
+	t.Run("entries the game refuses seat no one", func(t *testing.T) {
+		tournament, out := start(t, &SpyGame{EntryError: poker.ErrEntriesClosed}, 9, 12)
+		out.Reset()
+
+		if err := tournament.Register(ctx, poker.Entrant{Name: "Mary"}); !errors.Is(err, poker.ErrEntriesClosed) {
+			t.Errorf("got error %v, want %v", err, poker.ErrEntriesClosed)
+		}
+		assertTableSizes(t, tournament, 6, 6)
+		if out.Len() != 0 {
+			t.Errorf("got %q announced want nothing", out.String())
+		}
+	})
+
+	t.Run("tables need at least 2 seats", func(t *testing.T) {
+		if _, err := poker.NewTournament(&SpyGame{}, 1, nil); !errors.Is(err, poker.ErrInvalidTableSize) {
+			t.Errorf("got error %v, want %v", err, poker.ErrInvalidTableSize)
+		}
+	})
+}
+
+var moveAnnouncement = regexp.MustCompile(`(?m)^(\w+) move to table (\d+) seat (\d+)$`)
+
+// assertMoves checks want moves were announced and returns the player, table and seat of each
+func assertMoves(t testing.TB, out *bytes.Buffer, want int) [][]string {
+	t.Helper()
+	moves := moveAnnouncement.FindAllStringSubmatch(out.String(), -1)
+	if len(moves) != want || strings.Count(out.String(), "\n") != want {
+		t.Fatalf("got %q announced want %d moves", out.String(), want)
+	}
+	return moves
+}
+
+func assertTableSizes(t testing.TB, tournament *poker.Tournament, want ...int) {
+	t.Helper()
+	tables := tournament.Tables()
+	var got []int
+	for _, table := range tables {
+		got = append(got, len(seatedAt(table.Seats)))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got tables of %v players want %v, %v", got, want, tables)
+	}
+}
+
+func assertSeatedOnce(t testing.TB, tournament *poker.Tournament, players ...string) {
+	t.Helper()
+	seated := map[string]int{}
+	for _, table := range tournament.Tables() {
+		for _, player := range seatedAt(table.Seats) {
+			seated[player]++
+		}
+	}
+	for _, player := range players {
+		if seated[player] != 1 {
+			t.Errorf("got %s seated %d times want once", player, seated[player])
+		}
+	}
+}
+
+// the players in the seats, without the empty ones
+func seatedAt(seats []string) []string {
+	var players []string
+	for _, player := range seats {
+		if player != "" {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+func atoi(t testing.TB, s string) int {
+	t.Helper()
+	var n int
+	if _, err := fmt.Sscan(s, &n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
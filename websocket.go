@@ -1,45 +1,126 @@
 package poker
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// WebSocketTimeouts notice the connections that went quiet, like a phone that lost signal, so they don't hang around
+type WebSocketTimeouts struct {
+	// how often the server pings, the pongs keep a connection alive when nothing else is sent
+	Ping time.Duration
+	// how long a connection can go without a message or a pong before it is closed, longer than Ping
+	Idle time.Duration
+	// how long sending a message can take
+	Write time.Duration
+}
+
+func DefaultWebSocketTimeouts() WebSocketTimeouts {
+	return WebSocketTimeouts{Ping: 30 * time.Second, Idle: time.Minute, Write: 10 * time.Second}
+}
+
 type playerServerWS struct {
 	*websocket.Conn
+	timeouts WebSocketTimeouts
+	// the blind levels, the ticks and the replies to the host are sent from different goroutines
+	writeLock sync.Mutex
+
+	hangupOnce sync.Once
+	hungUp     chan struct{}
 }
 
-func NewPlayerServerWS(w http.ResponseWriter, r *http.Request) *playerServerWS {
-	// upgrades http connection to ws
-	// then read and record user input
+// NewPlayerServerWS upgrades the request to a websocket and keeps it alive with pings. When the upgrade fails the
+// request was already answered with the error.
+func NewPlayerServerWS(w http.ResponseWriter, r *http.Request, timeouts WebSocketTimeouts) (*playerServerWS, error) {
 	conn, err := wsUpgrader.Upgrade(w, r, nil)
-
 	if err != nil {
-		log.Printf("problem upgrading connection to WebSocket, %v\n", err)
+		return nil, fmt.Errorf("problem upgrading connection to WebSocket, %v", err)
 	}
 
-	return &playerServerWS{conn}
-}
+	ws := &playerServerWS{Conn: conn, timeouts: timeouts, hungUp: make(chan struct{})}
+	conn.SetReadDeadline(time.Now().Add(timeouts.Idle))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(timeouts.Idle))
+	})
+	go ws.keepAlive()
 
-func (w *playerServerWS) WaitForMsg() string {
-	// ReadMessage blocks on waiting for input
-	_, msg, err := w.ReadMessage()
+	return ws, nil
+}
 
+// WaitForMessage blocks until the next message. Messages that aren't json envelopes are ErrInvalidMessage, any other
+// error means the connection is gone.
+func (w *playerServerWS) WaitForMessage() (Message, error) {
+	_, data, err := w.ReadMessage()
 	if err != nil {
-		log.Printf("error reading from websocket, %v\n", err)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			w.Hangup(websocket.CloseNormalClosure, "idle timeout")
+		}
+		if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			log.Printf("error reading from websocket, %v\n", err)
+		}
+		return Message{}, err
 	}
+	w.SetReadDeadline(time.Now().Add(w.timeouts.Idle))
 
-	return string(msg)
+	var message Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return Message{}, fmt.Errorf("%w, %v", ErrInvalidMessage, err)
+	}
+	if message.Type == "" {
+		return Message{}, fmt.Errorf("%w, the message has no type", ErrInvalidMessage)
+	}
+	return message, nil
 }
 
-func (w *playerServerWS) Write(p []byte) (n int, err error) {
-	err = w.WriteMessage(websocket.TextMessage, p)
+func (w *playerServerWS) Send(message Message) error {
+	w.writeLock.Lock()
+	defer w.writeLock.Unlock()
 
-	if err != nil {
-		return 0, err
+	w.SetWriteDeadline(time.Now().Add(w.timeouts.Write))
+	return w.WriteJSON(message)
+}
+
+// SendError answers the message numbered replyTo with what went wrong
+func (w *playerServerWS) SendError(err error, replyTo int64) error {
+	payload := ErrorPayload{Error: err.Error(), ReplyTo: replyTo}
+	if errors.Is(err, ErrGameNotFound) {
+		payload.Code = GameNotFoundCode
 	}
+	return w.Send(NewMessage(ErrorMessage, payload))
+}
 
-	return len(p), nil
+// Hangup sends a close frame with the reason and closes the connection, only the first call does anything
+func (w *playerServerWS) Hangup(code int, reason string) {
+	w.hangupOnce.Do(func() {
+		close(w.hungUp)
+		// control frames can be written alongside Send
+		w.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(w.timeouts.Write))
+		w.Close()
+	})
+}
+
+func (w *playerServerWS) keepAlive() {
+	ticker := time.NewTicker(w.timeouts.Ping)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.hungUp:
+			return
+		case <-ticker.C:
+			if err := w.WriteControl(websocket.PingMessage, nil, time.Now().Add(w.timeouts.Write)); err != nil {
+				w.Hangup(websocket.CloseGoingAway, "ping failed")
+				return
+			}
+		}
+	}
 }